@@ -87,6 +87,9 @@ func main() {
 	rootCmd.PersistentFlags().BoolP("version", "", false, "Print the version")
 	rootCmd.PersistentFlags().BoolP("tinyint-as-bool", "", false, "Map MySQL tinyint(1) in Go to bool instead of int8")
 	rootCmd.PersistentFlags().BoolP("wipe", "", false, "Delete the output folder (rm -rf) before generation to ensure sanity")
+	rootCmd.PersistentFlags().BoolP("no-field-comments", "", false, "Disable column comments as the doc comments of model fields")
+	rootCmd.PersistentFlags().BoolP("table-names-are-singular", "", false, "Use table names as struct names without singularizing, e.g. user to User")
+	rootCmd.PersistentFlags().BoolP("single-file", "", false, "Generate every table into a single models_gen.go")
 
 	// hide flags not recommended for use
 	rootCmd.PersistentFlags().MarkHidden("replace")
@@ -141,7 +144,12 @@ func preRun(cmd *cobra.Command, args []string) error {
 		NoHooks:          viper.GetBool("no-hooks"),
 		NoAutoTimestamps: viper.GetBool("no-auto-timestamps"),
 		Wipe:             viper.GetBool("wipe"),
+
+		NoFieldComments:       viper.GetBool("no-field-comments"),
+		TableNamesAreSingular: viper.GetBool("table-names-are-singular"),
+		SingleFileMode:        viper.GetBool("single-file"),
 	}
+	cmdConfig.Logger = stderrLogger{verbose: cmdConfig.Debug}
 
 	// BUG: https://github.com/spf13/viper/issues/200
 	// Look up the value of blacklist, whitelist & tags directly from PFlags in Cobra if we
@@ -179,7 +187,7 @@ func preRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if driverName == "postgres" {
+	if driverName == "postgres" || driverName == "cockroach" || driverName == "redshift" {
 		cmdConfig.Postgres = boilingcore.PostgresConfig{
 			User:    viper.GetString("postgres.user"),
 			Pass:    viper.GetString("postgres.pass"),
@@ -187,6 +195,10 @@ func preRun(cmd *cobra.Command, args []string) error {
 			Port:    viper.GetInt("postgres.port"),
 			DBName:  viper.GetString("postgres.dbname"),
 			SSLMode: viper.GetString("postgres.sslmode"),
+
+			SSLCert:     viper.GetString("postgres.sslcert"),
+			SSLKey:      viper.GetString("postgres.sslkey"),
+			SSLRootCert: viper.GetString("postgres.sslrootcert"),
 		}
 
 		// BUG: https://github.com/spf13/viper/issues/71
@@ -221,12 +233,17 @@ func preRun(cmd *cobra.Command, args []string) error {
 
 	if driverName == "mysql" {
 		cmdConfig.MySQL = boilingcore.MySQLConfig{
-			User:    viper.GetString("mysql.user"),
-			Pass:    viper.GetString("mysql.pass"),
-			Host:    viper.GetString("mysql.host"),
-			Port:    viper.GetInt("mysql.port"),
-			DBName:  viper.GetString("mysql.dbname"),
-			SSLMode: viper.GetString("mysql.sslmode"),
+			User:             viper.GetString("mysql.user"),
+			Pass:             viper.GetString("mysql.pass"),
+			Host:             viper.GetString("mysql.host"),
+			Port:             viper.GetInt("mysql.port"),
+			DBName:           viper.GetString("mysql.dbname"),
+			SSLMode:          viper.GetString("mysql.sslmode"),
+			Charset:          viper.GetString("mysql.charset"),
+			Collation:        viper.GetString("mysql.collation"),
+			BoolColumns:      viper.GetStringSlice("mysql.bool-columns"),
+			ZeroDateHandling: viper.GetString("mysql.zero-date-handling"),
+			IgnoreUnsigned:   viper.GetBool("mysql.ignore-unsigned"),
 		}
 
 		// Set MySQL TinyintAsBool global var. This flag only applies to MySQL.
@@ -261,6 +278,45 @@ func preRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if driverName == "oracle" {
+		cmdConfig.Oracle = boilingcore.OracleConfig{
+			User:        viper.GetString("oracle.user"),
+			Pass:        viper.GetString("oracle.pass"),
+			Host:        viper.GetString("oracle.host"),
+			Port:        viper.GetInt("oracle.port"),
+			ServiceName: viper.GetString("oracle.service-name"),
+		}
+
+		// Oracle schemas are users
+		if len(cmdConfig.Schema) == 0 {
+			cmdConfig.Schema = strings.ToUpper(cmdConfig.Oracle.User)
+		}
+
+		err = vala.BeginValidation().Validate(
+			vala.StringNotEmpty(cmdConfig.Oracle.User, "oracle.user"),
+			vala.StringNotEmpty(cmdConfig.Oracle.Host, "oracle.host"),
+			vala.StringNotEmpty(cmdConfig.Oracle.ServiceName, "oracle.service-name"),
+		).Check()
+
+		if err != nil {
+			return commandFailure(err.Error())
+		}
+	}
+
+	if driverName == "ddl" {
+		cmdConfig.DDL = boilingcore.DDLConfig{
+			Path: viper.GetString("ddl.path"),
+		}
+
+		err = vala.BeginValidation().Validate(
+			vala.StringNotEmpty(cmdConfig.DDL.Path, "ddl.path"),
+		).Check()
+
+		if err != nil {
+			return commandFailure(err.Error())
+		}
+	}
+
 	cmdState, err = boilingcore.New(cmdConfig)
 	return err
 }
@@ -272,3 +328,24 @@ func run(cmd *cobra.Command, args []string) error {
 func postRun(cmd *cobra.Command, args []string) error {
 	return cmdState.Cleanup()
 }
+
+// stderrLogger is the Logger of the command, writing to stderr. Progress is
+// only written in debug mode, which is also the only one Run dumps the
+// schema in.
+type stderrLogger struct {
+	verbose bool
+}
+
+func (l stderrLogger) Debugf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+func (l stderrLogger) Infof(format string, args ...interface{}) {
+	if l.verbose {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+func (l stderrLogger) Warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/pkg/errors"
+)
+
+// ColumnsSuffix is the suffix of the file, next to a table's model, that the
+// quoted column names are conventionally generated into.
+const ColumnsSuffix = "_columns_gen.go"
+
+// ColumnsRenderer is a TableRenderer that emits a struct holding the quoted
+// name of each column, for building dynamic SQL safely:
+//
+//	var UserColumns = struct {
+//		ID    string
+//		Email string
+//	}{...}
+type ColumnsRenderer struct{}
+
+// Render writes the quoted column names of data.Table to w.
+func (ColumnsRenderer) Render(data *TemplateData, w io.Writer) error {
+	buf := &bytes.Buffer{}
+	name := data.Table.GoName + "Columns"
+
+	fmt.Fprintf(buf, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage %s\n\n", data.PkgName)
+	fmt.Fprintf(buf, "// %s holds the quoted column names of the %s table.\nvar %s = struct {\n", name, data.Table.Name, name)
+	for _, c := range data.Table.Columns {
+		if comment := data.FieldComment(c); comment != "" {
+			fmt.Fprintf(buf, "// %s\n", comment)
+		}
+		fmt.Fprintf(buf, "%s string\n", db.GoName(c.Name))
+	}
+	buf.WriteString("}{\n")
+	for i, quoted := range data.QuotedColumns() {
+		fmt.Fprintf(buf, "%s: %q,\n", db.GoName(data.Table.Columns[i].Name), quoted)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrapf(err, "unable to format columns of %s", data.Table.Name)
+	}
+
+	_, err = w.Write(src)
+	return err
+}
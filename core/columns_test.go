@@ -0,0 +1,139 @@
+package core
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	mysql := &TemplateData{LQ: '`', RQ: '`'}
+	postgres := &TemplateData{LQ: '"', RQ: '"'}
+
+	tests := []struct {
+		Data *TemplateData
+		Name string
+		Want string
+	}{
+		{mysql, "email", "`email`"},
+		{mysql, "odd`name", "`odd``name`"},
+		{postgres, "email", `"email"`},
+		{postgres, "public.users", `"public"."users"`},
+	}
+
+	for i, test := range tests {
+		if got := test.Data.QuoteIdentifier(test.Name); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestColumnsRenderer(t *testing.T) {
+	t.Parallel()
+
+	data := &TemplateData{
+		Table: db.Table{
+			Name:    "users",
+			GoName:  "User",
+			Columns: []db.Column{{Name: "id"}, {Name: "email"}},
+		},
+		PkgName: "models",
+		LQ:      '`',
+		RQ:      '`',
+	}
+
+	if got, want := data.QuotedColumns(), []string{"`id`", "`email`"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (ColumnsRenderer{}).Render(data, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "users"+ColumnsSuffix, buf.Bytes(), 0); err != nil {
+		t.Fatalf("columns are not valid go: %s\n%s", err, buf)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"var UserColumns = struct {",
+		"ID    string",
+		"Email: \"`email`\",",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestColumnsRendererFieldComments(t *testing.T) {
+	t.Parallel()
+
+	data := &TemplateData{
+		Table: db.Table{
+			Name:   "users",
+			GoName: "User",
+			Columns: []db.Column{
+				{Name: "id"},
+				{Name: "email", Comment: "login address.\r\nMust be unique;\n\tlowercase */ \x00"},
+			},
+		},
+		PkgName: "models",
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (ColumnsRenderer{}).Render(data, buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "users"+ColumnsSuffix, buf.Bytes(), 0); err != nil {
+		t.Fatalf("columns are not valid go: %s\n%s", err, buf)
+	}
+	if want := "\t// login address. Must be unique; lowercase */\n\tEmail string\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("want %q in output:\n%s", want, buf)
+	}
+
+	data.NoFieldComments = true
+	buf.Reset()
+	if err := (ColumnsRenderer{}).Render(data, buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "login") {
+		t.Errorf("want no field comments:\n%s", buf)
+	}
+}
+
+func TestInsertBatchSize(t *testing.T) {
+	t.Parallel()
+
+	table := db.Table{
+		Name: "users",
+		Columns: []db.Column{
+			{Name: "id", AutoIncrement: true},
+			{Name: "email"},
+			{Name: "name"},
+		},
+	}
+
+	tests := []struct {
+		Data *TemplateData
+		Want int
+	}{
+		{&TemplateData{Table: table, MaxPlaceholders: 65535}, 32767},
+		{&TemplateData{Table: table}, 0},
+		{&TemplateData{Table: db.Table{Columns: []db.Column{{Name: "id", AutoIncrement: true}}}, MaxPlaceholders: 65535}, 0},
+	}
+
+	for i, test := range tests {
+		if got := test.Data.InsertBatchSize(); got != test.Want {
+			t.Errorf("%d) want: %d, got: %d", i, test.Want, got)
+		}
+	}
+}
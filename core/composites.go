@@ -0,0 +1,128 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/pkg/errors"
+)
+
+// CompositesFilename is the file, relative to the output folder, that the
+// composite type structs are conventionally generated into.
+const CompositesFilename = "composites_gen.go"
+
+// CompositesRenderer is a SingletonRenderer that declares the struct of
+// every composite type in the schema once (see db.CompositeTypes), with a
+// field of its Go type per attribute, and the sql.Scanner and driver.Valuer
+// methods that read and write it as a column:
+//
+//	type Address struct {
+//		Street null.String
+//		Zip    null.Int
+//	}
+//
+//	func (a *Address) Scan(src interface{}) error
+//	func (a Address) Value() (driver.Value, error)
+type CompositesRenderer struct{}
+
+// qualifiedImports are the import paths of the packages the Go types drivers
+// write qualified, without a PkgName, are in.
+var qualifiedImports = map[string]string{
+	"json":  "encoding/json",
+	"null":  "gopkg.in/nullbio/null.v6",
+	"time":  "time",
+	"types": "github.com/mickeyreiss/sqlgen/types",
+}
+
+// RenderSingleton writes the composite type structs of data.Tables to w.
+func (CompositesRenderer) RenderSingleton(data *TemplateData, w io.Writer) error {
+	composites := db.CompositeTypes(data.Tables)
+
+	var fields []db.Column
+	for _, c := range composites {
+		fields = append(fields, c.Fields...)
+	}
+	std, thirdParty := db.ColumnImports(fields)
+	if len(composites) != 0 {
+		std = appendImport(std, "database/sql/driver")
+		thirdParty = appendImport(thirdParty, qualifiedImports["types"])
+	}
+	for _, f := range fields {
+		typ := strings.TrimLeft(f.TypeName, "*[]")
+		if len(f.PkgName) != 0 || !strings.Contains(typ, ".") {
+			continue
+		}
+		path, ok := qualifiedImports[typ[:strings.IndexByte(typ, '.')]]
+		if !ok {
+			continue
+		}
+		if strings.Contains(path, ".") {
+			thirdParty = appendImport(thirdParty, path)
+		} else {
+			std = appendImport(std, path)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage %s\n", data.PkgName)
+	if len(std)+len(thirdParty) != 0 {
+		buf.WriteString("\nimport (\n")
+		for _, path := range std {
+			fmt.Fprintf(buf, "%q\n", path)
+		}
+		if len(std) != 0 && len(thirdParty) != 0 {
+			buf.WriteString("\n")
+		}
+		for _, path := range thirdParty {
+			fmt.Fprintf(buf, "%q\n", path)
+		}
+		buf.WriteString(")\n")
+	}
+
+	for _, c := range composites {
+		fmt.Fprintf(buf, "\n// %s is the %s composite type of the database.\ntype %s struct {\n", c.GoName, c.Name, c.GoName)
+		for _, f := range c.Fields {
+			fmt.Fprintf(buf, "%s %s\n", db.GoName(f.Name), f.GoTypeExpr())
+		}
+		buf.WriteString("}\n")
+
+		recv := strings.ToLower(c.GoName[:1])
+		var dests, values []string
+		for _, f := range c.Fields {
+			dests = append(dests, ", &"+recv+"."+db.GoName(f.Name))
+			values = append(values, recv+"."+db.GoName(f.Name))
+		}
+		fmt.Fprintf(buf, "\n// Scan implements sql.Scanner, from a %s composite value.\n", c.Name)
+		fmt.Fprintf(buf, "func (%s *%s) Scan(src interface{}) error {\nreturn types.ScanRecord(src%s)\n}\n",
+			recv, c.GoName, strings.Join(dests, ""))
+		fmt.Fprintf(buf, "\n// Value implements driver.Valuer, as a %s composite value.\n", c.Name)
+		fmt.Fprintf(buf, "func (%s %s) Value() (driver.Value, error) {\nreturn types.RecordValue(%s)\n}\n",
+			recv, c.GoName, strings.Join(values, ", "))
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "unable to format composite types")
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+// appendImport adds path to the sorted paths, unless it's there already.
+func appendImport(paths []string, path string) []string {
+	i := sort.SearchStrings(paths, path)
+	if i < len(paths) && paths[i] == path {
+		return paths
+	}
+
+	paths = append(paths, "")
+	copy(paths[i+1:], paths[i:])
+	paths[i] = path
+	return paths
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestCompositesRenderer(t *testing.T) {
+	t.Parallel()
+
+	address := &db.CompositeType{Name: "address", GoName: "Address", Fields: []db.Column{
+		{Name: "street", TypeName: "null.String", Nullable: true},
+		{Name: "moved_in", TypeName: "null.Time", Nullable: true},
+		{Name: "geo", TypeName: "Point", PkgName: "github.com/example/geo", Nullable: true},
+	}}
+	tables := []db.Table{
+		{Name: "users", Columns: []db.Column{{Name: "home", TypeName: "*Address", Composite: address, Nullable: true}}},
+		{Name: "offices", Columns: []db.Column{{Name: "address", TypeName: "Address", Composite: address}}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (CompositesRenderer{}).RenderSingleton(&TemplateData{Tables: tables, PkgName: "models"}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), CompositesFilename, buf.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("composites are not valid go: %s\n%s", err, buf)
+	}
+	if len(f.Imports) != 4 {
+		t.Errorf("want the driver, null, geo and types imports, got %d:\n%s", len(f.Imports), buf)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "type Address struct"); n != 1 {
+		t.Errorf("want Address declared once, got %d times:\n%s", n, out)
+	}
+	for _, want := range []string{
+		`"gopkg.in/nullbio/null.v6"`,
+		"Street  null.String",
+		"MovedIn null.Time",
+		"Geo     geo.Point",
+		"func (a *Address) Scan(src interface{}) error {\n\treturn types.ScanRecord(src, &a.Street, &a.MovedIn, &a.Geo)\n}",
+		"func (a Address) Value() (driver.Value, error) {\n\treturn types.RecordValue(a.Street, a.MovedIn, a.Geo)\n}",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCompositesRendererCompiles(t *testing.T) {
+	t.Parallel()
+
+	address := &db.CompositeType{Name: "address", GoName: "Address", Fields: []db.Column{
+		{Name: "street", TypeName: "NullString", PkgName: "database/sql", Nullable: true},
+		{Name: "lines", TypeName: "types.NullStringArray", Nullable: true},
+		{Name: "moved_in", TypeName: "NullDate", PkgName: "github.com/mickeyreiss/sqlgen/types", Nullable: true},
+	}}
+	tables := []db.Table{
+		{Name: "users", Columns: []db.Column{{Name: "home", TypeName: "*Address", Composite: address, Nullable: true}}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (CompositesRenderer{}).RenderSingleton(&TemplateData{Tables: tables, PkgName: "models"}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, CompositesFilename, buf.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("composites are not valid go: %s\n%s", err, buf)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("models", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("want the composites to compile: %s\n%s", err, buf)
+	}
+
+	typ := pkg.Scope().Lookup("Address").Type()
+	for path, iface := range map[string]string{"database/sql": "Scanner", "database/sql/driver": "Valuer"} {
+		imported, err := conf.Importer.Import(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := imported.Scope().Lookup(iface).Type().Underlying().(*types.Interface)
+		if !types.Implements(types.NewPointer(typ), want) {
+			t.Errorf("want *Address to implement %s.%s", imported.Name(), iface)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 package core
 
+import (
+	"time"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
 // Config for the running of the commands
 type Config struct {
 	DriverName       string
 	Schema           string
 	PkgName          string
+	PackageDoc       string
 	OutFolder        string
 	BaseDir          string
 	WhitelistTables  []string
@@ -16,16 +23,163 @@ type Config struct {
 	NoHooks          bool
 	NoAutoTimestamps bool
 	Wipe             bool
+	// MetadataOnly generates just the schema metadata file, no models.
+	MetadataOnly bool
+	// ForceInt64 maps every integer column to int64/uint64 (or the nullable
+	// equivalent) regardless of its width in the database.
+	ForceInt64 bool
+	// DateAsCivil maps date columns to types.Date (types.NullDate when
+	// nullable) so they aren't confused with datetimes.
+	DateAsCivil bool
+	// StringifyLargeInts marshals int64 and uint64 columns to JSON strings,
+	// so JavaScript clients don't lose precision (see db.Column.JSONString).
+	StringifyLargeInts bool
+	// StructTagCasing is how column names are cased in generated struct
+	// tags: snake (the default), camel or title.
+	StructTagCasing string
+	// IncludeDDL records each table's CREATE statement in Table.CreateSQL.
+	IncludeDDL bool
+	// DirectivePrefix marks generation directives in column comments, "@"
+	// if empty. Columns commented @skip are not generated.
+	DirectivePrefix string
+	// BlacklistColumnPattern is a regular expression, e.g. "_internal$",
+	// for columns to leave out of every table. It can't match primary key
+	// columns.
+	BlacklistColumnPattern string
+	// WhitelistColumns maps table names to the only columns of the table to
+	// generate, and BlacklistColumns to columns to leave out, e.g. large
+	// blobs. A table in both is generated with its whitelisted columns, the
+	// whitelist winning. Neither can leave out primary key columns.
+	WhitelistColumns map[string][]string
+	BlacklistColumns map[string][]string
+	// CheckMode generates without writing anything, and has Run return an
+	// *ErrOutOfDate if any file in OutFolder would be created or changed.
+	CheckMode bool
+	// DryRun generates without writing anything, and has Run print whether
+	// each file in OutFolder would be created, modified or unchanged, with
+	// their diffs if DryRunDiff is set. Wipe is ignored.
+	DryRun     bool
+	DryRunDiff bool
+	// ToStdout writes every generated file to stdout, each preceded by a
+	// "// file: <path>" line, instead of into OutFolder.
+	ToStdout bool
+	// InstrumentationHook is a package.Func, e.g. "metrics.Time", passed
+	// to renderers for wrapping generated queries with timing.
+	InstrumentationHook string
+	// NoFieldComments stops renderers emitting column comments as the doc
+	// comments of model fields (see TemplateData.FieldComment).
+	NoFieldComments bool
+	// ConnFromContext is a package.Func, e.g. "db.FromContext", passed to
+	// renderers for generating methods that take their executor from a
+	// context.Context rather than as an argument.
+	ConnFromContext string
+	// EmbedColumnGroups maps the name of a shared struct, e.g. Timestamps,
+	// to its column names. Tables with every column of a group get it in
+	// TemplateData.ColumnGroups, for renderers to embed the struct rather
+	// than inline the fields.
+	EmbedColumnGroups map[string][]string
+	// JSONAsRawMessage generates MySQL json columns as json.RawMessage, or
+	// null.JSON when nullable, dropping the sqlboiler types dependency.
+	JSONAsRawMessage bool
+	// GeometryPackage and GeometryType are the import path and name of the
+	// Go type MySQL geometry columns are generated as, e.g. a geometry
+	// library's type that keeps the column's db.Column.SRID, and a pointer
+	// to it when nullable. Without one they are generated as []byte, or
+	// null.Bytes when nullable. They apply to the ddl driver too.
+	GeometryPackage string
+	GeometryType    string
+	// ShardMerge maps regular expressions of sharded table names to the
+	// logical table generated for them (see db.Options.ShardMerge).
+	ShardMerge map[string]string
+	// KeepDuplicateForeignKeys generates a relationship for every foreign
+	// key, rather than only the first of those between the same columns.
+	KeepDuplicateForeignKeys bool
+	// AutoTouchColumns are the columns, as column or table.column, that
+	// renderers set to the current time on every generated update (see
+	// db.Column.AutoTouch). Nil means updated_at, unless NoAutoTimestamps.
+	AutoTouchColumns []string
+	// FileNamer, when set, returns the path relative to OutFolder of the
+	// file rendered for a table with a renderer's suffix, e.g. the flat
+	// tableName + ".gen.go", rather than tableName/tableName + suffix.
+	// Missing parent directories are created.
+	FileNamer func(tableName, suffix string) string
+	// TableTransform, when set, is given each table once it has been
+	// introspected, before StructNames apply and the tables are validated,
+	// and returns the table to render in its place, e.g. renamed or with
+	// columns added or dropped. Relationships, OptionalOnInsert and the
+	// indexes and checks over dropped columns are worked out again after it
+	// runs (see db.Derive), but added columns need their Go types set, as
+	// they aren't translated from their DBType, enums included. Join tables
+	// are left out after it runs, so it can also reclassify a table by
+	// setting IsJoinTable. Its errors stop generation.
+	TableTransform func(db.Table) (db.Table, error)
+	// DisplayColumns maps table names to the column their models' String()
+	// shows (see db.Table.DisplayColumn), the primary key if not listed.
+	DisplayColumns map[string]string
+	// TableNamesAreSingular uses table names as they are for the Go type
+	// names of their models, user to User, rather than singularizing them,
+	// users to User.
+	TableNamesAreSingular bool
+	// StructNames maps table names to the Go type names of their models,
+	// overriding the inflected db.Table.GoName. Two tables ending up with
+	// the same name is an error.
+	StructNames map[string]string
+
+	// UseSnapshot runs all introspection in one read-only, repeatable read
+	// transaction, for a consistent view of a database being changed.
+	UseSnapshot bool
+	// Concurrency is how many tables are rendered at once, the number of
+	// CPUs if zero. Renderers must be safe to call concurrently.
+	Concurrency int
+	// AsOf, when set, introspects the schema as it was at that time, with
+	// drivers implementing db.AsOfInterface. Other drivers fail in New.
+	AsOf time.Time
 
-	TableRenderer     TableRenderer
-	TableTestRenderer TableTestRenderer
+	// Connection pool settings for introspection, zero for database/sql's
+	// defaults.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// ConnectRetries is how many more times Run tries to connect when the
+	// database is unreachable or still starting up, for databases started
+	// just before generating. The first retry is after ConnectRetryDelay,
+	// a second if zero, and each one after waits twice as long, up to 30
+	// seconds.
+	ConnectRetries    int
+	ConnectRetryDelay time.Duration
+
+	// Logger receives diagnostic output, which is discarded if it is nil.
+	// With Debug, it is also given the introspected schema as JSON.
+	Logger Logger
+
+	TableRenderer      TableRenderer
+	TableTestRenderer  TableTestRenderer
+	SingletonRenderers []NamedSingletonRenderer
+	// Renderers are run for every table in addition to TableRenderer, which
+	// is shorthand for a Renderers entry with the "_gen.go" suffix.
+	Renderers []NamedRenderer
+	// SplitModelAndQueries renders each table with ModelRenderer into
+	// <table>_model_gen.go and QueryRenderer into <table>_query_gen.go,
+	// instead of with TableRenderer into a single <table>_gen.go.
+	SplitModelAndQueries bool
+	ModelRenderer        TableRenderer
+	QueryRenderer        TableRenderer
+	// SingleFileMode renders the Go files of every table into one file,
+	// models_gen.go, and their tests into models_test_gen.go, in the
+	// output folder rather than a folder per table. Their imports are
+	// merged under a single package clause, so renderers don't need to
+	// change. FileNamer and Concurrency don't apply to them.
+	SingleFileMode bool
 
 	Postgres PostgresConfig
 	MySQL    MySQLConfig
 	MSSQL    MSSQLConfig
+	Oracle   OracleConfig
+	DDL      DDLConfig
 }
 
-// PostgresConfig configures a postgres database
+// PostgresConfig configures a postgres database, or a cockroach or redshift
+// one
 type PostgresConfig struct {
 	User    string
 	Pass    string
@@ -33,16 +187,50 @@ type PostgresConfig struct {
 	Port    int
 	DBName  string
 	SSLMode string
+	// SSLCert and SSLKey are the paths of the client certificate and its
+	// key, for servers that require clients to authenticate with one.
+	SSLCert string
+	SSLKey  string
+	// SSLRootCert is the path of the certificate authorities the server's
+	// certificate is verified against. SSLMode verify-full requires it.
+	SSLRootCert string
 }
 
 // MySQLConfig configures a mysql database
 type MySQLConfig struct {
-	User    string
-	Pass    string
+	User string
+	Pass string
+	// Host is the server's host name, or the path of its Unix socket, e.g.
+	// /var/run/mysqld/mysqld.sock, which Port is ignored for.
 	Host    string
 	Port    int
 	DBName  string
 	SSLMode string
+	// Charset is the connection's character set, utf8mb4 if empty, so
+	// comments read right whatever the database's default charset is.
+	Charset string
+	// Collation is set as the session collation_connection, and recorded on
+	// the State so generated ORDER BY clauses can use it.
+	Collation string
+	// BoolColumns are table.column names to generate as bools regardless of
+	// their width, e.g. a tinyint(4) used as a flag.
+	BoolColumns []string
+	// IgnoreUnsigned generates unsigned integer columns as signed Go types,
+	// e.g. int64 rather than uint64 for bigint unsigned.
+	IgnoreUnsigned bool
+	// ZeroDateHandling is how zero dates (0000-00-00) are read during
+	// introspection: "parse" (the default) reads dates as time.Time, with
+	// zero dates as the zero time, and "string" reads dates as text.
+	// Zero date column defaults are kept as written either way, with
+	// db.Column.ZeroDateDefault set.
+	ZeroDateHandling string
+}
+
+// DDLConfig configures the ddl driver, which reads MySQL CREATE TABLE
+// statements from a file instead of connecting to a database. The MySQL
+// BoolColumns and IgnoreUnsigned settings apply to its columns.
+type DDLConfig struct {
+	Path string
 }
 
 // MSSQLConfig configures a mysql database
@@ -54,3 +242,14 @@ type MSSQLConfig struct {
 	DBName  string
 	SSLMode string
 }
+
+// OracleConfig configures an oracle database. ServiceName is the service
+// the database is registered with the listener as, e.g. ORCLPDB1. Oracle
+// support needs sqlgen built with -tags oracle, and cgo.
+type OracleConfig struct {
+	User        string
+	Pass        string
+	Host        string
+	Port        int
+	ServiceName string
+}
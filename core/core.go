@@ -3,15 +3,26 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mickeyreiss/sqlgen/db"
 	"github.com/mickeyreiss/sqlgen/db/drivers"
 	"github.com/pkg/errors"
+	"github.com/vattle/sqlboiler/strmangle"
 )
 
 // State holds the global data needed by most pieces to run
@@ -20,6 +31,42 @@ type State struct {
 
 	Driver db.Interface
 	Tables []db.Table
+
+	// Collation is the session collation introspection ran under, if one
+	// was configured.
+	Collation string
+
+	pkgDoc string
+	// stdout receives the output in ToStdout mode, os.Stdout if nil.
+	stdout io.Writer
+	// stale are the files found out of date in CheckMode, checked the
+	// files compared in it, and dryRun the files compared in DryRun mode,
+	// guarded by staleMu since tables are rendered concurrently.
+	stale   []string
+	checked map[string]bool
+	dryRun  []dryRunFile
+	staleMu sync.Mutex
+}
+
+// dryRunFile is how a file generated in DryRun mode compares to the one in
+// the output folder.
+type dryRunFile struct {
+	path   string
+	status string
+	diff   string
+}
+
+// ErrOutOfDate is returned by Run in CheckMode when generating would change
+// the output folder.
+type ErrOutOfDate struct {
+	// Files are the paths of the files that would be created or changed,
+	// and of generated files nothing would be rendered into any more, e.g.
+	// those of a dropped table.
+	Files []string
+}
+
+func (e *ErrOutOfDate) Error() string {
+	return fmt.Sprintf("generated files are out of date: %s", strings.Join(e.Files, ", "))
 }
 
 // New creates a new state based off of the config
@@ -33,23 +80,42 @@ func New(config *Config) (*State, error) {
 		return nil, err
 	}
 
-	if s.Config.TableRenderer == nil {
-		return nil, errors.New("config must specify a TableRenderer")
+	if s.Config.SplitModelAndQueries && (s.Config.ModelRenderer == nil || s.Config.QueryRenderer == nil) {
+		return nil, errors.New("config must specify a ModelRenderer and QueryRenderer to SplitModelAndQueries")
+	}
+
+	if !s.Config.AsOf.IsZero() {
+		adb, ok := s.Driver.(db.AsOfInterface)
+		if !ok {
+			return nil, errors.Errorf("the %s driver does not support introspecting as of a time", config.DriverName)
+		}
+		if err := adb.SetAsOf(s.Config.AsOf); err != nil {
+			return nil, errors.Wrap(err, "unable to introspect as of a time")
+		}
+	}
+
+	if err := checkStructNames(s.Config.StructNames); err != nil {
+		return nil, err
+	}
+
+	if len(s.tableRenderers()) == 0 && !s.Config.MetadataOnly {
+		return nil, errors.New("config must specify a TableRenderer or Renderers")
 	}
 
 	return s, nil
 }
 
 // Run executes the sqlboiler templates and outputs them to files based on the
-// state given.
-func (s *State) Run() error {
+// state given. The database is introspected with ctx, and generation stops
+// with its error once it is done, leaving the files written so far.
+func (s *State) Run(ctx context.Context) error {
 	var err error
 	// Connect to the driver database
-	if err = s.Driver.Open(); err != nil {
-		return errors.Wrap(err, "unable to connect to the database")
+	if err = s.connect(ctx); err != nil {
+		return err
 	}
 
-	err = s.initTables(s.Config.Schema, s.Config.WhitelistTables, s.Config.BlacklistTables)
+	err = s.initTables(ctx, s.Config.Schema, s.Config.WhitelistTables, s.Config.BlacklistTables)
 	if err != nil {
 		return errors.Wrap(err, "unable to initialize tables")
 	}
@@ -59,12 +125,15 @@ func (s *State) Run() error {
 		if err != nil {
 			return errors.Wrap(err, "unable to json marshal tables")
 		}
-		fmt.Printf("%s\n", b)
+		s.logger().Debugf("%s", b)
 	}
 
-	err = s.initOutFolder()
-	if err != nil {
-		return errors.Wrap(err, "unable to initialize the output folder")
+	s.stale, s.checked, s.dryRun = nil, map[string]bool{}, nil
+	if !s.Config.ToStdout && !s.Config.CheckMode && !s.Config.DryRun {
+		err = s.initOutFolder()
+		if err != nil {
+			return errors.Wrap(err, "unable to initialize the output folder")
+		}
 	}
 
 	//if !s.Config.NoTests {
@@ -72,94 +141,645 @@ func (s *State) Run() error {
 	//		return errors.Wrap(err, "unable to generate TestMain output")
 	//	}
 	//}
-	for _, table := range s.Tables {
-		if table.IsJoinTable {
-			continue
+
+	// The package doc is written once, above the package clause of the first
+	// generated file.
+	s.pkgDoc = packageDoc(s.Config.PkgName, s.Config.PackageDoc)
+
+	if s.Config.SingleFileMode {
+		err = s.renderSingleFiles(ctx)
+	} else {
+		err = s.renderTables(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, singleton := range s.singletonRenderers() {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
 		if err := func() error {
-			// Open model file.
-			w, err := s.openFile(table.Name, "_gen.go")
+			w, err := s.createFile(singleton.Filename)
 			if err != nil {
-				panic(err)
+				return err
 			}
 			defer w.Close()
 
-			// Generate the table templates
-			if err := s.Config.TableRenderer.Render(table, w); err != nil {
-				return errors.Wrap(err, "unable to generate output")
-			}
-
-			return nil
+			return s.render(w, s.takePkgDoc(singleton.Filename), func(w io.Writer) error {
+				return singleton.Renderer.RenderSingleton(s.templateData(db.Table{}), w)
+			})
 		}(); err != nil {
-			panic(errors.Wrapf(err, "while rendering %v", table.Name))
+			return errors.Wrapf(err, "unable to generate %s", singleton.Filename)
 		}
+	}
 
-		if testRenderer := s.Config.TableTestRenderer; !s.Config.NoTests && testRenderer != nil {
-			if err := func() error {
-				// Open model test file.
-				w, err := s.openFile(table.Name, "_test_gen.go")
-				if err != nil {
-					panic(err)
-				}
-				defer w.Close()
+	if s.Config.DryRun {
+		if err := s.reportDryRun(); err != nil {
+			return errors.Wrap(err, "unable to report the dry run")
+		}
+	}
 
-				// Generate the test templates
-				if err := testRenderer.RenderTest(table, w); err != nil {
-					return errors.Wrap(err, "unable to generate test output")
-				}
-				return nil
-			}(); err != nil {
-				panic(errors.Wrapf(err, "while rendering test for %v", table.Name))
+	if s.Config.CheckMode {
+		if err := s.checkLeftovers(); err != nil {
+			return errors.Wrap(err, "unable to check the output folder")
+		}
+	}
+
+	if len(s.stale) != 0 {
+		sort.Strings(s.stale)
+		return &ErrOutOfDate{Files: s.stale}
+	}
+
+	return nil
+}
+
+// reportDryRun writes which files generating would create, modify or leave
+// unchanged to stdout, with the diffs of DryRunDiff, followed by the count
+// of each.
+func (s *State) reportDryRun() error {
+	w := s.stdout
+	if w == nil {
+		w = os.Stdout
+	}
+
+	sort.Slice(s.dryRun, func(i, j int) bool { return s.dryRun[i].path < s.dryRun[j].path })
+
+	counts := map[string]int{}
+	for _, f := range s.dryRun {
+		counts[f.status]++
+		if _, err := fmt.Fprintf(w, "%-9s %s\n", f.status, f.path); err != nil {
+			return err
+		}
+		if s.Config.DryRunDiff && len(f.diff) != 0 {
+			if _, err := io.WriteString(w, f.diff); err != nil {
+				return err
 			}
 		}
 	}
 
+	_, err := fmt.Fprintf(w, "%d created, %d modified, %d unchanged\n", counts["created"], counts["modified"], counts["unchanged"])
+	return err
+}
+
+// StreamSchema writes the schema to w as newline-delimited JSON, one table
+// per line, as each table is introspected. Unlike the Debug dump it never
+// holds the whole schema in memory, so relationships are not included.
+func (s *State) StreamSchema(ctx context.Context, w io.Writer) error {
+	if err := s.Driver.Open(); err != nil {
+		return errors.Wrap(err, "unable to connect to the database")
+	}
+
+	enc := json.NewEncoder(w)
+	err := db.StreamTables(ctx, s.Driver, s.Config.Schema, s.Config.WhitelistTables, s.Config.BlacklistTables, s.tableOptions(), func(t db.Table) error {
+		return enc.Encode(t)
+	})
+
+	return errors.Wrap(err, "unable to stream tables")
+}
+
+// ListSchemas connects to the database and lists the schemas that can be
+// generated from, so users can pick one.
+func (s *State) ListSchemas(ctx context.Context) ([]string, error) {
+	if err := s.Driver.Open(); err != nil {
+		return nil, errors.Wrap(err, "unable to connect to the database")
+	}
+
+	schemas, err := s.Driver.Schemas(ctx)
+	return schemas, errors.Wrap(err, "unable to list schemas")
+}
+
+// openFile opens a file for rendering a go file, at the path filePath gives.
+func (s *State) openFile(filename, suffix string) (io.WriteCloser, error) {
+	path, err := s.filePath(filename, suffix)
+	if err != nil {
+		return nil, err
+	}
+	return s.createFile(path)
+}
+
+// filePath is the path relative to the output folder of the file rendered
+// for a table with suffix: the one FileNamer gives if it is set, and
+// <table>/<table><suffix> otherwise.
+func (s *State) filePath(filename, suffix string) (string, error) {
+	if s.Config.FileNamer == nil {
+		return filepath.Join(filename, filename+suffix), nil
+	}
+
+	path := filepath.Clean(s.Config.FileNamer(filename, suffix))
+	if filepath.IsAbs(path) || path == "." || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("file namer returned %q for %s, want a path within the output folder", path, filename)
+	}
+	return path, nil
+}
+
+// checkFilePaths makes sure no two jobs render into the same file, which
+// a FileNamer ignoring the suffix or the table name would have them do.
+func (s *State) checkFilePaths(jobs []tableJob) error {
+	seen := map[string]tableJob{}
+	for _, job := range jobs {
+		path, err := s.filePath(job.table.Name, job.suffix)
+		if err != nil {
+			return err
+		}
+		if other, ok := seen[path]; ok {
+			return errors.Errorf("file namer returned %q for both %s (%s) and %s (%s)", path, other.table.Name, other.suffix, job.table.Name, job.suffix)
+		}
+		seen[path] = job
+	}
+
 	return nil
 }
 
-// openFile opens a file for rendering a go file.
-func (s *State) openFile(filename, suffix string) (*os.File, error) {
-	path := filepath.Join(s.Config.OutFolder, filename)
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+// createFile creates the file at path, relative to the output folder, along
+// with any missing parent directories. In ToStdout mode it instead writes a
+// separator naming path to stdout and returns stdout, and in CheckMode and
+// DryRun mode it returns a buffer compared against the existing file once
+// closed.
+func (s *State) createFile(path string) (io.WriteCloser, error) {
+	if s.Config.CheckMode || s.Config.DryRun {
+		return &checkFile{path: filepath.Join(s.Config.OutFolder, path), state: s}, nil
+	}
+
+	if s.Config.ToStdout {
+		w := s.stdout
+		if w == nil {
+			w = os.Stdout
+		}
+		if _, err := fmt.Fprintf(w, "// file: %s\n", filepath.ToSlash(path)); err != nil {
+			return nil, err
+		}
+		return nopCloser{w}, nil
+	}
+
+	path = filepath.Join(s.Config.OutFolder, path)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	// Files generated by older versions are read-only, so they're removed
+	// rather than opened for writing.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
-	w, err := os.OpenFile(filepath.Join(path, filename+suffix), os.O_WRONLY|os.O_CREATE, 0444)
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return nil, err
 	}
 	return w, nil
 }
 
+// nopCloser keeps a shared writer such as stdout open when a file is closed.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// checkFile buffers a file generated in CheckMode or DryRun mode. Closing it
+// compares its contents with what is already at path, recording the file as
+// stale in CheckMode unless they match, and how they compare in DryRun mode.
+type checkFile struct {
+	bytes.Buffer
+	path  string
+	state *State
+}
+
+func (c *checkFile) Close() error {
+	status := "unchanged"
+	existing, err := ioutil.ReadFile(c.path)
+	switch {
+	case err != nil:
+		status = "created"
+	case !bytes.Equal(existing, c.Bytes()):
+		status = "modified"
+	}
+
+	c.state.staleMu.Lock()
+	defer c.state.staleMu.Unlock()
+
+	c.state.checked[c.path] = true
+	if c.state.Config.CheckMode && status != "unchanged" {
+		c.state.stale = append(c.state.stale, c.path)
+	}
+	if c.state.Config.DryRun {
+		f := dryRunFile{path: c.path, status: status}
+		if c.state.Config.DryRunDiff {
+			f.diff = unifiedDiff(c.path, existing, c.Bytes())
+		}
+		c.state.dryRun = append(c.state.dryRun, f)
+	}
+	return nil
+}
+
+// generatedHeader is the line marking generated Go files, per
+// https://golang.org/s/generatedcode.
+var generatedHeader = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// checkLeftovers adds the generated files in the output folder that no
+// renderer wrote in CheckMode to the stale files. Files are taken to be
+// generated if they have the suffix of a table renderer, or the generated
+// code header.
+func (s *State) checkLeftovers() error {
+	var suffixes []string
+	for _, r := range s.tableRenderers() {
+		suffixes = append(suffixes, r.Suffix)
+	}
+	if s.Config.TableTestRenderer != nil {
+		suffixes = append(suffixes, "_test_gen.go")
+	}
+
+	err := filepath.Walk(s.Config.OutFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.Config.OutFolder {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || s.checked[path] {
+			return nil
+		}
+
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(path, suffix) {
+				s.stale = append(s.stale, path)
+				return nil
+			}
+		}
+
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if generatedHeader.Match(b) {
+			s.stale = append(s.stale, path)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// takePkgDoc returns the package doc if filename is the first Go file to be
+// rendered, for render to insert it above the package clause.
+func (s *State) takePkgDoc(filename string) string {
+	if !strings.HasSuffix(filename, ".go") {
+		return ""
+	}
+
+	doc := s.pkgDoc
+	s.pkgDoc = ""
+	return doc
+}
+
+// render writes the output of fn to w, with doc, if any, inserted above its
+// package clause.
+func (s *State) render(w io.Writer, doc string, fn func(w io.Writer) error) error {
+	if len(doc) == 0 {
+		return fn(w)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := fn(buf); err != nil {
+		return err
+	}
+	_, err := w.Write(insertPackageDoc(buf.Bytes(), doc))
+	return err
+}
+
+// tableJob renders one file of a table.
+type tableJob struct {
+	table  db.Table
+	suffix string
+	doc    string
+	render func(data *TemplateData, w io.Writer) error
+}
+
+// tableJobs lists the files to render for the tables, in order, handing the
+// package doc to the first Go file.
+func (s *State) tableJobs() []tableJob {
+	if s.Config.MetadataOnly {
+		return nil
+	}
+
+	var jobs []tableJob
+	for _, table := range s.Tables {
+		if table.IsJoinTable {
+			continue
+		}
+
+		for _, renderer := range s.tableRenderers() {
+			jobs = append(jobs, tableJob{table: table, suffix: renderer.Suffix, render: renderer.Renderer.Render})
+		}
+		if testRenderer := s.Config.TableTestRenderer; !s.Config.NoTests && testRenderer != nil {
+			jobs = append(jobs, tableJob{table: table, suffix: "_test_gen.go", render: testRenderer.RenderTest})
+		}
+	}
+
+	for i := range jobs {
+		jobs[i].doc = s.takePkgDoc(jobs[i].suffix)
+	}
+
+	return jobs
+}
+
+// concurrency is the number of tables rendered at once: Config.Concurrency,
+// defaulting to the number of CPUs, and always 1 writing to stdout, where
+// the files would interleave.
+func (s *State) concurrency() int {
+	switch {
+	case s.Config.ToStdout:
+		return 1
+	case s.Config.Concurrency > 0:
+		return s.Config.Concurrency
+	}
+
+	return runtime.NumCPU()
+}
+
+// renderTables renders the files of every table on a pool of workers. The
+// first error, or ctx being done, stops the workers from starting more files
+// and is returned.
+func (s *State) renderTables(ctx context.Context) error {
+	pending := s.tableJobs()
+	if err := s.checkFilePaths(pending); err != nil {
+		return err
+	}
+
+	jobs := make(chan tableJob)
+	quit := make(chan struct{})
+
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			close(quit)
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := s.renderTable(job); err != nil {
+					fail(errors.Wrapf(err, "while rendering %v", job.table.Name))
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, job := range pending {
+		select {
+		case jobs <- job:
+		case <-quit:
+			break feed
+		case <-ctx.Done():
+			fail(ctx.Err())
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
+
+// renderTable opens the output file of job and renders it, building the
+// table's TemplateData for this file alone.
+func (s *State) renderTable(job tableJob) error {
+	s.logger().Infof("generating table %s (%s)", job.table.Name, job.suffix)
+
+	w, err := s.openFile(job.table.Name, job.suffix)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if err := s.render(w, job.doc, func(w io.Writer) error { return job.render(s.templateData(job.table), w) }); err != nil {
+		return errors.Wrap(err, "unable to generate output")
+	}
+
+	return nil
+}
+
+// templateData builds the data renderers are given for table.
+func (s *State) templateData(table db.Table) *TemplateData {
+	return &TemplateData{
+		Table:     table,
+		Tables:    s.Tables,
+		PkgName:   s.Config.PkgName,
+		Collation: s.Collation,
+
+		InstrumentationHook: s.Config.InstrumentationHook,
+		ConnFromContext:     s.Config.ConnFromContext,
+		NoFieldComments:     s.Config.NoFieldComments,
+		ColumnGroups:        columnGroups(table, s.Config.EmbedColumnGroups),
+
+		LQ: s.Driver.LeftQuote(),
+		RQ: s.Driver.RightQuote(),
+
+		MaxPlaceholders: s.Driver.MaxPlaceholders(),
+		QuoteLiteral:    s.Driver.QuoteLiteral,
+	}
+}
+
+// tableRenderers returns the renderers run for every table: TableRenderer,
+// if set, or ModelRenderer and QueryRenderer when SplitModelAndQueries,
+// followed by Renderers.
+func (s *State) tableRenderers() []NamedRenderer {
+	var renderers []NamedRenderer
+	if s.Config.SplitModelAndQueries {
+		renderers = append(renderers,
+			NamedRenderer{Suffix: "_model_gen.go", Renderer: s.Config.ModelRenderer},
+			NamedRenderer{Suffix: "_query_gen.go", Renderer: s.Config.QueryRenderer},
+		)
+	} else if s.Config.TableRenderer != nil {
+		renderers = append(renderers, NamedRenderer{Suffix: "_gen.go", Renderer: s.Config.TableRenderer})
+	}
+
+	return append(renderers, s.Config.Renderers...)
+}
+
+// singletonRenderers returns the renderers for files that cover every table.
+// In MetadataOnly mode that is just the schema metadata file.
+func (s *State) singletonRenderers() []NamedSingletonRenderer {
+	metadata := NamedSingletonRenderer{
+		Filename: MetadataFilename,
+		Renderer: &MetadataRenderer{PkgName: s.Config.PkgName},
+	}
+
+	if s.Config.MetadataOnly {
+		return []NamedSingletonRenderer{metadata}
+	}
+
+	return s.Config.SingletonRenderers
+}
+
 // Cleanup closes any resources that must be closed
 func (s *State) Cleanup() error {
 	s.Driver.Close()
 	return nil
 }
 
+// maxConnectRetryDelay caps the doubling wait between connection attempts.
+const maxConnectRetryDelay = 30 * time.Second
+
+// connect opens the driver. With ConnectRetries, it checks the database is
+// ready by listing its schemas, and retries with exponential backoff while
+// that fails because the database is unreachable or starting up.
+func (s *State) connect(ctx context.Context) error {
+	if s.Config.ConnectRetries <= 0 {
+		return errors.Wrap(s.Driver.Open(), "unable to connect to the database")
+	}
+
+	delay := s.Config.ConnectRetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	var err error
+	attempts := 0
+	for {
+		attempts++
+		if err = s.Driver.Open(); err == nil {
+			if _, err = s.Driver.Schemas(ctx); err == nil {
+				return nil
+			}
+			s.Driver.Close()
+		}
+
+		if !drivers.IsConnError(err) {
+			return errors.Wrap(err, "unable to connect to the database")
+		}
+		if attempts > s.Config.ConnectRetries {
+			break
+		}
+		s.logger().Warnf("unable to connect to the database, retrying in %s: %s", delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if delay *= 2; delay > maxConnectRetryDelay {
+			delay = maxConnectRetryDelay
+		}
+	}
+
+	return errors.Wrapf(err, "unable to connect to the database after %d attempts", attempts)
+}
+
 // initDriver attempts to set the state Interface based off the passed in
 // driver flag value. If an invalid flag string is provided an error is returned.
 func (s *State) initDriver(driverName string) error {
+	if driverName == "postgres" || driverName == "cockroach" || driverName == "redshift" {
+		if s.Config.Postgres.SSLMode == "verify-full" && s.Config.Postgres.SSLRootCert == "" {
+			return errors.New("postgres sslmode verify-full needs an sslrootcert to verify the server's certificate against")
+		}
+	}
+
 	// Create a driver based off driver flag
 	switch driverName {
 	case "postgres":
-		s.Driver = drivers.NewPostgresDriver(
+		driver := drivers.NewPostgresDriver(
+			s.Config.Postgres.User,
+			s.Config.Postgres.Pass,
+			s.Config.Postgres.DBName,
+			s.Config.Postgres.Host,
+			s.Config.Postgres.Port,
+			s.Config.Postgres.SSLMode,
+			s.Config.Postgres.SSLCert,
+			s.Config.Postgres.SSLKey,
+			s.Config.Postgres.SSLRootCert,
+		)
+		driver.Pool = s.pool()
+		driver.UseSnapshot = s.Config.UseSnapshot
+		driver.Warnf = s.logger().Warnf
+		s.Driver = driver
+	case "cockroach":
+		driver := drivers.NewCockroachDriver(
+			s.Config.Postgres.User,
+			s.Config.Postgres.Pass,
+			s.Config.Postgres.DBName,
+			s.Config.Postgres.Host,
+			s.Config.Postgres.Port,
+			s.Config.Postgres.SSLMode,
+			s.Config.Postgres.SSLCert,
+			s.Config.Postgres.SSLKey,
+			s.Config.Postgres.SSLRootCert,
+		)
+		driver.Pool = s.pool()
+		driver.UseSnapshot = s.Config.UseSnapshot
+		driver.Warnf = s.logger().Warnf
+		s.Driver = driver
+	case "redshift":
+		driver := drivers.NewRedshiftDriver(
 			s.Config.Postgres.User,
 			s.Config.Postgres.Pass,
 			s.Config.Postgres.DBName,
 			s.Config.Postgres.Host,
 			s.Config.Postgres.Port,
 			s.Config.Postgres.SSLMode,
+			s.Config.Postgres.SSLCert,
+			s.Config.Postgres.SSLKey,
+			s.Config.Postgres.SSLRootCert,
 		)
+		driver.Pool = s.pool()
+		driver.UseSnapshot = s.Config.UseSnapshot
+		driver.Warnf = s.logger().Warnf
+		s.Driver = driver
 	case "mysql":
-		s.Driver = drivers.NewMySQLDriver(
+		switch s.Config.MySQL.ZeroDateHandling {
+		case "", drivers.MySQLZeroDateParse, drivers.MySQLZeroDateString:
+		default:
+			return errors.Errorf("unknown mysql zero date handling %q", s.Config.MySQL.ZeroDateHandling)
+		}
+
+		driver := drivers.NewMySQLDriver(
 			s.Config.MySQL.User,
 			s.Config.MySQL.Pass,
 			s.Config.MySQL.DBName,
 			s.Config.MySQL.Host,
 			s.Config.MySQL.Port,
 			s.Config.MySQL.SSLMode,
+			s.Config.MySQL.Charset,
+			s.Config.MySQL.Collation,
+			s.Config.MySQL.ZeroDateHandling,
 		)
+		driver.Pool = s.pool()
+		driver.UseSnapshot = s.Config.UseSnapshot
+		driver.BoolColumns = s.Config.MySQL.BoolColumns
+		driver.JSONAsRawMessage = s.Config.JSONAsRawMessage
+		driver.IgnoreUnsigned = s.Config.MySQL.IgnoreUnsigned
+		driver.GeometryPackage = s.Config.GeometryPackage
+		driver.GeometryType = s.Config.GeometryType
+		s.Driver = driver
+		s.Collation = s.Config.MySQL.Collation
+	case "oracle":
+		driver := drivers.NewOracleDriver(
+			s.Config.Oracle.User,
+			s.Config.Oracle.Pass,
+			s.Config.Oracle.Host,
+			s.Config.Oracle.Port,
+			s.Config.Oracle.ServiceName,
+		)
+		driver.Pool = s.pool()
+		s.Driver = driver
+	case "ddl":
+		driver := drivers.NewDDLFileDriver(s.Config.DDL.Path)
+		driver.MySQL.BoolColumns = s.Config.MySQL.BoolColumns
+		driver.MySQL.JSONAsRawMessage = s.Config.JSONAsRawMessage
+		driver.MySQL.IgnoreUnsigned = s.Config.MySQL.IgnoreUnsigned
+		driver.MySQL.GeometryPackage = s.Config.GeometryPackage
+		driver.MySQL.GeometryType = s.Config.GeometryType
+		s.Driver = driver
 	case "mock":
 		s.Driver = &drivers.MockDriver{}
 	}
@@ -171,25 +791,105 @@ func (s *State) initDriver(driverName string) error {
 	return nil
 }
 
+// pool builds the driver connection pool settings from the config.
+func (s *State) pool() drivers.Pool {
+	return drivers.Pool{
+		MaxOpenConns:    s.Config.MaxOpenConns,
+		MaxIdleConns:    s.Config.MaxIdleConns,
+		ConnMaxLifetime: s.Config.ConnMaxLifetime,
+	}
+}
+
 // initTables retrieves all "public" schema table names from the database.
-func (s *State) initTables(schema string, whitelist, blacklist []string) error {
+func (s *State) initTables(ctx context.Context, schema string, whitelist, blacklist []string) error {
+	// Every check runs before failing, so a schema with several problems is
+	// fixed in one go.
 	var err error
-	s.Tables, err = db.Tables(s.Driver, schema, whitelist, blacklist)
-	if err != nil {
+	s.Tables, err = db.Tables(ctx, s.Driver, schema, whitelist, blacklist, s.tableOptions())
+	problems, ok := err.(*db.ValidationError)
+	if err != nil && !ok {
 		return errors.Wrap(err, "unable to fetch table data")
 	}
+	if problems == nil {
+		problems = &db.ValidationError{}
+	}
 
 	if len(s.Tables) == 0 {
 		return errors.New("no tables found in database")
 	}
 
-	if err := checkPKeys(s.Tables); err != nil {
+	// Enum types named after tables follow the tables' final Go names.
+	goNames := make([]string, len(s.Tables))
+	for i, t := range s.Tables {
+		goNames[i] = t.GoName
+	}
+	if err = s.transformTables(); err != nil {
 		return err
 	}
+	setStructNames(s.Tables, s.Config.StructNames)
+	for i := range s.Tables {
+		db.RenameEnumTypes(&s.Tables[i], goNames[i])
+	}
+	// The transform may have renamed, added or dropped tables and columns
+	// the relationships and the like were worked out from.
+	db.Derive(s.Tables, s.tableOptions())
+	setAutoTouch(s.Tables, s.autoTouchColumns())
+
+	setDisplayColumns(s.Tables, s.Config.DisplayColumns, problems)
+	checkPKeys(s.Tables, problems)
+	checkGoNames(s.Tables, problems)
+	checkTags(s.Tables, s.Config.StructTagCasing, problems)
+
+	return problems.Err()
+}
+
+// transformTables replaces each table with what TableTransform returns for
+// it, if it is set.
+func (s *State) transformTables() error {
+	if s.Config.TableTransform == nil {
+		return nil
+	}
+
+	for i, t := range s.Tables {
+		transformed, err := s.Config.TableTransform(t)
+		if err != nil {
+			return errors.Wrapf(err, "unable to transform table %s", t.Name)
+		}
+		s.Tables[i] = transformed
+	}
 
 	return nil
 }
 
+// tableOptions builds the db.Tables options from the config.
+func (s *State) tableOptions() db.Options {
+	return db.Options{
+		ForceInt64:  s.Config.ForceInt64,
+		DateAsCivil: s.Config.DateAsCivil,
+		IncludeDDL:  s.Config.IncludeDDL,
+
+		StringifyLargeInts:     s.Config.StringifyLargeInts,
+		DirectivePrefix:        s.Config.DirectivePrefix,
+		BlacklistColumnPattern: s.Config.BlacklistColumnPattern,
+		WhitelistColumns:       s.Config.WhitelistColumns,
+		BlacklistColumns:       s.Config.BlacklistColumns,
+		ShardMerge:             s.Config.ShardMerge,
+
+		KeepDuplicateForeignKeys: s.Config.KeepDuplicateForeignKeys,
+		SingularTableNames:       s.Config.TableNamesAreSingular,
+		Warnf:                    s.logger().Warnf,
+	}
+}
+
+// logger is the configured Logger, or one discarding everything.
+func (s *State) logger() Logger {
+	if s.Config.Logger == nil {
+		return nopLogger{}
+	}
+
+	return s.Config.Logger
+}
+
 // initOutFolder creates the folder that will hold the generated output.
 func (s *State) initOutFolder() error {
 	if s.Config.Wipe {
@@ -201,18 +901,148 @@ func (s *State) initOutFolder() error {
 	return os.MkdirAll(s.Config.OutFolder, os.ModePerm)
 }
 
-// checkPKeys ensures every table has a primary key column
-func checkPKeys(tables []db.Table) error {
-	var missingPkey []string
-	for _, t := range tables {
-		if t.PKey == nil {
-			missingPkey = append(missingPkey, t.Name)
+// packageDoc formats doc as the godoc comment for package pkgName, adding the
+// conventional "Package <name>" prefix when doc doesn't already start with it.
+func packageDoc(pkgName, doc string) string {
+	doc = strings.TrimSpace(doc)
+	if len(doc) == 0 {
+		return ""
+	}
+
+	if !strings.HasPrefix(doc, "Package "+pkgName+" ") {
+		doc = "Package " + pkgName + " " + doc
+	}
+
+	buf := &bytes.Buffer{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			buf.WriteString("//\n")
+			continue
+		}
+		fmt.Fprintf(buf, "// %s\n", line)
+	}
+
+	return buf.String()
+}
+
+// insertPackageDoc places doc directly above the package clause of src so it
+// is picked up by godoc, even if src starts with a generated-code header.
+func insertPackageDoc(src []byte, doc string) []byte {
+	i := 0
+	if !bytes.HasPrefix(src, []byte("package ")) {
+		i = bytes.Index(src, []byte("\npackage "))
+		if i < 0 {
+			return append([]byte(doc), src...)
+		}
+		i++
+	}
+
+	out := make([]byte, 0, len(src)+len(doc))
+	out = append(out, src[:i]...)
+	out = append(out, doc...)
+	return append(out, src[i:]...)
+}
+
+// setStructNames overrides the GoName of each table in names, after any
+// other naming has been applied.
+func setStructNames(tables []db.Table, names map[string]string) {
+	for i, t := range tables {
+		if name, ok := names[t.Name]; ok {
+			tables[i].GoName = name
+		}
+	}
+}
+
+// autoTouchColumns are the configured AutoTouchColumns, or updated_at.
+func (s *State) autoTouchColumns() []string {
+	if s.Config.AutoTouchColumns != nil || s.Config.NoAutoTimestamps {
+		return s.Config.AutoTouchColumns
+	}
+	return []string{"updated_at"}
+}
+
+// setAutoTouch flags the columns named in columns, either by themselves for
+// every table or as table.column, as AutoTouch. Columns the database bumps
+// on update are left alone, so they aren't set twice.
+func setAutoTouch(tables []db.Table, columns []string) {
+	for i, t := range tables {
+		for j, c := range t.Columns {
+			if c.AutoUpdateTime {
+				continue
+			}
+			if strmangle.SetInclude(c.Name, columns) || strmangle.SetInclude(t.Name+"."+c.Name, columns) {
+				tables[i].Columns[j].AutoTouch = true
+			}
+		}
+	}
+}
+
+// setDisplayColumns sets the DisplayColumn of each table to the column in
+// columns, or the first column of its primary key. Listed columns the table
+// doesn't have are added to problems.
+func setDisplayColumns(tables []db.Table, columns map[string]string, problems *db.ValidationError) {
+	for i, t := range tables {
+		if name, ok := columns[t.Name]; ok {
+			if !strmangle.SetInclude(name, db.ColumnNames(t.Columns)) {
+				problems.Add(t.Name, "display column %s not found", name)
+			}
+			tables[i].DisplayColumn = name
+			continue
+		}
+		if t.PKey != nil && len(t.PKey.Columns) != 0 {
+			tables[i].DisplayColumn = t.PKey.Columns[0]
+		}
+	}
+}
+
+// checkStructNames ensures each struct name override is an exported Go
+// identifier.
+func checkStructNames(names map[string]string) error {
+	var invalid []string
+	for table, name := range names {
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			invalid = append(invalid, table+": "+name)
 		}
 	}
 
-	if len(missingPkey) != 0 {
-		return errors.Errorf("primary key missing in tables (%s)", strings.Join(missingPkey, ", "))
+	if len(invalid) != 0 {
+		sort.Strings(invalid)
+		return errors.Errorf("struct names must be exported identifiers (%s)", strings.Join(invalid, ", "))
 	}
 
 	return nil
 }
+
+// checkGoNames adds every table whose final Go name, inflected or from
+// StructNames, is also another table's to problems, as their models would
+// be declared twice. Join tables have no models.
+func checkGoNames(tables []db.Table, problems *db.ValidationError) {
+	first := map[string]string{}
+	for _, t := range tables {
+		if t.IsJoinTable {
+			continue
+		}
+		if other, ok := first[t.GoName]; ok {
+			problems.Add(t.Name, "struct name %s is also the struct name of %s", t.GoName, other)
+			continue
+		}
+		first[t.GoName] = t.Name
+	}
+}
+
+// checkPKeys adds every table without a primary key, or with one on a
+// column it doesn't have, to problems.
+func checkPKeys(tables []db.Table, problems *db.ValidationError) {
+	for _, t := range tables {
+		if t.PKey == nil {
+			problems.Add(t.Name, "primary key missing")
+			continue
+		}
+		for _, c := range t.PKey.Columns {
+			if !strmangle.SetInclude(c, db.ColumnNames(t.Columns)) {
+				problems.Add(t.Name, "primary key column %s left out", c)
+			}
+		}
+	}
+}
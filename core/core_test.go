@@ -1,5 +1,1106 @@
 package core
 
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/mickeyreiss/sqlgen/db/drivers"
+	"github.com/pkg/errors"
+)
+
+type testRenderer struct{}
+
+func (testRenderer) Render(data *TemplateData, w io.Writer) error {
+	_, err := io.WriteString(w, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage models\n\n// "+data.Table.Name+"\n")
+	return err
+}
+
+type protoRenderer struct{}
+
+func (protoRenderer) Render(data *TemplateData, w io.Writer) error {
+	_, err := io.WriteString(w, "syntax = \"proto3\";\n\npackage models;\n\nmessage "+data.Table.Name+" {}\n")
+	return err
+}
+
+type modelRenderer struct{}
+
+func (modelRenderer) Render(data *TemplateData, w io.Writer) error {
+	_, err := io.WriteString(w, "package models\n\ntype "+data.Table.Name+" struct{}\n")
+	return err
+}
+
+type queryRenderer struct{}
+
+func (queryRenderer) Render(data *TemplateData, w io.Writer) error {
+	_, err := io.WriteString(w, "package models\n\nfunc (o *"+data.Table.Name+") Insert() error { return nil }\n")
+	return err
+}
+
+// dataRenderer records the TemplateData of each table it renders.
+type dataRenderer struct {
+	mu   sync.Mutex
+	data map[string]*TemplateData
+}
+
+func (d *dataRenderer) Render(data *TemplateData, w io.Writer) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.data[data.Table.Name] = data
+	_, err := io.WriteString(w, "package models\n")
+	return err
+}
+
+// readOutput returns the contents of every file under dir, keyed by path
+// relative to dir.
+func readOutput(t *testing.T, dir string) map[string]string {
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to read output: %s", err)
+	}
+
+	return files
+}
+
+func testState(t *testing.T, driver db.Interface) (*State, func()) {
+	out, err := ioutil.TempDir("", "sqlgen")
+	if err != nil {
+		t.Fatalf("unable to create tempdir: %s", err)
+	}
+
+	s := &State{
+		Config: &Config{
+			PkgName:       "models",
+			OutFolder:     out,
+			TableRenderer: testRenderer{},
+		},
+		Driver: driver,
+	}
+
+	return s, func() { os.RemoveAll(out) }
+}
+
+func TestRunDriverErrors(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+
+	tests := []struct {
+		Driver *drivers.MockDriver
+		Want   string
+	}{
+		{&drivers.MockDriver{FailOnOpen: boom}, "unable to connect to the database: boom"},
+		{&drivers.MockDriver{FailOnTableNames: boom}, "unable to initialize tables: unable to fetch table data: unable to get table names: boom"},
+		{&drivers.MockDriver{FailOnColumns: boom}, "unable to initialize tables: unable to fetch table data: unable to fetch table column info (pilots): boom"},
+		{&drivers.MockDriver{FailOnPrimaryKeys: boom}, "unable to initialize tables: unable to fetch table data: unable to fetch table pkey info (pilots): boom"},
+		{&drivers.MockDriver{FailOnForeignKeys: boom}, "unable to initialize tables: unable to fetch table data: unable to fetch table fkey info (pilots): boom"},
+		{&drivers.MockDriver{FailOnIndexes: boom}, "unable to initialize tables: unable to fetch table data: unable to fetch table index info (pilots): boom"},
+	}
+
+	for i, test := range tests {
+		s, cleanup := testState(t, test.Driver)
+
+		err := s.Run(context.Background())
+		cleanup()
+
+		if err == nil {
+			t.Errorf("%d) expected an error", i)
+			continue
+		}
+		if got := err.Error(); got != test.Want {
+			t.Errorf("%d) wrong error:\nwant: %s\ngot:  %s", i, test.Want, got)
+		}
+		if errors.Cause(err) != boom {
+			t.Errorf("%d) wrong cause: %v", i, errors.Cause(err))
+		}
+	}
+}
+
+// unreadyDriver fails to list schemas with err its first failures times.
+type unreadyDriver struct {
+	*drivers.MockDriver
+	err      error
+	failures int
+	opens    int
+}
+
+func (u *unreadyDriver) Open() error {
+	u.opens++
+	return u.MockDriver.Open()
+}
+
+func (u *unreadyDriver) Schemas(ctx context.Context) ([]string, error) {
+	if u.failures > 0 {
+		u.failures--
+		return nil, u.err
+	}
+	return u.MockDriver.Schemas(ctx)
+}
+
+func TestRunConnectRetries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Err      error
+		Failures int
+		Opens    int
+		Want     string
+	}{
+		{driver.ErrBadConn, 2, 3, ""},
+		{driver.ErrBadConn, 5, 4, "unable to connect to the database after 4 attempts: driver: bad connection"},
+		{errors.New("relation does not exist"), 1, 1, "unable to connect to the database: relation does not exist"},
+	}
+
+	for i, test := range tests {
+		d := &unreadyDriver{MockDriver: &drivers.MockDriver{}, err: test.Err, failures: test.Failures}
+		s, cleanup := testState(t, d)
+		s.Config.ConnectRetries = 3
+		s.Config.ConnectRetryDelay = time.Millisecond
+
+		err := s.Run(context.Background())
+		cleanup()
+
+		if test.Want == "" && err != nil {
+			t.Errorf("%d) want a connection after retrying, got: %s", i, err)
+		} else if test.Want != "" && (err == nil || err.Error() != test.Want) {
+			t.Errorf("%d) want: %s\ngot:  %v", i, test.Want, err)
+		}
+		if d.opens != test.Opens {
+			t.Errorf("%d) want %d attempts, got %d", i, test.Opens, d.opens)
+		}
+	}
+}
+
+// cancelingDriver cancels generation once it has introspected the columns
+// of a table.
+type cancelingDriver struct {
+	*drivers.MockDriver
+	cancel  context.CancelFunc
+	columns int
+}
+
+func (c *cancelingDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
+	c.columns++
+	c.cancel()
+	return c.MockDriver.Columns(ctx, schema, tableName)
+}
+
+func TestRunCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := &cancelingDriver{MockDriver: &drivers.MockDriver{}, cancel: cancel}
+	s, cleanup := testState(t, d)
+	defer cleanup()
+
+	err := s.Run(ctx)
+	if errors.Cause(err) != context.Canceled {
+		t.Fatalf("want the context's error, got: %v", err)
+	}
+	if d.columns != 1 {
+		t.Errorf("want introspection to stop after the first table, got %d tables", d.columns)
+	}
+	if files := readOutput(t, s.Config.OutFolder); len(files) != 0 {
+		t.Errorf("want no files generated, got %d", len(files))
+	}
+}
+
+// fieldsRenderer writes the fields of a table and the tables it has many of.
+type fieldsRenderer struct{}
+
+func (fieldsRenderer) Render(data *TemplateData, w io.Writer) error {
+	fmt.Fprintf(w, "package models\n\ntype %s struct {\n", data.Table.GoName)
+	for _, c := range data.Table.Columns {
+		fmt.Fprintf(w, "\t%s %s\n", db.GoName(c.Name), c.GoTypeExpr())
+	}
+	for _, r := range data.Table.ToManyRelationships {
+		fmt.Fprintf(w, "\t// has many %s\n", r.ForeignTable)
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+func TestRunSeededMockDriver(t *testing.T) {
+	t.Parallel()
+
+	d := &drivers.MockDriver{
+		MockTables: map[string][]db.Column{
+			"authors": {{Name: "id", DBType: "integer"}, {Name: "name", DBType: "text"}},
+			"books":   {{Name: "id", DBType: "integer"}, {Name: "author_id", DBType: "integer"}, {Name: "title", DBType: "text", Nullable: true}},
+			"drafts":  {{Name: "id", DBType: "integer"}},
+		},
+		MockPKeys: map[string]*db.PrimaryKey{
+			"authors": {Name: "authors_pkey", Columns: []string{"id"}},
+			"books":   {Name: "books_pkey", Columns: []string{"id"}},
+			"drafts":  {Name: "drafts_pkey", Columns: []string{"id"}},
+		},
+		MockFKeys: map[string][]db.ForeignKey{
+			"books": {{Table: "books", Name: "books_author_id_fkey", Column: "author_id", ForeignTable: "authors", ForeignColumn: "id"}},
+		},
+	}
+	s, cleanup := testState(t, d)
+	defer cleanup()
+	s.Config.TableRenderer = fieldsRenderer{}
+	s.Config.BlacklistTables = []string{"drafts"}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOutput(t, s.Config.OutFolder)
+	if len(files) != 2 {
+		t.Errorf("want authors and books generated, got: %v", files)
+	}
+	want := "package models\n\ntype Author struct {\n\tID int\n\tName string\n\t// has many books\n}\n"
+	if got := files[filepath.Join("authors", "authors_gen.go")]; got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+	want = "package models\n\ntype Book struct {\n\tID int\n\tAuthorID int\n\tTitle null.String\n}\n"
+	if got := files[filepath.Join("books", "books_gen.go")]; got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// testLogger records what it is given at each level.
+type testLogger struct {
+	mu    sync.Mutex
+	lines map[string][]string
+}
+
+func (l *testLogger) logf(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines[level] = append(l.lines[level], fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.logf("debug", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.logf("info", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.logf("warn", format, args...) }
+
+func TestRunLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := &testLogger{lines: map[string][]string{}}
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.Logger = logger
+	s.Config.Debug = true
+	s.Config.BlacklistTables = []string{"airports"}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if debug := logger.lines["debug"]; len(debug) != 1 || !json.Valid([]byte(debug[0])) {
+		t.Errorf("want the schema dumped as JSON, got: %q", debug)
+	}
+	info := strings.Join(logger.lines["info"], "\n")
+	for _, name := range []string{"pilots", "jets", "licenses", "hangars", "languages"} {
+		if !strings.Contains(info, "generating table "+name+" (_gen.go)") {
+			t.Errorf("want the progress of %s logged, got:\n%s", name, info)
+		}
+	}
+	if warn := strings.Join(logger.lines["warn"], "\n"); !strings.Contains(warn, "airports isn't being generated") {
+		t.Errorf("want the dropped foreign keys warned about, got:\n%s", warn)
+	}
+}
+
+func TestRunPackageDoc(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.PackageDoc = "holds the generated models."
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := "// Package models holds the generated models.\n"
+	count := 0
+	for name, contents := range readOutput(t, s.Config.OutFolder) {
+		n := strings.Count(contents, doc)
+		count += n
+		if n != 0 && !strings.Contains(contents, "DO NOT EDIT.\n\n"+doc+"package models\n") {
+			t.Errorf("package doc is not directly above the package clause in %s:\n%s", name, contents)
+		}
+	}
+	if count != 1 {
+		t.Errorf("want the package doc exactly once, got: %d", count)
+	}
+}
+
+func TestRunRenderers(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.Renderers = []NamedRenderer{{Suffix: ".gen.proto", Renderer: protoRenderer{}}}
+	s.Config.PackageDoc = "holds the generated models."
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOutput(t, s.Config.OutFolder)
+	for _, name := range []string{"pilots", "jets", "airports", "licenses", "hangars", "languages"} {
+		if !strings.Contains(files[filepath.Join(name, name+"_gen.go")], "// "+name+"\n") {
+			t.Errorf("want a go file for %s", name)
+		}
+		proto := files[filepath.Join(name, name+".gen.proto")]
+		if !strings.Contains(proto, "message "+name+" {}") {
+			t.Errorf("want a proto file for %s", name)
+		}
+		if strings.Contains(proto, "//") {
+			t.Errorf("want no package doc in the proto file for %s:\n%s", name, proto)
+		}
+	}
+	if len(files) != 12 {
+		t.Errorf("want two files per table, got: %d files", len(files))
+	}
+}
+
+func TestRunFileNamer(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.FileNamer = func(tableName, suffix string) string {
+		return tableName + strings.Replace(suffix, "_", ".", 1)
+	}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOutput(t, s.Config.OutFolder)
+	for _, name := range []string{"pilots", "jets", "airports", "licenses", "hangars", "languages"} {
+		if _, ok := files[name+".gen.go"]; !ok {
+			t.Errorf("want %s.gen.go in the output folder, got: %v", name, files)
+		}
+	}
+
+	s.Config.FileNamer = func(tableName, suffix string) string { return "../" + tableName + suffix }
+	if err := s.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "want a path within the output folder") {
+		t.Errorf("want a path outside the output folder to fail, got: %v", err)
+	}
+
+	s.Config.FileNamer = func(tableName, suffix string) string { return "models.go" }
+	if err := s.Run(context.Background()); err == nil || !strings.Contains(err.Error(), `file namer returned "models.go" for both`) {
+		t.Errorf("want two tables named into the same file to fail, got: %v", err)
+	}
+}
+
+// failRenderer fails rendering the table named fail.
+type failRenderer struct {
+	fail string
+}
+
+func (f failRenderer) Render(data *TemplateData, w io.Writer) error {
+	if data.Table.Name == f.fail {
+		return errors.New("boom")
+	}
+	_, err := io.WriteString(w, "package models\n")
+	return err
+}
+
+func TestRunTableTransform(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	data := &dataRenderer{data: map[string]*TemplateData{}}
+	s.Config.TableRenderer = data
+	s.Config.TableTransform = func(t db.Table) (db.Table, error) {
+		switch t.Name {
+		case "pilots":
+			t.GoName = "Aviator"
+			t.Columns = append(t.Columns, db.Column{Name: "display_name", TypeName: "string", Default: "''"})
+		case "jets":
+			t.IsJoinTable = true
+		case "licenses", "hangars":
+			t.Columns = t.Columns[:1]
+		}
+		return t, nil
+	}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	pilots, ok := data.data["pilots"]
+	if !ok {
+		t.Fatal("want pilots rendered")
+	}
+	if pilots.Table.GoName != "Aviator" {
+		t.Errorf("want pilots renamed, got: %s", pilots.Table.GoName)
+	}
+	if c := pilots.Table.Columns[len(pilots.Table.Columns)-1]; c.Name != "display_name" {
+		t.Errorf("want the synthetic column added, got: %s", c.Name)
+	} else if !c.OptionalOnInsert {
+		t.Error("want the synthetic column's OptionalOnInsert derived from its default")
+	}
+	if idx := data.data["hangars"].Table.Indexes; len(idx) != 0 {
+		t.Errorf("want the index over the dropped hangars.name gone, got: %#v", idx)
+	}
+	if _, ok := data.data["jets"]; ok {
+		t.Error("want jets skipped as a join table")
+	}
+	for _, r := range pilots.Table.ToManyRelationships {
+		if r.ForeignTable == "licenses" {
+			t.Error("want the relationship to the dropped licenses.pilot_id gone")
+		}
+	}
+
+	s.Config.TableTransform = func(t db.Table) (db.Table, error) {
+		if t.Name == "pilots" {
+			t.Columns = t.Columns[1:]
+		}
+		return t, nil
+	}
+	err := s.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "pilots: primary key column id left out") {
+		t.Errorf("want the transformed tables validated, got: %v", err)
+	}
+
+	s.Config.TableTransform = func(t db.Table) (db.Table, error) {
+		return t, errors.New("no plural for " + t.Name)
+	}
+	err = s.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unable to transform table") || !strings.Contains(err.Error(), "no plural for") {
+		t.Errorf("want the transform's error wrapped, got: %v", err)
+	}
+}
+
+func TestRunEnumTypeNames(t *testing.T) {
+	t.Parallel()
+
+	d := &drivers.MockDriver{
+		MockTables: map[string][]db.Column{
+			"posts": {{Name: "id", DBType: "integer"}, {Name: "status", DBType: "enum('draft','published')"}},
+			"notes": {{Name: "id", DBType: "integer"}, {Name: "status", DBType: "enum('open','done')"}},
+		},
+		MockPKeys: map[string]*db.PrimaryKey{
+			"posts": {Name: "posts_pkey", Columns: []string{"id"}},
+			"notes": {Name: "notes_pkey", Columns: []string{"id"}},
+		},
+	}
+	s, cleanup := testState(t, d)
+	defer cleanup()
+	data := &dataRenderer{data: map[string]*TemplateData{}}
+	s.Config.TableRenderer = data
+	s.Config.StructNames = map[string]string{"posts": "Article"}
+	s.Config.TableTransform = func(t db.Table) (db.Table, error) {
+		if t.Name == "notes" {
+			t.GoName = "Memo"
+		}
+		return t, nil
+	}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, want := range map[string]string{"posts": "ArticleStatus", "notes": "MemoStatus"} {
+		if got := data.data[table].Table.GetColumn("status").TypeName; got != want {
+			t.Errorf("%s: want: %s, got: %s", table, want, got)
+		}
+	}
+}
+
+func TestRunValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	d := &drivers.MockDriver{
+		MockTables: map[string][]db.Column{
+			"authors": {{Name: "id", DBType: "integer"}, {Name: "user_id", DBType: "integer"}, {Name: "userID", DBType: "integer"}},
+			"drafts":  {{Name: "body", DBType: "text"}},
+		},
+		MockPKeys: map[string]*db.PrimaryKey{
+			"authors": {Name: "authors_pkey", Columns: []string{"id"}},
+		},
+	}
+	s, cleanup := testState(t, d)
+	defer cleanup()
+	s.Config.StructTagCasing = TagCasingCamel
+
+	err := s.Run(context.Background())
+	if _, ok := errors.Cause(err).(*db.ValidationError); !ok {
+		t.Fatalf("want a *db.ValidationError, got: %v", err)
+	}
+	for _, want := range []string{"drafts: primary key missing", "authors: duplicate struct tag userID (user_id, userID)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("want %q in:\n%s", want, err)
+		}
+	}
+
+	s.Config.StructTagCasing = "kebab"
+	s.Config.BlacklistColumns = map[string][]string{"authors": {"id"}}
+	err = s.Run(context.Background())
+	for _, want := range []string{"unknown struct tag casing \"kebab\"", "drafts: primary key missing", "authors: primary key column id left out"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("want %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestRunConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var outputs []map[string]string
+	for _, concurrency := range []int{1, 4} {
+		s, cleanup := testState(t, &drivers.MockDriver{})
+		defer cleanup()
+		s.Config.Concurrency = concurrency
+		s.Config.PackageDoc = "holds the generated models."
+
+		if err := s.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, readOutput(t, s.Config.OutFolder))
+	}
+
+	if !reflect.DeepEqual(outputs[0], outputs[1]) {
+		t.Errorf("want the same output rendering concurrently, got:\n%v\n%v", outputs[0], outputs[1])
+	}
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.Concurrency = 4
+	s.Config.TableRenderer = failRenderer{fail: "jets"}
+
+	err := s.Run(context.Background())
+	if err == nil || err.Error() != "while rendering jets: unable to generate output: boom" {
+		t.Errorf("want the failing table's error, got: %v", err)
+	}
+}
+
+func TestRunSplitModelAndQueries(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.SplitModelAndQueries = true
+	s.Config.ModelRenderer = modelRenderer{}
+	s.Config.QueryRenderer = queryRenderer{}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOutput(t, s.Config.OutFolder)
+	for _, name := range []string{"pilots", "jets", "airports", "licenses", "hangars", "languages"} {
+		model, ok := files[filepath.Join(name, name+"_model_gen.go")]
+		if !ok || !strings.Contains(model, "type "+name+" struct{}") {
+			t.Errorf("want a model file for %s", name)
+		}
+		if strings.Contains(model, "func ") {
+			t.Errorf("want no query methods in the model file for %s:\n%s", name, model)
+		}
+		if !strings.Contains(files[filepath.Join(name, name+"_query_gen.go")], ") Insert() error") {
+			t.Errorf("want a query file for %s", name)
+		}
+	}
+	if len(files) != 12 {
+		t.Errorf("want two files per table, got: %d files", len(files))
+	}
+
+	s.Config.DriverName = "mock"
+	if _, err := New(s.Config); err != nil {
+		t.Error(err)
+	}
+	s.Config.ModelRenderer = nil
+	if _, err := New(s.Config); err == nil {
+		t.Error("want an error splitting without a ModelRenderer")
+	}
+}
+
+// importRenderer renders a model with an import, for the imports of the
+// tables to be merged in SingleFileMode.
+type importRenderer struct{}
+
+func (importRenderer) Render(data *TemplateData, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage models\n\nimport (\n\t\"database/sql\"\n\t\"time\"\n)\n\n// %s is a row of %s.\ntype %s struct {\n\tCreatedAt time.Time\n\tName      sql.NullString\n}\n", data.Table.GoName, data.Table.Name, data.Table.GoName)
+	return err
+}
+
+func (importRenderer) RenderTest(data *TemplateData, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "package models\n\nimport \"testing\"\n\nfunc Test%s(t *testing.T) {}\n", data.Table.GoName)
+	return err
+}
+
+func TestRunSingleFileMode(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.SingleFileMode = true
+	s.Config.TableRenderer = importRenderer{}
+	s.Config.TableTestRenderer = importRenderer{}
+	s.Config.Renderers = []NamedRenderer{{Suffix: ".proto", Renderer: protoRenderer{}}}
+	s.Config.PackageDoc = "Package models is generated."
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOutput(t, s.Config.OutFolder)
+	tables := []string{"pilots", "jets", "airports", "licenses", "hangars", "languages"}
+	if len(files) != len(tables)+2 {
+		t.Errorf("want one models file, one test file and the protos, got: %d files", len(files))
+	}
+
+	models := files[SingleFilename]
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, SingleFilename, models, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("want the combined file to parse: %s\n%s", err, models)
+	}
+	if len(f.Imports) != 2 {
+		t.Errorf("want the imports merged, got: %d", len(f.Imports))
+	}
+	if got := strings.Count(models, "package models"); got != 1 {
+		t.Errorf("want a single package clause, got: %d", got)
+	}
+	if !strings.HasPrefix(models, "// Code generated by sqlgen. DO NOT EDIT.\n\n// Package models is generated.\npackage models\n\nimport (\n\t\"database/sql\"\n\t\"time\"\n)\n") {
+		t.Errorf("want the header, doc and imports once:\n%s", models)
+	}
+	for _, name := range tables {
+		goName := db.GoName(strings.TrimSuffix(name, "s"))
+		if !strings.Contains(models, "// "+goName+" is a row of "+name+".\ntype "+goName+" struct {") {
+			t.Errorf("want the %s model in the combined file", name)
+		}
+		if !strings.Contains(files[SingleTestFilename], "func Test"+goName+"(t *testing.T) {}") {
+			t.Errorf("want the %s test in the combined test file", name)
+		}
+		if _, ok := files[filepath.Join(name, name+".proto")]; !ok {
+			t.Errorf("want the %s proto rendered on its own", name)
+		}
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("models", fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("want the combined file to compile: %s", err)
+	}
+}
+
+func TestRunCheckMode(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Config.CheckMode = true
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("want identical output to pass, got: %s", err)
+	}
+
+	stale := filepath.Join(s.Config.OutFolder, "jets", "jets_gen.go")
+	if err := os.Remove(stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(stale, []byte("package models\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.Run(context.Background())
+	outOfDate, ok := err.(*ErrOutOfDate)
+	if !ok {
+		t.Fatalf("want an *ErrOutOfDate, got: %v", err)
+	}
+	if len(outOfDate.Files) != 1 || outOfDate.Files[0] != stale {
+		t.Errorf("want only %s out of date, got: %v", stale, outOfDate.Files)
+	}
+	if b, _ := ioutil.ReadFile(stale); string(b) != "package models\n" {
+		t.Errorf("want the stale file left alone, got:\n%s", b)
+	}
+
+	if err := s.Run(context.Background()); err == nil {
+		t.Fatal("want the stale file still out of date")
+	}
+	s.Config.CheckMode = false
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.Config.CheckMode = true
+
+	dropped := filepath.Join(s.Config.OutFolder, "gliders", "gliders_gen.go")
+	if err := os.MkdirAll(filepath.Dir(dropped), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dropped, []byte("package models\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	handWritten := filepath.Join(s.Config.OutFolder, "helpers.go")
+	if err := ioutil.WriteFile(handWritten, []byte("package models\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(context.Background())
+	if outOfDate, ok := err.(*ErrOutOfDate); !ok || len(outOfDate.Files) != 1 || outOfDate.Files[0] != dropped {
+		t.Errorf("want only the file of the dropped table out of date, got: %v", err)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	modified := filepath.Join(s.Config.OutFolder, "jets", "jets_gen.go")
+	if err := ioutil.WriteFile(modified, []byte("package models\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(s.Config.OutFolder, "pilots", "pilots_gen.go")
+	if err := os.Remove(created); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	s.stdout = buf
+	s.Config.DryRun = true
+	s.Config.DryRunDiff = true
+	s.Config.Wipe = true
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"created   " + created + "\n",
+		"modified  " + modified + "\n--- " + modified + "\n+++ " + modified + "\n@@ -1,1 +1,5 @@\n",
+		"+// jets\n",
+		"unchanged " + filepath.Join(s.Config.OutFolder, "airports", "airports_gen.go") + "\n",
+		"1 created, 1 modified, 4 unchanged\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output:\n%s", want, out)
+		}
+	}
+
+	if b, _ := ioutil.ReadFile(modified); string(b) != "package models\n" {
+		t.Errorf("want the modified file left alone, got:\n%s", b)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("want no file created, got: %v", err)
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(s.Config.OutFolder, "jets", "jets_gen.go")
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Longer content than will be generated, for stale trailing bytes to
+	// show if the file isn't truncated, and read-only like the files older
+	// versions generated.
+	if err := ioutil.WriteFile(path, append(want, "// old trailing bytes\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0444); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("want regenerating to succeed, got: %s", err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Root can write to the read-only file regardless, so the mode is what
+	// shows it was replaced rather than written over.
+	if info.Mode().Perm()&0200 == 0 {
+		t.Errorf("want the generated file writable, got mode: %s", info.Mode())
+	}
+}
+
+func TestRunToStdout(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.ToStdout = true
+	s.Config.OutFolder = filepath.Join(s.Config.OutFolder, "models")
+
+	buf := &bytes.Buffer{}
+	s.stdout = buf
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, name := range []string{"pilots", "jets", "airports", "licenses", "hangars", "languages"} {
+		if want := "// file: " + name + "/" + name + "_gen.go\n// Code generated by sqlgen"; !strings.Contains(out, want) {
+			t.Errorf("want a separator and content for %s in:\n%s", name, out)
+		}
+		if !strings.Contains(out, "// "+name+"\n") {
+			t.Errorf("want the content of %s", name)
+		}
+	}
+
+	if _, err := os.Stat(s.Config.OutFolder); !os.IsNotExist(err) {
+		t.Errorf("want no output folder, got: %v", err)
+	}
+}
+
+func TestRunInstrumentationHook(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.InstrumentationHook = "metrics.Time"
+
+	renderer := &dataRenderer{data: map[string]*TemplateData{}}
+	s.Config.TableRenderer = renderer
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(renderer.data) == 0 {
+		t.Fatal("want tables rendered")
+	}
+	for name, data := range renderer.data {
+		if data.InstrumentationHook != "metrics.Time" {
+			t.Errorf("want the hook in the %s template data, got: %q", name, data.InstrumentationHook)
+		}
+	}
+}
+
+func TestRunConnFromContext(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.ConnFromContext = "models.ExecutorFromContext"
+
+	renderer := &dataRenderer{data: map[string]*TemplateData{}}
+	s.Config.TableRenderer = renderer
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(renderer.data) == 0 {
+		t.Fatal("want tables rendered")
+	}
+	for name, data := range renderer.data {
+		if data.ConnFromContext != "models.ExecutorFromContext" {
+			t.Errorf("want the hint in the %s template data, got: %q", name, data.ConnFromContext)
+		}
+	}
+}
+
+func TestRunMetadataOnly(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.MetadataOnly = true
+	s.Config.TableRenderer = nil
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readOutput(t, s.Config.OutFolder)
+	if len(files) != 1 {
+		t.Errorf("want only the metadata file, got: %d files", len(files))
+	}
+	if !strings.Contains(files[MetadataFilename], `Name: "pilots"`) {
+		t.Errorf("want pilots in the metadata file:\n%s", files[MetadataFilename])
+	}
+}
+
+func TestStreamSchema(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+
+	buf := &bytes.Buffer{}
+	if err := s.StreamSchema(context.Background(), buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var table db.Table
+		if err := json.Unmarshal(scanner.Bytes(), &table); err != nil {
+			t.Fatalf("line %d is not a table: %s", len(names)+1, err)
+		}
+		names = append(names, table.Name)
+	}
+
+	want := "pilots jets airports licenses hangars languages pilot_languages"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("want tables: %s\ngot: %s", want, got)
+	}
+}
+
+func TestListSchemas(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+
+	schemas, err := s.ListSchemas(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 1 || schemas[0] != "public" {
+		t.Errorf("want the public schema, got: %v", schemas)
+	}
+}
+
+func TestNewAsOfUnsupported(t *testing.T) {
+	t.Parallel()
+
+	for _, driverName := range []string{"mock", "mysql", "postgres"} {
+		_, err := New(&Config{
+			DriverName:    driverName,
+			TableRenderer: testRenderer{},
+			AsOf:          time.Date(2017, 3, 4, 0, 0, 0, 0, time.UTC),
+		})
+		if want := "the " + driverName + " driver does not support introspecting as of a time"; err == nil || err.Error() != want {
+			t.Errorf("want: %s, got: %v", want, err)
+		}
+	}
+}
+
+func TestNewPostgresVerifyFull(t *testing.T) {
+	t.Parallel()
+
+	for _, driverName := range []string{"postgres", "cockroach", "redshift"} {
+		_, err := New(&Config{
+			DriverName:    driverName,
+			TableRenderer: testRenderer{},
+			Postgres:      PostgresConfig{User: "user", Host: "localhost", DBName: "dbname", SSLMode: "verify-full"},
+		})
+		if err == nil || !strings.Contains(err.Error(), "sslrootcert") {
+			t.Errorf("%s) want an error asking for the root cert, got: %v", driverName, err)
+		}
+
+		_, err = New(&Config{
+			DriverName:    driverName,
+			TableRenderer: testRenderer{},
+			Postgres:      PostgresConfig{User: "user", Host: "localhost", DBName: "dbname", SSLMode: "verify-full", SSLRootCert: "/certs/ca.crt"},
+		})
+		if err != nil {
+			t.Errorf("%s) %s", driverName, err)
+		}
+	}
+}
+
+func TestNewMySQLCollation(t *testing.T) {
+	t.Parallel()
+
+	s, err := New(&Config{
+		DriverName:    "mysql",
+		TableRenderer: testRenderer{},
+		MySQL: MySQLConfig{
+			User:      "user",
+			Host:      "localhost",
+			DBName:    "dbname",
+			Collation: "utf8mb4_bin",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Collation != "utf8mb4_bin" {
+		t.Errorf("want the collation recorded on the state, got: %q", s.Collation)
+	}
+}
+
+func TestPackageDoc(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Doc  string
+		Want string
+	}{
+		{"", ""},
+		{"holds models.", "// Package models holds models.\n"},
+		{"Package models holds models.", "// Package models holds models.\n"},
+		{"holds models.\n\nMore detail.", "// Package models holds models.\n//\n// More detail.\n"},
+	}
+
+	for i, test := range tests {
+		if got := packageDoc("models", test.Doc); got != test.Want {
+			t.Errorf("%d) want: %q, got: %q", i, test.Want, got)
+		}
+	}
+}
+
 //import (
 //	"bufio"
 //	"bytes"
@@ -48,7 +1149,7 @@ package core
 //		t.Fatalf("Unable to create State using config: %s", err)
 //	}
 //
-//	if err = state.Run(); err != nil {
+//	if err = state.Run(context.Background()); err != nil {
 //		t.Errorf("Unable to execute State.Run: %s", err)
 //	}
 //
@@ -58,7 +1159,7 @@ package core
 //	cmd.Dir = state.Config.OutFolder
 //	cmd.Stderr = buf
 //
-//	if err = cmd.Run(); err != nil {
+//	if err = cmd.Run(context.Background()); err != nil {
 //		t.Errorf("go test cmd execution failed: %s", err)
 //		outputCompileErrors(buf, state.Config.OutFolder)
 //		fmt.Println()
@@ -138,3 +1239,117 @@ package core
 //		fh.Close()
 //	}
 //}
+
+func TestAutoTouch(t *testing.T) {
+	t.Parallel()
+
+	tables := []db.Table{
+		{Name: "users", Columns: []db.Column{{Name: "id"}, {Name: "updated_at"}, {Name: "seen_at"}}},
+		{Name: "posts", Columns: []db.Column{{Name: "updated_at", AutoUpdateTime: true}, {Name: "seen_at"}}},
+	}
+
+	s := &State{Config: &Config{}}
+	setAutoTouch(tables, s.autoTouchColumns())
+	if !tables[0].Columns[1].AutoTouch {
+		t.Error("want users.updated_at touched by default")
+	}
+	if tables[1].Columns[0].AutoTouch {
+		t.Error("want posts.updated_at left to the database's ON UPDATE")
+	}
+	if !tables[1].Columns[0].AutoUpdateTime {
+		t.Error("want posts.updated_at still bumped by the database")
+	}
+
+	s.Config.AutoTouchColumns = []string{"posts.seen_at"}
+	setAutoTouch(tables, s.autoTouchColumns())
+	if !tables[1].Columns[1].AutoTouch {
+		t.Error("want posts.seen_at touched")
+	}
+	if tables[0].Columns[2].AutoTouch {
+		t.Error("want users.seen_at left alone")
+	}
+
+	if got := (&State{Config: &Config{NoAutoTimestamps: true}}).autoTouchColumns(); len(got) != 0 {
+		t.Errorf("want no columns touched with NoAutoTimestamps, got: %v", got)
+	}
+}
+
+func TestDisplayColumns(t *testing.T) {
+	t.Parallel()
+
+	tables := []db.Table{
+		{Name: "users", Columns: []db.Column{{Name: "id"}, {Name: "email"}}, PKey: &db.PrimaryKey{Columns: []string{"id"}}},
+		{Name: "posts", Columns: []db.Column{{Name: "id"}, {Name: "title"}}, PKey: &db.PrimaryKey{Columns: []string{"id"}}},
+		{Name: "post_tags", Columns: []db.Column{{Name: "post_id"}, {Name: "tag"}}, PKey: &db.PrimaryKey{Columns: []string{"post_id", "tag"}}},
+	}
+
+	problems := &db.ValidationError{}
+	setDisplayColumns(tables, map[string]string{"users": "email"}, problems)
+	if err := problems.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"email", "id", "post_id"} {
+		if got := tables[i].DisplayColumn; got != want {
+			t.Errorf("%d) want: %s, got: %s", i, want, got)
+		}
+	}
+
+	setDisplayColumns(tables, map[string]string{"users": "name", "posts": "subject"}, problems)
+	err := problems.Err()
+	if want := "invalid schema:\n  posts: display column subject not found\n  users: display column name not found"; err == nil || err.Error() != want {
+		t.Errorf("want: %s\ngot:  %v", want, err)
+	}
+}
+
+func TestStructNames(t *testing.T) {
+	t.Parallel()
+
+	tables := []db.Table{
+		{Name: "user_accounts", GoName: "UserAccount"},
+		{Name: "posts", GoName: "Post"},
+	}
+
+	names := map[string]string{"user_accounts": "Account"}
+	if err := checkStructNames(names); err != nil {
+		t.Fatal(err)
+	}
+
+	setStructNames(tables, names)
+	if tables[0].GoName != "Account" {
+		t.Errorf("want user_accounts named Account, got: %s", tables[0].GoName)
+	}
+	if tables[1].GoName != "Post" {
+		t.Errorf("want posts left alone, got: %s", tables[1].GoName)
+	}
+
+	err := checkStructNames(map[string]string{"user_accounts": "account", "posts": "Blog Post"})
+	if err == nil {
+		t.Fatal("want unexported and invalid names to fail")
+	}
+	if want := "struct names must be exported identifiers (posts: Blog Post, user_accounts: account)"; err.Error() != want {
+		t.Errorf("want: %s\ngot:  %s", want, err)
+	}
+}
+
+func TestRunStructNameCollisions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Names map[string]string
+		Want  string
+	}{
+		{map[string]string{"jets": "Aircraft", "hangars": "Aircraft"}, "struct name Aircraft is also the struct name of"},
+		{map[string]string{"jets": "Pilot"}, "struct name Pilot is also the struct name of"},
+	}
+
+	for i, test := range tests {
+		s, cleanup := testState(t, &drivers.MockDriver{})
+		s.Config.StructNames = test.Names
+		err := s.Run(context.Background())
+		cleanup()
+
+		if err == nil || !strings.Contains(err.Error(), test.Want) {
+			t.Errorf("%d) want: %s, got: %v", i, test.Want, err)
+		}
+	}
+}
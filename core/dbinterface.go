@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"io"
+)
+
+// DBFilename is the file, relative to the output folder, that the DB
+// interface is conventionally generated into.
+const DBFilename = "db_gen.go"
+
+// DBRenderer is a SingletonRenderer that emits a DB interface, the subset of
+// *sql.DB that generated methods depend on, along with a package-level setter.
+// *sql.Tx satisfies the interface too.
+type DBRenderer struct {
+	PkgName string
+}
+
+// RenderSingleton writes the DB interface to w.
+func (d *DBRenderer) RenderSingleton(data *TemplateData, w io.Writer) error {
+	_, err := fmt.Fprintf(w, `// Code generated by sqlgen. DO NOT EDIT.
+
+package %s
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DB is the subset of *sql.DB that generated code runs queries against.
+// *sql.Tx satisfies it as well.
+type DB interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+var currentDB DB
+
+// SetDB sets the DB that generated code runs queries against.
+func SetDB(db DB) {
+	currentDB = db
+}
+
+// GetDB returns the DB set by SetDB.
+func GetDB() DB {
+	return currentDB
+}
+`, d.PkgName)
+
+	return err
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db/drivers"
+)
+
+func TestRunDBRenderer(t *testing.T) {
+	t.Parallel()
+
+	s, cleanup := testState(t, &drivers.MockDriver{})
+	defer cleanup()
+	s.Config.SingletonRenderers = []NamedSingletonRenderer{
+		{Filename: DBFilename, Renderer: &DBRenderer{PkgName: "models"}},
+	}
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	src, ok := readOutput(t, s.Config.OutFolder)[DBFilename]
+	if !ok {
+		t.Fatalf("want %s to be generated", DBFilename)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), DBFilename, src, 0)
+	if err != nil {
+		t.Fatalf("not valid go: %s\n%s", err, src)
+	}
+
+	var methods []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != "DB" {
+			return true
+		}
+		for _, m := range spec.Type.(*ast.InterfaceType).Methods.List {
+			methods = append(methods, m.Names[0].Name)
+		}
+		return false
+	})
+	sort.Strings(methods)
+
+	want := "Exec ExecContext Query QueryContext QueryRow QueryRowContext"
+	if got := strings.Join(methods, " "); got != want {
+		t.Errorf("want methods: %s\ngot: %s", want, got)
+	}
+	if file.Scope.Lookup("SetDB") == nil {
+		t.Error("want a SetDB setter")
+	}
+}
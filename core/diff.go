@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is how many unchanged lines surround each hunk of a diff.
+const diffContext = 3
+
+// maxDiffCells bounds the table unifiedDiff finds the longest common lines
+// with. Bigger changes are shown as every middle line removed and added.
+const maxDiffCells = 4 << 20
+
+// diffLine is a line of a diff: ' ' kept, '-' removed or '+' added.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns the diff -u of the old and new contents of the file
+// at path, or "" if they are the same.
+func unifiedDiff(path string, old, new []byte) string {
+	lines := diffLines(splitLines(old), splitLines(new))
+
+	var changes []int
+	for i, l := range lines {
+		if l.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	// aLine and bLine are the old and new line numbers before each line.
+	aLine := make([]int, len(lines)+1)
+	bLine := make([]int, len(lines)+1)
+	for i, l := range lines {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if l.kind != '+' {
+			aLine[i+1]++
+		}
+		if l.kind != '-' {
+			bLine[i+1]++
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", path, path)
+	for i := 0; i < len(changes); {
+		start := changes[i] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext {
+			j++
+		}
+		end := changes[j] + diffContext + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		aStart, aCount := aLine[start], aLine[end]-aLine[start]
+		bStart, bCount := bLine[start], bLine[end]-bLine[start]
+		if aCount != 0 {
+			aStart++
+		}
+		if bCount != 0 {
+			bStart++
+		}
+		fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, l := range lines[start:end] {
+			buf.WriteByte(l.kind)
+			buf.WriteString(l.text)
+			buf.WriteByte('\n')
+		}
+
+		i = j + 1
+	}
+
+	return buf.String()
+}
+
+// splitLines splits b into its lines, without their newlines.
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+// diffLines returns the lines of a and b as kept, removed and added, keeping
+// the longest common subsequence of lines.
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix []diffLine
+	for len(a) != 0 && len(b) != 0 && a[0] == b[0] {
+		prefix = append(prefix, diffLine{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) != 0 && len(b) != 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffLine{{' ', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	lines := prefix
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			lines = append(lines, diffLine{'-', l})
+		}
+		for _, l := range b {
+			lines = append(lines, diffLine{'+', l})
+		}
+		return append(lines, suffix...)
+	}
+
+	// lcs[i][j] is the length of the longest common lines of a[i:] and b[j:].
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	return append(lines, suffix...)
+}
@@ -0,0 +1,40 @@
+package core
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	new := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nm\nn\n"
+
+	want := `--- f.go
++++ f.go
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,5 +9,5 @@
+ i
+ j
+ k
+-l
+ m
++n
+`
+	if got := unifiedDiff("f.go", []byte(old), []byte(new)); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+
+	if got := unifiedDiff("f.go", []byte(old), []byte(old)); got != "" {
+		t.Errorf("want no diff of identical files, got:\n%s", got)
+	}
+
+	want = "--- f.go\n+++ f.go\n@@ -0,0 +1,2 @@\n+x\n+y\n"
+	if got := unifiedDiff("f.go", nil, []byte("x\ny\n")); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
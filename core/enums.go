@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/pkg/errors"
+)
+
+// EnumsFilename is the file, relative to the output folder, that the enum
+// types are conventionally generated into.
+const EnumsFilename = "enums_gen.go"
+
+// EnumsRenderer is a SingletonRenderer that declares the string-backed type
+// of every enum in the schema once (see db.Enums), with a constant per
+// value:
+//
+//	type Mood string
+//
+//	const (
+//		MoodHappy Mood = "happy"
+//		MoodSad   Mood = "sad"
+//	)
+type EnumsRenderer struct{}
+
+// EnumConstant is the Go constant for one value of an enum.
+type EnumConstant struct {
+	// Name is the constant's identifier, e.g. PostStatusInProgress.
+	Name string
+	// Value is the enum value, e.g. "in progress".
+	Value string
+}
+
+// enumConstants names the constants of the values of the enum typeName, in
+// order. Values whose names would collide, like "a-b" and "a b", have the
+// later ones numbered: PostStatusAB, PostStatusAB2.
+func enumConstants(typeName string, values []string) []EnumConstant {
+	seen := make(map[string]bool)
+	consts := make([]EnumConstant, len(values))
+	for i, v := range values {
+		base := enumConstName(typeName, v, i+1)
+		name := base
+		for n := 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		seen[name] = true
+		consts[i] = EnumConstant{Name: name, Value: v}
+	}
+
+	return consts
+}
+
+// enumConstName is the name of the constant for value, the nth of the enum
+// typeName, e.g. MoodNotSoGood for "not so good", MoodEmpty for "", or
+// MoodValue3 for a value with no letters or digits, such as "?".
+func enumConstName(typeName, value string, n int) string {
+	if value == "" {
+		return typeName + "Empty"
+	}
+
+	words := strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, value), "_")
+	if words == "" {
+		return typeName + "Value" + strconv.Itoa(n)
+	}
+
+	name := db.GoName(words)
+	if unicode.IsDigit([]rune(words)[0]) {
+		// A value like 2fa isn't prefixed, following typeName.
+		name = strings.TrimPrefix(name, "X")
+	}
+	return typeName + name
+}
+
+// RenderSingleton writes the enum types of data.Tables to w.
+func (EnumsRenderer) RenderSingleton(data *TemplateData, w io.Writer) error {
+	enums, err := db.Enums(data.Tables)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage %s\n", data.PkgName)
+	for _, e := range enums {
+		fmt.Fprintf(buf, "\n// %s is an enum of the database.\ntype %s string\n\n", e.TypeName, e.TypeName)
+		fmt.Fprintf(buf, "// The values of %s.\nconst (\n", e.TypeName)
+		for _, c := range enumConstants(e.TypeName, e.Values) {
+			fmt.Fprintf(buf, "%s %s = %q\n", c.Name, e.TypeName, c.Value)
+		}
+		buf.WriteString(")\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "unable to format enums")
+	}
+
+	_, err = w.Write(src)
+	return err
+}
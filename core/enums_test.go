@@ -0,0 +1,102 @@
+package core
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestEnumsRenderer(t *testing.T) {
+	t.Parallel()
+
+	mood := db.Column{Name: "mood", TypeName: "Mood", DBType: "enum.mood('happy','not so good')", EnumValues: []string{"happy", "not so good"}}
+	tables := []db.Table{
+		{Name: "users", Columns: []db.Column{mood}},
+		{Name: "posts", Columns: []db.Column{mood}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (EnumsRenderer{}).RenderSingleton(&TemplateData{Tables: tables, PkgName: "models"}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), EnumsFilename, buf.Bytes(), 0); err != nil {
+		t.Fatalf("enums are not valid go: %s\n%s", err, buf)
+	}
+
+	out := buf.String()
+	if n := strings.Count(out, "type Mood string"); n != 1 {
+		t.Errorf("want Mood declared once, got %d times:\n%s", n, out)
+	}
+	for _, want := range []string{
+		`MoodHappy     Mood = "happy"`,
+		`MoodNotSoGood Mood = "not so good"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestEnumConstName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Value string
+		Want  string
+	}{
+		{"happy", "MoodHappy"},
+		{"xray", "MoodXray"},
+		{"in-progress", "MoodInProgress"},
+		{"2fa", "Mood2fa"},
+		{"", "MoodEmpty"},
+		{"_x", "MoodX"},
+		{"-2fa", "Mood2fa"},
+		{"?", "MoodValue7"},
+		{"-", "MoodValue7"},
+	}
+
+	for i, test := range tests {
+		if got := enumConstName("Mood", test.Value, 7); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestEnumConstants(t *testing.T) {
+	t.Parallel()
+
+	data := &TemplateData{Table: db.Table{Name: "posts", GoName: "Post"}}
+	values := []string{"in progress", "n/a", "done", "in-progress", "2fa", "?", "-", "value 6"}
+
+	want := []EnumConstant{
+		{"PostStatusInProgress", "in progress"},
+		{"PostStatusNA", "n/a"},
+		{"PostStatusDone", "done"},
+		{"PostStatusInProgress2", "in-progress"},
+		{"PostStatus2fa", "2fa"},
+		{"PostStatusValue6", "?"},
+		{"PostStatusValue7", "-"},
+		{"PostStatusValue62", "value 6"},
+	}
+
+	for _, c := range []db.Column{
+		{Name: "status", TypeName: "PostStatus", EnumValues: values},
+		{Name: "status", TypeName: "null.String", EnumValues: values, Nullable: true},
+	} {
+		got := data.EnumConstants(c)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("want: %#v\ngot:  %#v", want, got)
+		}
+		for _, e := range got {
+			if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+				t.Errorf("want an exported identifier, got: %q", e.Name)
+			}
+		}
+	}
+}
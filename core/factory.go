@@ -0,0 +1,85 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// FactoryFilename is the file, relative to the output folder, that the
+// column factory specs are conventionally generated into.
+const FactoryFilename = "factories_gen.go"
+
+// FactoryRenderer is a SingletonRenderer that emits the db.FactorySpec of
+// every column, for test data factories to produce valid random rows. Add
+// it to Config.SingletonRenderers to generate it.
+type FactoryRenderer struct{}
+
+// RenderSingleton writes the factory specs for data.Tables to w.
+func (FactoryRenderer) RenderSingleton(data *TemplateData, w io.Writer) error {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage %s\n\n", data.PkgName)
+	buf.WriteString(`// FactorySpec hints at how to produce a valid random value for a column.
+type FactorySpec struct {
+	// Type is the Go type as generated code spells it, e.g. null.String.
+	Type       string
+	MaxLength  int
+	EnumValues []string
+	Nullable   bool
+	Unique     bool
+	// Min and Max bound the value when its column has a range check, not
+	// included when MinExclusive or MaxExclusive, e.g. Min 0 for age > 0.
+	Min, Max                   string
+	MinExclusive, MaxExclusive bool
+	// In, when set, are the only values the column's check allows.
+	In []string
+}
+
+`)
+
+	buf.WriteString("// Factories holds the FactorySpec of every column, by table and column name.\nvar Factories = map[string]map[string]FactorySpec{\n")
+	for _, t := range data.Tables {
+		if t.IsJoinTable {
+			continue
+		}
+
+		fmt.Fprintf(buf, "%q: {\n", t.Name)
+		for _, c := range t.Columns {
+			spec := c.FactorySpec()
+			fmt.Fprintf(buf, "%q: {Type: %q", c.Name, c.GoTypeExpr())
+			if spec.MaxLength != 0 {
+				fmt.Fprintf(buf, ", MaxLength: %d", spec.MaxLength)
+			}
+			if len(spec.EnumValues) != 0 {
+				fmt.Fprintf(buf, ", EnumValues: %#v", spec.EnumValues)
+			}
+			fmt.Fprintf(buf, ", Nullable: %t, Unique: %t", spec.Nullable, spec.Unique)
+			if check := spec.Check; check != nil {
+				if len(check.Min) != 0 {
+					fmt.Fprintf(buf, ", Min: %q, MinExclusive: %t", check.Min, check.MinExclusive)
+				}
+				if len(check.Max) != 0 {
+					fmt.Fprintf(buf, ", Max: %q, MaxExclusive: %t", check.Max, check.MaxExclusive)
+				}
+				if len(check.In) != 0 {
+					fmt.Fprintf(buf, ", In: %#v", check.In)
+				}
+			}
+			buf.WriteString("},\n")
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "unable to format factory specs")
+	}
+
+	_, err = w.Write(src)
+	return err
+}
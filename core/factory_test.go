@@ -0,0 +1,58 @@
+package core
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestFactoryRenderer(t *testing.T) {
+	t.Parallel()
+
+	tables := []db.Table{
+		{
+			Name: "posts",
+			Columns: []db.Column{
+				{Name: "id", TypeName: "int", Unique: true},
+				{Name: "code", TypeName: "string", MaxLength: 10},
+				{Name: "status", TypeName: "string", EnumValues: []string{"draft", "published"}},
+				{Name: "note", PkgName: "gopkg.in/nullbio/null.v6", TypeName: "String", Nullable: true},
+				{Name: "score", TypeName: "int", Check: &db.ColumnCheck{Min: "0", Max: "100"}},
+				{Name: "age", TypeName: "int", Check: &db.ColumnCheck{Min: "0", MinExclusive: true}},
+				{Name: "size", TypeName: "string", Check: &db.ColumnCheck{In: []string{"S", "M", "L"}}},
+			},
+		},
+		{Name: "post_tags", IsJoinTable: true},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (FactoryRenderer{}).RenderSingleton(&TemplateData{Tables: tables, PkgName: "models"}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), FactoryFilename, buf.Bytes(), 0); err != nil {
+		t.Fatalf("factories are not valid go: %s\n%s", err, buf)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"package models",
+		`"code":   {Type: "string", MaxLength: 10, Nullable: false, Unique: false},`,
+		`"status": {Type: "string", EnumValues: []string{"draft", "published"}, Nullable: false, Unique: false},`,
+		`"note":   {Type: "null.String", Nullable: true, Unique: false},`,
+		`"score":  {Type: "int", Nullable: false, Unique: false, Min: "0", MinExclusive: false, Max: "100", MaxExclusive: false},`,
+		`"age":    {Type: "int", Nullable: false, Unique: false, Min: "0", MinExclusive: true},`,
+		`"size":   {Type: "string", Nullable: false, Unique: false, In: []string{"S", "M", "L"}},`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "post_tags") {
+		t.Errorf("want no factories for join tables:\n%s", out)
+	}
+}
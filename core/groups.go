@@ -0,0 +1,82 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+// ColumnGroup is a set of columns generated as an embedded struct shared
+// between tables, e.g. Timestamps for created_at, updated_at and deleted_at.
+type ColumnGroup struct {
+	Name string
+	// Columns are the table's columns in the group, in the group's order.
+	Columns []db.Column
+}
+
+// columnGroups returns the groups that table has every column of, sorted by
+// name. A column can only be embedded once, so a group overlapping one
+// before it is left out.
+func columnGroups(table db.Table, groups map[string][]string) []ColumnGroup {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var matched []ColumnGroup
+	embedded := map[string]bool{}
+	for _, name := range names {
+		group := ColumnGroup{Name: name}
+		for _, column := range groups[name] {
+			c, ok := findColumn(table, column)
+			if !ok || embedded[column] {
+				group.Columns = nil
+				break
+			}
+			group.Columns = append(group.Columns, c)
+		}
+		if len(group.Columns) == 0 {
+			continue
+		}
+
+		for _, c := range group.Columns {
+			embedded[c.Name] = true
+		}
+		matched = append(matched, group)
+	}
+
+	return matched
+}
+
+// findColumn looks up a column by name, unlike Table.GetColumn without
+// panicking when there is none.
+func findColumn(table db.Table, name string) (db.Column, bool) {
+	for _, c := range table.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+
+	return db.Column{}, false
+}
+
+// InlineColumns returns the columns of Table that aren't in one of its
+// ColumnGroups, in order.
+func (t *TemplateData) InlineColumns() []db.Column {
+	embedded := map[string]bool{}
+	for _, g := range t.ColumnGroups {
+		for _, c := range g.Columns {
+			embedded[c.Name] = true
+		}
+	}
+
+	var columns []db.Column
+	for _, c := range t.Table.Columns {
+		if !embedded[c.Name] {
+			columns = append(columns, c)
+		}
+	}
+
+	return columns
+}
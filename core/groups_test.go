@@ -0,0 +1,53 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestColumnGroups(t *testing.T) {
+	t.Parallel()
+
+	groups := map[string][]string{
+		"Timestamps": {"created_at", "updated_at", "deleted_at"},
+		"Audit":      {"created_by", "updated_by"},
+		"Times":      {"created_at", "updated_at"},
+	}
+
+	posts := db.Table{
+		Name: "posts",
+		Columns: []db.Column{
+			{Name: "id"},
+			{Name: "title"},
+			{Name: "created_at"},
+			{Name: "updated_at"},
+			{Name: "deleted_at"},
+		},
+	}
+
+	data := &TemplateData{Table: posts, ColumnGroups: columnGroups(posts, groups)}
+	if len(data.ColumnGroups) != 1 || data.ColumnGroups[0].Name != "Times" {
+		t.Fatalf("want only the first of the overlapping groups, got: %#v", data.ColumnGroups)
+	}
+
+	// Without the overlapping group, the three timestamps are embedded.
+	delete(groups, "Times")
+	data = &TemplateData{Table: posts, ColumnGroups: columnGroups(posts, groups)}
+	if len(data.ColumnGroups) != 1 || data.ColumnGroups[0].Name != "Timestamps" {
+		t.Fatalf("want the Timestamps group, got: %#v", data.ColumnGroups)
+	}
+	if got := db.ColumnNames(data.ColumnGroups[0].Columns); !reflect.DeepEqual(got, []string{"created_at", "updated_at", "deleted_at"}) {
+		t.Errorf("want the group's columns in order, got: %v", got)
+	}
+	if got := db.ColumnNames(data.InlineColumns()); !reflect.DeepEqual(got, []string{"id", "title"}) {
+		t.Errorf("want id and title inlined, got: %v", got)
+	}
+
+	// A table missing one of the columns inlines them all.
+	drafts := db.Table{Name: "drafts", Columns: posts.Columns[:4]}
+	if got := columnGroups(drafts, groups); len(got) != 0 {
+		t.Errorf("want no groups for drafts, got: %#v", got)
+	}
+}
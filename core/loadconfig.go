@@ -0,0 +1,110 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfig reads a Config from a YAML (.yaml, .yml) or TOML (.toml) file.
+// Keys are the Config field names, matched case insensitively, with the
+// driver connections in nested sections. The keys of map fields, such as
+// StructNames or ShardMerge, are kept exactly as written:
+//
+//	driverName: postgres
+//	outFolder: models
+//	blacklistTables: [schema_migrations]
+//	postgres:
+//	  dbName: app
+//	  pass: ${DB_PASSWORD}
+//
+// Keys that aren't fields are an error. ${VAR} in string values is replaced
+// by the environment variable, and $${VAR} is a literal ${VAR}; other $s,
+// like the one in pa$word, are kept. Renderers can't be set from a file.
+func LoadConfig(path string) (*Config, error) {
+	var configType string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		configType = "yaml"
+	case ".toml":
+		configType = "toml"
+	default:
+		return nil, errors.Errorf("unable to load config %s: want a .yaml, .yml or .toml file", path)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load config %s", path)
+	}
+
+	// The file is read into a map rather than through viper, which lowercases
+	// keys and splits them on dots, so map fields like ShardMerge keep keys
+	// such as `Orders_\D+` or `orders_.*` as written.
+	raw := map[string]interface{}{}
+	if configType == "yaml" {
+		err = yaml.Unmarshal(b, &raw)
+	} else {
+		err = toml.Unmarshal(b, &raw)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load config %s", path)
+	}
+
+	cfg := &Config{}
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:  mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused: true,
+		Result:      cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(raw); err != nil {
+		return nil, errors.Wrapf(err, "unable to load config %s", path)
+	}
+
+	expandEnv(reflect.ValueOf(cfg).Elem())
+	return cfg, nil
+}
+
+// envVar matches ${VAR}, or $${VAR} to be left as ${VAR}.
+var envVar = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces environment variables in the strings of v, recursing
+// into structs, slices and maps.
+func expandEnv(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(envVar.ReplaceAllStringFunc(v.String(), func(m string) string {
+				if strings.HasPrefix(m, "$$") {
+					return m[1:]
+				}
+				return os.Getenv(m[2 : len(m)-1])
+			}))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			expandEnv(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			expandEnv(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			expandEnv(elem)
+			v.SetMapIndex(k, elem)
+		}
+	}
+}
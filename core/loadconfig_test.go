@@ -0,0 +1,120 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, contents string) string {
+	dir, err := ioutil.TempDir("", "sqlgen_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	os.Setenv("SQLGEN_TEST_LOAD_CONFIG_PASS", "hunter2")
+
+	yamlPath := writeConfig(t, "sqlgen.yaml", `
+driverName: postgres
+schema: app
+outFolder: models
+whitelistTables: [users, posts]
+wipe: true
+noTests: true
+structNames:
+  people: Person
+shardMerge:
+  'Orders_\D+': orders
+  'events_.*': events
+embedColumnGroups:
+  Timestamps: [created_at, updated_at]
+postgres:
+  dbName: app
+  port: 5433
+  user: pa$word$${SQLGEN_TEST_LOAD_CONFIG_PASS}
+  pass: ${SQLGEN_TEST_LOAD_CONFIG_PASS}
+`)
+	defer os.RemoveAll(filepath.Dir(yamlPath))
+
+	tomlPath := writeConfig(t, "sqlgen.toml", `
+driverName = "postgres"
+schema = "app"
+outFolder = "models"
+whitelistTables = ["users", "posts"]
+wipe = true
+noTests = true
+
+[structNames]
+people = "Person"
+
+[shardMerge]
+'Orders_\D+' = "orders"
+'events_.*' = "events"
+
+[embedColumnGroups]
+Timestamps = ["created_at", "updated_at"]
+
+[postgres]
+dbName = "app"
+port = 5433
+user = "pa$word$${SQLGEN_TEST_LOAD_CONFIG_PASS}"
+pass = "${SQLGEN_TEST_LOAD_CONFIG_PASS}"
+`)
+	defer os.RemoveAll(filepath.Dir(tomlPath))
+
+	want := &Config{
+		DriverName:        "postgres",
+		Schema:            "app",
+		OutFolder:         "models",
+		WhitelistTables:   []string{"users", "posts"},
+		Wipe:              true,
+		NoTests:           true,
+		StructNames:       map[string]string{"people": "Person"},
+		ShardMerge:        map[string]string{`Orders_\D+`: "orders", `events_.*`: "events"},
+		EmbedColumnGroups: map[string][]string{"Timestamps": {"created_at", "updated_at"}},
+		Postgres:          PostgresConfig{DBName: "app", Port: 5433, User: "pa$word${SQLGEN_TEST_LOAD_CONFIG_PASS}", Pass: "hunter2"},
+	}
+
+	for _, path := range []string{yamlPath, tomlPath} {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Errorf("%s) %s", filepath.Base(path), err)
+			continue
+		}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("%s) want:\n%#v\ngot:\n%#v", filepath.Base(path), want, cfg)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	unknown := writeConfig(t, "sqlgen.yml", "outFolder: models\npostgres:\n  database: app\n")
+	defer os.RemoveAll(filepath.Dir(unknown))
+
+	_, err := LoadConfig(unknown)
+	if err == nil || !strings.Contains(err.Error(), "database") {
+		t.Errorf("want an error naming the unknown key, got: %v", err)
+	}
+
+	json := writeConfig(t, "sqlgen.json", "{}")
+	defer os.RemoveAll(filepath.Dir(json))
+
+	if _, err := LoadConfig(json); err == nil {
+		t.Error("want an error for a .json file")
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// MetadataFilename is the file, relative to the output folder, that the
+// schema metadata is generated into.
+const MetadataFilename = "metadata_gen.go"
+
+// MetadataRenderer is a SingletonRenderer that emits a compiled-in
+// description of the schema: every table with its columns and primary key.
+type MetadataRenderer struct {
+	PkgName string
+}
+
+// RenderSingleton writes the schema metadata for data.Tables to w.
+func (m *MetadataRenderer) RenderSingleton(data *TemplateData, w io.Writer) error {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintf(buf, "// Code generated by sqlgen. DO NOT EDIT.\n\npackage %s\n\n", m.PkgName)
+	buf.WriteString(`// ColumnMetadata describes a database column.
+type ColumnMetadata struct {
+	Name     string
+	DBType   string
+	Nullable bool
+	Unique   bool
+}
+
+// TableMetadata describes a database table.
+type TableMetadata struct {
+	Name        string
+	Columns     []ColumnMetadata
+	PrimaryKey  []string
+	IsJoinTable bool
+}
+
+`)
+
+	buf.WriteString("// Tables describes every table in the schema.\nvar Tables = []TableMetadata{\n")
+	for _, t := range data.Tables {
+		fmt.Fprintf(buf, "{\nName: %q,\nColumns: []ColumnMetadata{\n", t.Name)
+		for _, c := range t.Columns {
+			fmt.Fprintf(buf, "{Name: %q, DBType: %q, Nullable: %t, Unique: %t},\n", c.Name, c.DBType, c.Nullable, c.Unique)
+		}
+		buf.WriteString("},\n")
+		if t.PKey != nil {
+			fmt.Fprintf(buf, "PrimaryKey: %#v,\n", t.PKey.Columns)
+		}
+		fmt.Fprintf(buf, "IsJoinTable: %t,\n},\n", t.IsJoinTable)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "unable to format schema metadata")
+	}
+
+	_, err = w.Write(src)
+	return err
+}
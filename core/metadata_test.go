@@ -0,0 +1,48 @@
+package core
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestMetadataRenderer(t *testing.T) {
+	t.Parallel()
+
+	tables := []db.Table{
+		{
+			Name: "pilots",
+			Columns: []db.Column{
+				{Name: "id", DBType: "integer"},
+				{Name: "name", DBType: "character", Nullable: true},
+			},
+			PKey: &db.PrimaryKey{Columns: []string{"id"}},
+		},
+		{Name: "pilot_languages", IsJoinTable: true},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := (&MetadataRenderer{PkgName: "models"}).RenderSingleton(&TemplateData{Tables: tables}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), MetadataFilename, buf.Bytes(), 0); err != nil {
+		t.Fatalf("metadata is not valid go: %s\n%s", err, buf)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"package models",
+		`{Name: "name", DBType: "character", Nullable: true, Unique: false}`,
+		`PrimaryKey:  []string{"id"}`,
+		`Name:        "pilot_languages"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output:\n%s", want, out)
+		}
+	}
+}
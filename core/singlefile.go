@@ -0,0 +1,206 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SingleFilename is the file, relative to the output folder, SingleFileMode
+// renders the Go files of every table into, and SingleTestFilename the one
+// their tests go into.
+const (
+	SingleFilename     = "models_gen.go"
+	SingleTestFilename = "models_test_gen.go"
+)
+
+// renderSingleFiles renders the Go files of every table into SingleFilename
+// and SingleTestFilename, one after the other, merging their imports under
+// a single package clause. Files of other kinds, e.g. ".proto", are still
+// rendered per table.
+func (s *State) renderSingleFiles(ctx context.Context) error {
+	var doc string
+	var models, tests []tableJob
+	for _, job := range s.tableJobs() {
+		if len(job.doc) != 0 {
+			doc = job.doc
+		}
+
+		switch {
+		case strings.HasSuffix(job.suffix, "_test.go") || strings.HasSuffix(job.suffix, "_test_gen.go"):
+			tests = append(tests, job)
+		case strings.HasSuffix(job.suffix, ".go"):
+			models = append(models, job)
+		default:
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := s.renderTable(job); err != nil {
+				return errors.Wrapf(err, "while rendering %v", job.table.Name)
+			}
+		}
+	}
+
+	if err := s.renderSingleFile(ctx, SingleFilename, doc, models); err != nil {
+		return err
+	}
+	return s.renderSingleFile(ctx, SingleTestFilename, "", tests)
+}
+
+// renderSingleFile renders jobs into the file filename, with doc above its
+// package clause. Nothing is written if there are no jobs.
+func (s *State) renderSingleFile(ctx context.Context, filename, doc string, jobs []tableJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(jobs))
+	srcs := make([][]byte, len(jobs))
+	for i, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.logger().Infof("generating table %s (%s)", job.table.Name, job.suffix)
+
+		buf := &bytes.Buffer{}
+		if err := job.render(s.templateData(job.table), buf); err != nil {
+			return errors.Wrapf(err, "while rendering %v", job.table.Name)
+		}
+		names[i], srcs[i] = job.table.Name+job.suffix, buf.Bytes()
+	}
+
+	src, err := mergeGoFiles(names, srcs)
+	if err != nil {
+		return errors.Wrapf(err, "unable to generate %s", filename)
+	}
+
+	w, err := s.createFile(filename)
+	if err != nil {
+		return errors.Wrapf(err, "unable to generate %s", filename)
+	}
+	defer w.Close()
+
+	if len(doc) != 0 {
+		src = insertPackageDoc(src, doc)
+	}
+	_, err = w.Write(src)
+	return errors.Wrapf(err, "unable to generate %s", filename)
+}
+
+// mergeGoFiles combines the Go source files srcs, named names, into one:
+// the distinct imports of them all in a single block, followed by their
+// declarations, with their doc comments, in order. The files must be of the
+// same package. Comments outside declarations, other than those above the
+// package clause of the first file, are dropped.
+func mergeGoFiles(names []string, srcs [][]byte) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	var pkgName string
+	var header []byte
+	var imports []string
+	seen := map[string]bool{}
+	decls := &bytes.Buffer{}
+
+	for i, src := range srcs {
+		f, err := parser.ParseFile(fset, names[i], src, parser.ParseComments)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s", names[i])
+		}
+
+		if i == 0 {
+			pkgName = f.Name.Name
+			header = src[:offset(fset, f.Package)]
+		} else if f.Name.Name != pkgName {
+			return nil, errors.Errorf("%s is in package %s, want %s", names[i], f.Name.Name, pkgName)
+		}
+
+		for _, spec := range f.Imports {
+			imp := spec.Path.Value
+			if spec.Name != nil {
+				imp = spec.Name.Name + " " + imp
+			}
+			if !seen[imp] {
+				seen[imp] = true
+				imports = append(imports, imp)
+			}
+		}
+
+		for _, decl := range f.Decls {
+			start := decl.Pos()
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok == token.IMPORT {
+					continue
+				}
+				if d.Doc != nil {
+					start = d.Doc.Pos()
+				}
+			case *ast.FuncDecl:
+				if d.Doc != nil {
+					start = d.Doc.Pos()
+				}
+			}
+
+			decls.WriteString("\n")
+			decls.Write(src[offset(fset, start):offset(fset, decl.End())])
+			decls.WriteString("\n")
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.Write(header)
+	fmt.Fprintf(buf, "package %s\n", pkgName)
+	if len(imports) != 0 {
+		buf.WriteString("\nimport (\n")
+		for _, imp := range sortImports(imports) {
+			fmt.Fprintf(buf, "\t%s\n", imp)
+		}
+		buf.WriteString(")\n")
+	}
+	buf.Write(decls.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// offset is the byte offset of pos in its file.
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
+
+// sortImports orders imports, each an optional name and a quoted path, as
+// gofmt'd Go does: the standard library's, a blank line, then the others,
+// each sorted by path.
+func sortImports(imports []string) []string {
+	path := func(imp string) string {
+		p, _ := strconv.Unquote(imp[strings.IndexByte(imp, '"'):])
+		return p
+	}
+
+	var std, thirdParty []string
+	for _, imp := range imports {
+		if first := strings.SplitN(path(imp), "/", 2)[0]; strings.Contains(first, ".") {
+			thirdParty = append(thirdParty, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+
+	for _, group := range [][]string{std, thirdParty} {
+		sort.Slice(group, func(i, j int) bool { return path(group[i]) < path(group[j]) })
+	}
+
+	if len(std) != 0 && len(thirdParty) != 0 {
+		std = append(std, "")
+	}
+	return append(std, thirdParty...)
+}
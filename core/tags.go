@@ -0,0 +1,76 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/vattle/sqlboiler/strmangle"
+)
+
+// Struct tag casings for Config.StructTagCasing.
+const (
+	TagCasingSnake = "snake"
+	TagCasingCamel = "camel"
+	TagCasingTitle = "title"
+)
+
+// StructTag returns the json/db tag value generated for column under casing.
+// The snake casing, also used when casing is empty, is the column name as is.
+func StructTag(casing, column string) string {
+	switch casing {
+	case TagCasingCamel:
+		return strmangle.CamelCase(column)
+	case TagCasingTitle:
+		return strmangle.TitleCase(column)
+	}
+
+	return column
+}
+
+// JSONTag returns the json tag value generated for column under casing,
+// with the ,string option on columns flagged JSONString.
+func JSONTag(casing string, column db.Column) string {
+	tag := StructTag(casing, column.Name)
+	if column.JSONString {
+		tag += ",string"
+	}
+
+	return tag
+}
+
+// checkTags ensures the generated struct tags are unique within each table,
+// since two fields sharing a tag silently break marshaling. Collisions, and
+// an unknown casing, are added to problems.
+func checkTags(tables []db.Table, casing string, problems *db.ValidationError) {
+	switch casing {
+	case "", TagCasingSnake, TagCasingCamel, TagCasingTitle:
+	default:
+		problems.Add("", "unknown struct tag casing %q", casing)
+		return
+	}
+
+	for _, t := range tables {
+		if t.IsJoinTable {
+			continue
+		}
+
+		columns := map[string][]string{}
+		for _, c := range t.Columns {
+			tag := StructTag(casing, c.Name)
+			columns[tag] = append(columns[tag], c.Name)
+		}
+
+		var tags []string
+		for tag, names := range columns {
+			if len(names) > 1 {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+
+		for _, tag := range tags {
+			problems.Add(t.Name, "duplicate struct tag %s (%s)", tag, strings.Join(columns[tag], ", "))
+		}
+	}
+}
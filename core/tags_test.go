@@ -0,0 +1,57 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestCheckTags(t *testing.T) {
+	t.Parallel()
+
+	tables := []db.Table{
+		{
+			Name:    "users",
+			Columns: []db.Column{{Name: "id"}, {Name: "user_id"}, {Name: "userID"}},
+		},
+	}
+
+	problems := &db.ValidationError{}
+	checkTags(tables, TagCasingSnake, problems)
+	if err := problems.Err(); err != nil {
+		t.Errorf("want distinct snake case tags, got: %s", err)
+	}
+
+	checkTags(tables, TagCasingCamel, problems)
+	err := problems.Err()
+	if err == nil {
+		t.Fatal("want a collision between user_id and userID")
+	}
+	if want := "invalid schema:\n  users: duplicate struct tag userID (user_id, userID)"; err.Error() != want {
+		t.Errorf("want: %s\ngot:  %s", want, err)
+	}
+
+	problems = &db.ValidationError{}
+	checkTags(tables, "kebab", problems)
+	if err := problems.Err(); err == nil {
+		t.Error("want an error for an unknown casing")
+	}
+}
+
+func TestJSONTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Column db.Column
+		Want   string
+	}{
+		{db.Column{Name: "user_id", TypeName: "int64"}, "userID"},
+		{db.Column{Name: "user_id", TypeName: "int64", JSONString: true}, "userID,string"},
+	}
+
+	for i, test := range tests {
+		if got := JSONTag(TagCasingCamel, test.Column); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+// TemplateData is what renderers are given for each file they render.
+type TemplateData struct {
+	// Table is the table being rendered, zero for singleton files.
+	Table db.Table
+	// Tables is every table in the schema.
+	Tables []db.Table
+
+	PkgName string
+	// Collation is the session collation introspection ran under, if one
+	// was configured.
+	Collation string
+
+	// InstrumentationHook is the package.Func generated queries should be
+	// wrapped in, if one was configured.
+	InstrumentationHook string
+	// NoFieldComments is whether column comments should be left out of
+	// field doc comments.
+	NoFieldComments bool
+	// ConnFromContext is the package.Func that returns the executor stored
+	// in a context.Context, if one was configured.
+	ConnFromContext string
+	// ColumnGroups are the configured EmbedColumnGroups that Table has
+	// every column of, sorted by name.
+	ColumnGroups []ColumnGroup
+
+	// LQ and RQ are the driver's left and right identifier quotes.
+	LQ byte
+	RQ byte
+	// MaxPlaceholders is the most placeholders the driver allows in a
+	// single statement.
+	MaxPlaceholders int
+	// QuoteLiteral quotes a string as a literal of the driver's dialect,
+	// for renderers writing seed SQL.
+	QuoteLiteral func(s string) string
+}
+
+// QuoteIdentifier quotes name with the driver's identifier quotes, doubling
+// any right quote inside it. A schema qualified name like schema.table is
+// quoted part by part.
+func (t *TemplateData) QuoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		rq := string(t.RQ)
+		parts[i] = string(t.LQ) + strings.Replace(part, rq, rq+rq, -1) + rq
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// QuotedColumns returns the quoted name of each of Table's columns, in
+// order.
+func (t *TemplateData) QuotedColumns() []string {
+	names := make([]string, len(t.Table.Columns))
+	for i, c := range t.Table.Columns {
+		names[i] = t.QuoteIdentifier(c.Name)
+	}
+
+	return names
+}
+
+// FieldComment returns the comment of column c as a single line, safe to
+// emit after "// " as the doc comment of its field, or "" if it has none or
+// NoFieldComments is set.
+func (t *TemplateData) FieldComment(c db.Column) string {
+	if t.NoFieldComments {
+		return ""
+	}
+
+	comment := strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, c.Comment)
+
+	return strings.Join(strings.Fields(comment), " ")
+}
+
+// EnumConstants returns the constants for the EnumValues of column c, in
+// order, named after its enum type, e.g. PostStatusInProgress for "in
+// progress". Nullable enum columns have no type of their own, so their
+// constants are named as if they did: after the table model and column.
+func (t *TemplateData) EnumConstants(c db.Column) []EnumConstant {
+	typeName := c.TypeName
+	if !c.IsEnumType() {
+		typeName = t.Table.GoName + db.GoName(c.Name)
+	}
+
+	return enumConstants(typeName, c.EnumValues)
+}
+
+// InsertBatchSize returns how many rows of Table a single multi-row insert
+// can hold without exceeding MaxPlaceholders, or 0 if there is no limit or
+// nothing to insert.
+func (t *TemplateData) InsertBatchSize() int {
+	columns := len(t.Table.InsertColumns())
+	if t.MaxPlaceholders <= 0 || columns == 0 {
+		return 0
+	}
+
+	return t.MaxPlaceholders / columns
+}
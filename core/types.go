@@ -1,14 +1,48 @@
 package core
 
-import (
-	"github.com/mickeyreiss/sqlgen/db"
-	"io"
-)
+import "io"
 
 type TableRenderer interface {
-	Render(table db.Table, w io.Writer) error
+	Render(data *TemplateData, w io.Writer) error
+}
+
+// NamedRenderer pairs a TableRenderer with the suffix of the file it renders
+// each table into, e.g. "_gen.go" or ".gen.proto".
+type NamedRenderer struct {
+	Suffix   string
+	Renderer TableRenderer
 }
 
 type TableTestRenderer interface {
-	RenderTest(table db.Table, w io.Writer) error
+	RenderTest(data *TemplateData, w io.Writer) error
+}
+
+// SingletonRenderer renders a file that covers every table at once, such as a
+// schema registry. The TemplateData it is given has no Table.
+type SingletonRenderer interface {
+	RenderSingleton(data *TemplateData, w io.Writer) error
+}
+
+// NamedSingletonRenderer pairs a SingletonRenderer with the file, relative to
+// the output folder, that it renders into.
+type NamedSingletonRenderer struct {
+	Filename string
+	Renderer SingletonRenderer
+}
+
+// Logger receives the diagnostic output of a State: the Debug schema dump,
+// progress, and warnings such as dropped foreign keys and connection
+// retries. Tables are rendered concurrently, so it must be safe to call
+// from several goroutines.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
 }
+
+// nopLogger is the Logger of a Config without one, discarding everything.
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Warnf(format string, args ...interface{})  {}
@@ -1,9 +1,10 @@
 package db
 
 import (
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
-
-	"github.com/vattle/sqlboiler/strmangle"
 )
 
 // Column holds information about a database column.
@@ -19,18 +20,86 @@ type Column struct {
 	Unique    bool
 	Validated bool
 
+	// AutoIncrement is set for columns the database fills in from a
+	// sequence on insert: MySQL auto_increment and Postgres serials.
+	AutoIncrement bool
+	// AutoUpdateTime is set for timestamps the database bumps on every
+	// update (MySQL ON UPDATE CURRENT_TIMESTAMP). They are still inserted,
+	// but left out of UPDATE sets.
+	AutoUpdateTime bool
+	// AutoTouch is set for columns generated updates should set to the
+	// current time themselves, e.g. updated_at (see core's
+	// Config.AutoTouchColumns). It is never set on AutoUpdateTime columns,
+	// which the database already bumps.
+	AutoTouch bool
+	// OptionalOnInsert is set for not null columns with a default, which
+	// the database fills in when an INSERT leaves them out.
+	OptionalOnInsert bool
+	// MaxLength is the length limit of char, varchar and binary columns,
+	// or the length in bits of Postgres bit strings, 0 for unlimited or
+	// other types.
+	MaxLength int
+	// Precision and Scale are the total and fractional digits of decimal
+	// columns, e.g. 10 and 2 for decimal(10,2), 0 for other types.
+	Precision int
+	Scale     int
+	// SRID is the spatial reference system a geometry column is restricted
+	// to, e.g. 4326, or 0 if it isn't.
+	SRID int
+	// JSONString is set for int64 and uint64 columns when
+	// Options.StringifyLargeInts is, for their json tags to have ,string.
+	JSONString bool
+	// EnumValues are the values of an enum column, or the members of a set
+	// column, in their declared order, which is how MySQL sorts enums. Not
+	// null enum columns have a Go type named for them, e.g. PostStatus,
+	// backed by string. Columns of a named Postgres enum type share one
+	// named for the type, e.g. Mood for mood (see Enums).
+	EnumValues []string
+	// Comment is the column's comment in the database.
+	Comment string
+	// Directives are the words of Comment starting with the directive
+	// prefix (see Options.DirectivePrefix), without it: "skip" for @skip.
+	Directives []string
+	// Check holds the column's simple range or IN CHECK constraint, if the
+	// driver reports one.
+	Check *ColumnCheck
+
 	// Postgres only extension bits
 	// ArrType is the underlying data type of the Postgres
 	// ARRAY type. See here:
 	// https://www.postgresql.org/docs/9.1/static/infoschema-element-types.html
 	ArrType *string
 	UDTName string
+	// CaseInsensitive is set for text columns that compare without regard
+	// to case (citext), so lookups don't need to wrap them in LOWER().
+	CaseInsensitive bool
+	// Composite is the composite type of a column declared with one, e.g.
+	// CREATE TYPE address AS (...). The column's Go type is the struct
+	// generated for it (see CompositeTypes).
+	Composite *CompositeType
 
 	// MySQL only bits
 	// Used to get full type, ex:
 	// tinyint(1) instead of tinyint
 	// Used for "tinyint-as-bool" flag
 	FullDBType string
+	// Generated is set for columns computed from the others (GENERATED
+	// ALWAYS AS (...) VIRTUAL or STORED), which can't be inserted or
+	// updated.
+	Generated bool
+	// GeneratedStored is set for Generated columns that are STORED, written
+	// to disk when their row is, rather than VIRTUAL, computed when read.
+	GeneratedStored bool
+	// GeneratedFrom are the columns a Generated column's expression refers
+	// to, as far as it can be parsed, in the order they first appear.
+	GeneratedFrom []string
+	// ForceBool is set for columns listed in the driver's BoolColumns,
+	// generated as bools whatever their DBType, which is kept.
+	ForceBool bool
+	// ZeroDateDefault is set for date and time columns whose Default is the
+	// zero date (0000-00-00), which isn't a valid date outside of MySQL.
+	// Generated code should use ZeroValueExpr rather than parse Default.
+	ZeroDateDefault bool
 
 	// MS SQL only bits
 	// Used to indicate that the value
@@ -38,6 +107,147 @@ type Column struct {
 	AutoGenerated bool
 }
 
+// IsEnumType reports whether the column's Go type is one generated for its
+// enum values (see Enums), rather than a string or null.String.
+func (c Column) IsEnumType() bool {
+	return len(c.EnumValues) != 0 && len(c.PkgName) == 0 && c.TypeName != "string" && !strings.Contains(c.TypeName, ".")
+}
+
+// EnumOrdinal is the position of value in EnumValues, counting from 1 as
+// MySQL does when sorting an enum column, or 0 if it isn't a member (MySQL
+// stores invalid values as the empty string, ordinal 0).
+func (c Column) EnumOrdinal(value string) int {
+	for i, v := range c.EnumValues {
+		if v == value {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// HasDirective reports whether the column's comment has the directive.
+func (c Column) HasDirective(directive string) bool {
+	for _, d := range c.Directives {
+		if d == directive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseDirectives returns the words of comment that start with prefix,
+// minus the prefix, e.g. [json skip] for "legacy @json @skip".
+func parseDirectives(comment, prefix string) []string {
+	var directives []string
+	for _, word := range strings.Fields(comment) {
+		if d := strings.TrimPrefix(word, prefix); len(d) != 0 && len(d) != len(word) {
+			directives = append(directives, d)
+		}
+	}
+
+	return directives
+}
+
+// GoTypeExpr is the Go type of the column as written in a struct field,
+// e.g. time.Time, null.Int or []byte. TypeName is qualified by the name of
+// the PkgName import when the driver sets one, after any pointer or slice
+// prefix (*Point is *geo.Point), and is used as is otherwise.
+func (c Column) GoTypeExpr() string {
+	if len(c.PkgName) == 0 {
+		return c.TypeName
+	}
+
+	name := strings.TrimLeft(c.TypeName, "*[]")
+	prefix := c.TypeName[:len(c.TypeName)-len(name)]
+	return prefix + importName(c.PkgName) + "." + name
+}
+
+// nilTypes are the named Go types drivers map columns to that are slices or
+// maps underneath, whose zero value is nil rather than a composite literal.
+var nilTypes = map[string]bool{
+	"json.RawMessage":    true,
+	"types.JSON":         true,
+	"types.Set":          true,
+	"types.HStore":       true,
+	"types.BoolArray":    true,
+	"types.BytesArray":   true,
+	"types.Float64Array": true,
+	"types.Int64Array":   true,
+	"types.StringArray":  true,
+}
+
+// ZeroValueExpr is the Go expression of the zero value of the column's
+// type: 0, "", false, nil for slices, maps, pointers and named types over
+// them such as types.JSON, and a composite literal such as time.Time{} or
+// null.Int{} for other named types.
+func (c Column) ZeroValueExpr() string {
+	typ := c.GoTypeExpr()
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "complex64", "complex128", "byte", "rune",
+		"types.Byte":
+		return "0"
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "interface{}":
+		return "nil"
+	}
+
+	switch {
+	case strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "map["), nilTypes[typ]:
+		return "nil"
+	case c.IsEnumType():
+		// Enum types generated for the column are backed by string.
+		return `""`
+	}
+
+	return typ + "{}"
+}
+
+// importName is the package name an import path is referred to by, its
+// last element without a gopkg.in style version: null for
+// gopkg.in/nullbio/null.v6.
+func importName(path string) string {
+	name := path[strings.LastIndexByte(path, '/')+1:]
+	if i := strings.Index(name, ".v"); i > 0 {
+		if _, err := strconv.Atoi(name[i+2:]); err == nil {
+			name = name[:i]
+		}
+	}
+
+	return name
+}
+
+// ColumnImports returns the sorted, distinct import paths the Go types of
+// the columns need, the standard library's (e.g. time) apart from the
+// others, for the separate groups gofmt'd Go puts them in. Only PkgName is
+// considered, TypeNames qualified by the driver need their own imports.
+func ColumnImports(cols []Column) (std, thirdParty []string) {
+	seen := map[string]bool{}
+	for _, c := range cols {
+		if len(c.PkgName) == 0 || seen[c.PkgName] {
+			continue
+		}
+		seen[c.PkgName] = true
+
+		// Standard library paths have no domain in their first element.
+		if first := strings.SplitN(c.PkgName, "/", 2)[0]; strings.Contains(first, ".") {
+			thirdParty = append(thirdParty, c.PkgName)
+		} else {
+			std = append(std, c.PkgName)
+		}
+	}
+
+	sort.Strings(std)
+	sort.Strings(thirdParty)
+	return std, thirdParty
+}
+
 // ColumnNames of the columns.
 func ColumnNames(cols []Column) []string {
 	names := make([]string, len(cols))
@@ -53,7 +263,7 @@ func ColumnDBTypes(cols []Column) map[string]string {
 	types := map[string]string{}
 
 	for _, c := range cols {
-		types[strmangle.TitleCase(c.Name)] = c.DBType
+		types[GoName(c.Name)] = c.DBType
 	}
 
 	return types
@@ -97,3 +307,192 @@ func FilterColumnsByEnum(columns []Column) []Column {
 
 	return cols
 }
+
+// rgxLengthType matches the MySQL column types that have a length limit,
+// capturing it.
+var rgxLengthType = regexp.MustCompile(`^(?:var)?(?:char|binary)\((\d+)\)`)
+
+// rgxDecimalType matches the MySQL decimal column types, capturing their
+// precision and optional scale.
+var rgxDecimalType = regexp.MustCompile(`^(?:decimal|numeric|dec|fixed)\((\d+)(?:,\s*(\d+))?\)`)
+
+// columnLimits fills in the MaxLength, Precision, Scale and EnumValues of a
+// column from its database type, unless the driver already did.
+func columnLimits(c Column) Column {
+	if c.MaxLength == 0 {
+		if m := rgxLengthType.FindStringSubmatch(strings.ToLower(c.FullDBType)); m != nil {
+			c.MaxLength, _ = strconv.Atoi(m[1])
+		}
+	}
+	if c.Precision == 0 {
+		if m := rgxDecimalType.FindStringSubmatch(strings.ToLower(c.FullDBType)); m != nil {
+			c.Precision, _ = strconv.Atoi(m[1])
+			c.Scale, _ = strconv.Atoi(m[2])
+		}
+	}
+	if c.EnumValues == nil {
+		c.EnumValues = ParseEnumValues(c.DBType)
+	}
+
+	return c
+}
+
+// ParseEnumValues returns the members of an enum('a','b') or set('a','b')
+// database type, or of the Postgres enum.name('a','b') the postgres driver
+// gives columns of a named enum type, unescaping quotes doubled inside them,
+// or nil if dbType is none of those.
+func ParseEnumValues(dbType string) []string {
+	lower := strings.ToLower(dbType)
+	var list string
+	switch {
+	case strings.HasPrefix(lower, "enum(") && strings.HasSuffix(lower, ")"):
+		list = dbType[len("enum(") : len(dbType)-1]
+	case enumTypeName(dbType) != "" && strings.HasSuffix(lower, ")"):
+		list = dbType[strings.IndexByte(dbType, '(')+1 : len(dbType)-1]
+	case strings.HasPrefix(lower, "set(") && strings.HasSuffix(lower, ")"):
+		list = dbType[len("set(") : len(dbType)-1]
+	default:
+		return nil
+	}
+
+	vals := []string{}
+	for len(list) != 0 {
+		if list[0] != '\'' {
+			return nil
+		}
+
+		var val []byte
+		i := 1
+		for ; i < len(list); i++ {
+			if list[i] == '\'' {
+				if i+1 < len(list) && list[i+1] == '\'' {
+					val = append(val, '\'')
+					i++
+					continue
+				}
+				break
+			}
+			val = append(val, list[i])
+		}
+		if i == len(list) {
+			return nil
+		}
+		vals = append(vals, string(val))
+
+		list = strings.TrimLeft(list[i+1:], " ")
+		if len(list) != 0 {
+			if list[0] != ',' {
+				return nil
+			}
+			list = strings.TrimLeft(list[1:], " ")
+			if len(list) == 0 {
+				return nil
+			}
+		}
+	}
+
+	return vals
+}
+
+// enumTypeName returns the name of the Postgres enum type of a column with
+// the enum.name('a','b') dbType, e.g. mood, or "" for any other dbType.
+func enumTypeName(dbType string) string {
+	if !strings.HasPrefix(strings.ToLower(dbType), "enum.") {
+		return ""
+	}
+
+	end := strings.IndexByte(dbType, '(')
+	if end < 0 {
+		return ""
+	}
+	return dbType[len("enum."):end]
+}
+
+// enumType gives a not null enum column a string-backed Go type of its own,
+// named after the table model and column, e.g. PostStatus, for renderers
+// to declare with a constant per EnumValues member. Columns of a named
+// Postgres enum type get the type's name instead, e.g. Mood, the same in
+// every table. Nullable enums stay null.String.
+func enumType(tableGoName string, c Column) Column {
+	if len(c.EnumValues) == 0 || c.Nullable || len(c.PkgName) != 0 || c.TypeName != "string" {
+		return c
+	}
+
+	if name := enumTypeName(c.DBType); name != "" {
+		c.TypeName = GoName(name)
+	} else if strings.HasPrefix(strings.ToLower(c.DBType), "enum(") {
+		c.TypeName = tableGoName + GoName(c.Name)
+	}
+
+	return c
+}
+
+// RenameEnumTypes renames the enum types of t's columns that were named
+// after the table when its Go name was oldGoName, e.g. PostStatus, after
+// t.GoName, e.g. ArticleStatus. Other TypeNames are left alone.
+func RenameEnumTypes(t *Table, oldGoName string) {
+	if t.GoName == oldGoName {
+		return
+	}
+
+	for i, c := range t.Columns {
+		if c.IsEnumType() && c.TypeName == oldGoName+GoName(c.Name) {
+			t.Columns[i].TypeName = t.GoName + GoName(c.Name)
+		}
+	}
+}
+
+// stringifyLargeInt flags a column as marshaled to a JSON string if its Go
+// type is int64 or uint64, which JavaScript clients can't hold exactly. The
+// null types marshal themselves and ignore ,string, so they aren't flagged.
+func stringifyLargeInt(c Column) Column {
+	if len(c.PkgName) == 0 && (c.TypeName == "int64" || c.TypeName == "uint64") {
+		c.JSONString = true
+	}
+
+	return c
+}
+
+// widenInt widens an integer column's Go type to 64 bits, keeping its
+// signedness and nullability. Other columns are returned unchanged.
+func widenInt(c Column) Column {
+	switch c.TypeName {
+	case "int", "int8", "int16", "int32":
+		c.TypeName = "int64"
+	case "uint", "uint8", "uint16", "uint32":
+		c.TypeName = "uint64"
+	case "Int", "Int8", "Int16", "Int32":
+		c.TypeName = "Int64"
+	case "Uint", "Uint8", "Uint16", "Uint32":
+		c.TypeName = "Uint64"
+	case "null.Int", "null.Int8", "null.Int16", "null.Int32":
+		c.TypeName = "null.Int64"
+	case "null.Uint", "null.Uint8", "null.Uint16", "null.Uint32":
+		c.TypeName = "null.Uint64"
+	}
+
+	return c
+}
+
+// civilDate maps a date column to types.Date (or types.NullDate), keeping
+// the driver's convention of either a qualified TypeName or an import path
+// in PkgName. Other columns are returned unchanged.
+func civilDate(c Column) Column {
+	if c.DBType != "date" {
+		return c
+	}
+
+	typeName := "Date"
+	if c.Nullable {
+		typeName = "NullDate"
+	}
+
+	if len(c.PkgName) != 0 {
+		c.PkgName = "github.com/mickeyreiss/sqlgen/types"
+		c.TypeName = typeName
+	} else {
+		c.TypeName = "types." + typeName
+	}
+
+	return c
+}
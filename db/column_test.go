@@ -1,6 +1,7 @@
 package db
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -86,3 +87,219 @@ func TestFilterColumnsByEnum(t *testing.T) {
 		t.Errorf("Invalid result: %#v", res)
 	}
 }
+
+func TestWidenInt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		In   string
+		Want string
+	}{
+		{"int", "int64"},
+		{"int16", "int64"},
+		{"int64", "int64"},
+		{"uint8", "uint64"},
+		{"Int32", "Int64"},
+		{"Uint", "Uint64"},
+		{"null.Int", "null.Int64"},
+		{"null.Uint16", "null.Uint64"},
+		{"string", "string"},
+		{"float32", "float32"},
+	}
+
+	for i, test := range tests {
+		if got := widenInt(Column{TypeName: test.In}).TypeName; got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestCivilDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		In   Column
+		Want Column
+	}{
+		{
+			In:   Column{DBType: "date", TypeName: "time.Time"},
+			Want: Column{DBType: "date", TypeName: "types.Date"},
+		},
+		{
+			In:   Column{DBType: "date", TypeName: "null.Time", Nullable: true},
+			Want: Column{DBType: "date", TypeName: "types.NullDate", Nullable: true},
+		},
+		{
+			In:   Column{DBType: "date", PkgName: "time", TypeName: "Time"},
+			Want: Column{DBType: "date", PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "Date"},
+		},
+		{
+			In:   Column{DBType: "date", PkgName: "gopkg.in/nullbio/null.v6", TypeName: "Time", Nullable: true},
+			Want: Column{DBType: "date", PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "NullDate", Nullable: true},
+		},
+		{
+			In:   Column{DBType: "datetime", PkgName: "time", TypeName: "Time"},
+			Want: Column{DBType: "datetime", PkgName: "time", TypeName: "Time"},
+		},
+	}
+
+	for i, test := range tests {
+		if got := civilDate(test.In); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("%d) want: %#v, got: %#v", i, test.Want, got)
+		}
+	}
+}
+
+func TestColumnLimitsDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		FullDBType string
+		Precision  int
+		Scale      int
+	}{
+		{"decimal(10,2)", 10, 2},
+		{"decimal(10,2) unsigned", 10, 2},
+		{"NUMERIC(8)", 8, 0},
+		{"dec(5, 1)", 5, 1},
+		{"int(10)", 0, 0},
+	}
+
+	for i, test := range tests {
+		c := columnLimits(Column{FullDBType: test.FullDBType})
+		if c.Precision != test.Precision || c.Scale != test.Scale {
+			t.Errorf("%d) want: %d,%d, got: %d,%d", i, test.Precision, test.Scale, c.Precision, c.Scale)
+		}
+	}
+}
+
+func TestParseEnumValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		DBType string
+		Want   []string
+	}{
+		{"enum('active','inactive','pending')", []string{"active", "inactive", "pending"}},
+		{"ENUM('a', 'b')", []string{"a", "b"}},
+		{"enum('it''s','a,b','')", []string{"it's", "a,b", ""}},
+		{"set('x','y')", []string{"x", "y"}},
+		{"set()", []string{}},
+		{"enum('unterminated)", nil},
+		{"enum('a',)", nil},
+		{"varchar(10)", nil},
+		{"enum.mood('happy','it''s ok')", []string{"happy", "it's ok"}},
+		{"enum.mood", nil},
+	}
+
+	for i, test := range tests {
+		if got := ParseEnumValues(test.DBType); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("%d) want: %#v, got: %#v", i, test.Want, got)
+		}
+	}
+}
+
+func TestEnumType(t *testing.T) {
+	t.Parallel()
+
+	values := []string{"draft", "published"}
+	tests := []struct {
+		In   Column
+		Want string
+	}{
+		{Column{Name: "status", DBType: "enum('draft','published')", TypeName: "string", EnumValues: values}, "PostStatus"},
+		{Column{Name: "status", DBType: "enum('draft','published')", TypeName: "null.String", EnumValues: values, Nullable: true}, "null.String"},
+		{Column{Name: "tags", DBType: "set('draft','published')", TypeName: "string", EnumValues: values}, "string"},
+		{Column{Name: "name", DBType: "varchar", TypeName: "string"}, "string"},
+		{Column{Name: "feeling", DBType: "enum.user_mood('happy','sad')", TypeName: "string", EnumValues: values}, "UserMood"},
+		{Column{Name: "feeling", DBType: "enum.user_mood('happy','sad')", TypeName: "null.String", EnumValues: values, Nullable: true}, "null.String"},
+	}
+
+	for i, test := range tests {
+		if got := enumType("Post", test.In).TypeName; got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestColumnGoTypeExpr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Column Column
+		Want   string
+	}{
+		{Column{PkgName: "time", TypeName: "Time"}, "time.Time"},
+		{Column{PkgName: "gopkg.in/nullbio/null.v6", TypeName: "Int"}, "null.Int"},
+		{Column{PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "JSON"}, "types.JSON"},
+		{Column{PkgName: "github.com/paulmach/orb", TypeName: "*Point"}, "*orb.Point"},
+		{Column{PkgName: "github.com/paulmach/orb", TypeName: "[]Point"}, "[]orb.Point"},
+		{Column{PkgName: "encoding/json", TypeName: "RawMessage"}, "json.RawMessage"},
+		{Column{TypeName: "[]byte"}, "[]byte"},
+		{Column{TypeName: "int64"}, "int64"},
+		{Column{TypeName: "null.String"}, "null.String"},
+	}
+
+	for i, test := range tests {
+		if got := test.Column.GoTypeExpr(); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestColumnImports(t *testing.T) {
+	t.Parallel()
+
+	cols := []Column{
+		{PkgName: "gopkg.in/nullbio/null.v6", TypeName: "Int"},
+		{PkgName: "time", TypeName: "Time"},
+		{TypeName: "[]byte"},
+		{PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "JSON"},
+		{PkgName: "gopkg.in/nullbio/null.v6", TypeName: "String"},
+		{PkgName: "encoding/json", TypeName: "RawMessage"},
+	}
+
+	std, thirdParty := ColumnImports(cols)
+	if want := []string{"encoding/json", "time"}; !reflect.DeepEqual(std, want) {
+		t.Errorf("want standard imports: %v, got: %v", want, std)
+	}
+	if want := []string{"github.com/mickeyreiss/sqlgen/types", "gopkg.in/nullbio/null.v6"}; !reflect.DeepEqual(thirdParty, want) {
+		t.Errorf("want third party imports: %v, got: %v", want, thirdParty)
+	}
+}
+
+func TestColumnZeroValueExpr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Column Column
+		Want   string
+	}{
+		{Column{TypeName: "int"}, "0"},
+		{Column{TypeName: "uint64"}, "0"},
+		{Column{TypeName: "float32"}, "0"},
+		{Column{TypeName: "types.Byte"}, "0"},
+		{Column{TypeName: "string"}, `""`},
+		{Column{TypeName: "bool"}, "false"},
+		{Column{TypeName: "[]byte"}, "nil"},
+		{Column{TypeName: "*string"}, "nil"},
+		{Column{PkgName: "time", TypeName: "Time"}, "time.Time{}"},
+		{Column{TypeName: "time.Time"}, "time.Time{}"},
+		{Column{PkgName: "gopkg.in/nullbio/null.v6", TypeName: "Int"}, "null.Int{}"},
+		{Column{TypeName: "null.String"}, "null.String{}"},
+		{Column{PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "JSON"}, "nil"},
+		{Column{PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "Set"}, "nil"},
+		{Column{PkgName: "encoding/json", TypeName: "RawMessage"}, "nil"},
+		{Column{TypeName: "types.StringArray"}, "nil"},
+		{Column{TypeName: "types.HStore"}, "nil"},
+		{Column{PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "NullStringArray"}, "types.NullStringArray{}"},
+		{Column{PkgName: "github.com/mickeyreiss/sqlgen/types", TypeName: "Decimal"}, "types.Decimal{}"},
+		{Column{TypeName: "PostStatus", EnumValues: []string{"draft"}}, `""`},
+	}
+
+	for i, test := range tests {
+		if got := test.Column.ZeroValueExpr(); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
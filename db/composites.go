@@ -0,0 +1,34 @@
+package db
+
+import "sort"
+
+// CompositeType is a Postgres composite (row) type, generated as a struct
+// with a field per attribute.
+type CompositeType struct {
+	// Name is the type's name in the database, e.g. address.
+	Name string
+	// GoName is the struct's name, e.g. Address.
+	GoName string
+	// Fields are the type's attributes, in order, with their Go types
+	// translated like columns.
+	Fields []Column
+}
+
+// CompositeTypes returns the composite types of the columns of tables,
+// sorted by GoName, each once however many columns use it.
+func CompositeTypes(tables []Table) []CompositeType {
+	seen := make(map[string]bool)
+	var composites []CompositeType
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.Composite == nil || seen[c.Composite.GoName] {
+				continue
+			}
+			seen[c.Composite.GoName] = true
+			composites = append(composites, *c.Composite)
+		}
+	}
+
+	sort.Slice(composites, func(i, j int) bool { return composites[i].GoName < composites[j].GoName })
+	return composites
+}
@@ -0,0 +1,22 @@
+package db
+
+import "testing"
+
+func TestCompositeTypes(t *testing.T) {
+	t.Parallel()
+
+	address := &CompositeType{Name: "address", GoName: "Address", Fields: []Column{{Name: "street"}, {Name: "city"}}}
+	money := &CompositeType{Name: "money_amount", GoName: "MoneyAmount"}
+	tables := []Table{
+		{Name: "users", Columns: []Column{{Name: "home", Composite: address}, {Name: "id"}}},
+		{Name: "orders", Columns: []Column{{Name: "total", Composite: money}, {Name: "ship_to", Composite: address}}},
+	}
+
+	got := CompositeTypes(tables)
+	if len(got) != 2 || got[0].GoName != "Address" || got[1].GoName != "MoneyAmount" {
+		t.Fatalf("want Address and MoneyAmount once each, got: %#v", got)
+	}
+	if len(got[0].Fields) != 2 {
+		t.Errorf("want the fields of address, got: %#v", got[0].Fields)
+	}
+}
@@ -0,0 +1,304 @@
+package drivers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/pkg/errors"
+)
+
+// CockroachDriver introspects CockroachDB over the Postgres wire protocol.
+// It is the PostgresDriver with information_schema queries in place of the
+// pg_catalog ones CockroachDB doesn't fully support, and its type names
+// (STRING, INT8, ...) translated as their Postgres equivalents. CockroachDB
+// enums are generated as strings and columns have no comments.
+type CockroachDriver struct {
+	*PostgresDriver
+}
+
+// NewCockroachDriver takes the database connection details as parameters and
+// returns a pointer to a CockroachDriver object, which must be opened and
+// closed like a PostgresDriver.
+func NewCockroachDriver(user, pass, dbname, host string, port int, sslmode, sslcert, sslkey, sslrootcert string) *CockroachDriver {
+	return &CockroachDriver{PostgresDriver: NewPostgresDriver(user, pass, dbname, host, port, sslmode, sslcert, sslkey, sslrootcert)}
+}
+
+// Schemas lists the schemas of the database, minus the system schemas.
+func (c *CockroachDriver) Schemas(ctx context.Context) ([]string, error) {
+	var names []string
+
+	rows, err := c.conn().QueryContext(ctx, `
+	select schema_name from information_schema.schemata
+	where schema_name not in ('crdb_internal', 'information_schema', 'pg_catalog', 'pg_extension')
+	order by schema_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// PrimaryKeyInfo looks up the primary key of a table. CockroachDB before
+// 22.1 names every primary key "primary", so unlike Postgres its columns are
+// matched by table as well as by constraint name.
+func (c *CockroachDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*db.PrimaryKey, error) {
+	rows, err := c.conn().QueryContext(ctx, `
+	select tc.constraint_name, kcu.column_name
+	from information_schema.table_constraints as tc
+	inner join information_schema.key_column_usage as kcu
+		on kcu.constraint_name = tc.constraint_name
+		and kcu.table_schema = tc.table_schema
+		and kcu.table_name = tc.table_name
+	where tc.table_name = $1 and tc.table_schema = $2 and tc.constraint_type = 'PRIMARY KEY'
+	order by kcu.ordinal_position`, tableName, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pkey *db.PrimaryKey
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, err
+		}
+		if pkey == nil {
+			pkey = &db.PrimaryKey{Name: name}
+		}
+		pkey.Columns = append(pkey.Columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pkey, nil
+}
+
+// Columns retrieves the columns of a table from information_schema.columns,
+// leaving out hidden ones like the rowid of tables without a primary key.
+func (c *CockroachDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
+	var columns []db.Column
+
+	rows, err := c.conn().QueryContext(ctx, `
+		select
+		c.column_name,
+		c.data_type,
+		c.udt_name,
+		c.column_default,
+		c.is_nullable = 'YES' as is_nullable,
+		exists(
+			select 1
+			from information_schema.statistics s
+			where s.table_schema = c.table_schema and s.table_name = c.table_name and s.column_name = c.column_name and
+				s.non_unique = 'NO' and s.storing = 'NO' and s.implicit = 'NO' and
+				(select count(*) from information_schema.statistics s2
+				where s2.table_schema = s.table_schema and s2.table_name = s.table_name and s2.index_name = s.index_name and
+					s2.storing = 'NO' and s2.implicit = 'NO') = 1
+		) as is_unique,
+		c.character_maximum_length
+		from information_schema.columns as c
+		where c.table_schema = $1 and c.table_name = $2 and c.is_hidden = 'NO'
+		order by c.ordinal_position
+	`, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var colName, colType, udtName string
+		var defaultValue *string
+		var nullable, unique bool
+		var maxLength *int
+		if err := rows.Scan(&colName, &colType, &udtName, &defaultValue, &nullable, &unique, &maxLength); err != nil {
+			return nil, errors.Wrapf(err, "unable to scan for table %s", tableName)
+		}
+
+		column := db.Column{
+			Name:     colName,
+			DBType:   colType,
+			UDTName:  udtName,
+			Nullable: nullable,
+			Unique:   unique,
+		}
+		if defaultValue != nil {
+			column.Default = *defaultValue
+			column.AutoIncrement = strings.HasPrefix(column.Default, "unique_rowid()") || strings.HasPrefix(column.Default, "nextval(")
+		}
+		if maxLength != nil {
+			column.MaxLength = *maxLength
+		}
+
+		columns = append(columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// TableComment returns the comment of a table, empty if it has none.
+func (c *CockroachDriver) TableComment(ctx context.Context, schema, tableName string) (string, error) {
+	var comment string
+
+	query := `select coalesce(obj_description((quote_ident($1) || '.' || quote_ident($2))::regclass, 'pg_class'), '')`
+
+	if err := c.conn().QueryRowContext(ctx, query, schema, tableName).Scan(&comment); err != nil {
+		return "", err
+	}
+
+	return comment, nil
+}
+
+// ForeignKeyInfo retrieves the foreign keys for a given table name, with the
+// columns of composite keys in order. CockroachDB constraints can't be
+// deferred.
+func (c *CockroachDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]db.ForeignKey, error) {
+	var fkeys []db.ForeignKey
+
+	query := `
+	select rc.constraint_name, kcu.column_name, fkcu.table_name, fkcu.column_name, rc.delete_rule, rc.update_rule
+	from information_schema.referential_constraints rc
+		inner join information_schema.key_column_usage kcu
+			on kcu.constraint_schema = rc.constraint_schema and kcu.constraint_name = rc.constraint_name and kcu.table_name = rc.table_name
+		inner join information_schema.key_column_usage fkcu
+			on fkcu.constraint_schema = rc.unique_constraint_schema and fkcu.constraint_name = rc.unique_constraint_name and
+				fkcu.table_name = rc.referenced_table_name and fkcu.ordinal_position = kcu.position_in_unique_constraint
+	where rc.constraint_schema = $1 and rc.table_name = $2
+	order by rc.constraint_name, kcu.ordinal_position`
+
+	rows, err := c.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fkey := db.ForeignKey{Table: tableName}
+		if err := rows.Scan(&fkey.Name, &fkey.Column, &fkey.ForeignTable, &fkey.ForeignColumn, &fkey.OnDelete, &fkey.OnUpdate); err != nil {
+			return nil, err
+		}
+
+		fkeys = append(fkeys, fkey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return db.GroupForeignKeys(fkeys), nil
+}
+
+// IndexInfo retrieves the secondary indexes for a given table name, with
+// their key parts in order. Stored and implicit columns aren't key parts.
+func (c *CockroachDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]db.Index, error) {
+	var indexes []db.Index
+
+	query := `
+	select s.index_name, s.non_unique = 'NO', s.column_name
+	from information_schema.statistics s
+	where s.table_schema = $1 and s.table_name = $2 and s.storing = 'NO' and s.implicit = 'NO' and
+		s.index_name not in (
+			select constraint_name from information_schema.table_constraints
+			where table_schema = $1 and table_name = $2 and constraint_type = 'PRIMARY KEY'
+		)
+	order by s.index_name, s.seq_in_index`
+
+	rows, err := c.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var unique bool
+		var column *string
+		if err := rows.Scan(&name, &unique, &column); err != nil {
+			return nil, err
+		}
+
+		indexes = addIndexPart(indexes, name, unique, column, nil, nil)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return indexes, nil
+}
+
+// cockroachDataTypes are the Postgres data types of CockroachDB's own type
+// names and aliases, which older versions report, upper case, in
+// information_schema.
+var cockroachDataTypes = map[string]string{
+	"string":      "text",
+	"int":         "bigint",
+	"int8":        "bigint",
+	"int64":       "bigint",
+	"int4":        "integer",
+	"int2":        "smallint",
+	"serial":      "bigserial",
+	"serial8":     "bigserial",
+	"serial4":     "serial",
+	"serial2":     "smallserial",
+	"float":       "double precision",
+	"float8":      "double precision",
+	"float4":      "real",
+	"bool":        "boolean",
+	"bytes":       "bytea",
+	"decimal":     "numeric",
+	"timestamptz": "timestamp with time zone",
+	"timestamp":   "timestamp without time zone",
+	"varchar":     "character varying",
+	"char":        "character",
+	"varbit":      "bit varying",
+	"date":        "date",
+	"time":        "time",
+	"interval":    "interval",
+	"inet":        "inet",
+	"uuid":        "uuid",
+	"json":        "json",
+	"jsonb":       "jsonb",
+}
+
+// cockroachDataType returns the Postgres data type of a CockroachDB one,
+// which may be one of its own names, e.g. INT8, or already Postgres's.
+func cockroachDataType(dataType string) string {
+	if t, ok := cockroachDataTypes[strings.ToLower(dataType)]; ok {
+		return t
+	}
+	return dataType
+}
+
+// TranslateColumnType converts CockroachDB types to Go types like the
+// PostgresDriver, after translating CockroachDB type names, including
+// arrays of them like STRING[], to the Postgres ones.
+func (c *CockroachDriver) TranslateColumnType(col db.Column) db.Column {
+	if elem := strings.TrimSuffix(col.DBType, "[]"); elem != col.DBType {
+		elem = cockroachDataType(elem)
+		col.DBType = "ARRAY"
+		col.ArrType = &elem
+	} else if col.DBType != "ARRAY" {
+		col.DBType = cockroachDataType(col.DBType)
+	}
+
+	return c.PostgresDriver.TranslateColumnType(col)
+}
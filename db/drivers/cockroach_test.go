@@ -0,0 +1,78 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestCockroachTranslateColumnType(t *testing.T) {
+	t.Parallel()
+
+	c := NewCockroachDriver("", "", "", "", 0, "", "", "", "")
+
+	tests := []struct {
+		Column db.Column
+		Want   string
+		DBType string
+	}{
+		{db.Column{Name: "id", DBType: "INT8"}, "int64", "bigint"},
+		{db.Column{Name: "id", DBType: "bigint"}, "int64", "bigint"},
+		{db.Column{Name: "id", DBType: "SERIAL"}, "int64", "bigserial"},
+		{db.Column{Name: "n", DBType: "INT4", Nullable: true}, "null.Int", "integer"},
+		{db.Column{Name: "name", DBType: "STRING"}, "string", "text"},
+		{db.Column{Name: "name", DBType: "STRING", Nullable: true}, "null.String", "text"},
+		{db.Column{Name: "data", DBType: "BYTES"}, "[]byte", "bytea"},
+		{db.Column{Name: "ratio", DBType: "FLOAT8"}, "float64", "double precision"},
+		{db.Column{Name: "active", DBType: "BOOL"}, "bool", "boolean"},
+		{db.Column{Name: "at", DBType: "TIMESTAMPTZ", Nullable: true}, "null.Time", "timestamp with time zone"},
+		{db.Column{Name: "doc", DBType: "JSONB"}, "types.JSON", "jsonb"},
+		{db.Column{Name: "tags", DBType: "STRING[]"}, "types.StringArray", "ARRAYtext"},
+		{db.Column{Name: "ids", DBType: "ARRAY", UDTName: "_int8", Nullable: true}, "types.NullInt64Array", "ARRAYbigint"},
+	}
+
+	for i, test := range tests {
+		col := c.TranslateColumnType(test.Column)
+		if col.GoTypeExpr() != test.Want || col.DBType != test.DBType {
+			t.Errorf("%d) want: %s %s, got: %s %s", i, test.Want, test.DBType, col.GoTypeExpr(), col.DBType)
+		}
+	}
+}
+
+func TestCockroachPrimaryKeyInfo(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordingDriver{rows: [][]driver.Value{
+		{"primary", "region"},
+		{"primary", "id"},
+	}}
+	sql.Register("sqlgen-cockroach-pkey-test", rec)
+	conn, err := sql.Open("sqlgen-cockroach-pkey-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &CockroachDriver{PostgresDriver: &PostgresDriver{dbConn: conn}}
+
+	pkey, err := c.PrimaryKeyInfo(context.Background(), "public", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &db.PrimaryKey{Name: "primary", Columns: []string{"region", "id"}}
+	if !reflect.DeepEqual(pkey, want) {
+		t.Errorf("want: %#v, got: %#v", want, pkey)
+	}
+	if len(rec.queries) != 1 || !strings.Contains(rec.queries[0], "kcu.table_name = tc.table_name") {
+		t.Errorf("want the key columns matched by table, got: %v", rec.queries)
+	}
+
+	rec.rows = nil
+	if pkey, err = c.PrimaryKeyInfo(context.Background(), "public", "events"); err != nil || pkey != nil {
+		t.Errorf("want no primary key, got: %#v, %v", pkey, err)
+	}
+}
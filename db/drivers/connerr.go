@@ -0,0 +1,37 @@
+package drivers
+
+import (
+	"database/sql/driver"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// IsConnError reports whether err is the database being unreachable or not
+// yet ready, e.g. a refused connection or a Postgres server still starting
+// up, rather than a query failing. Only these are worth retrying.
+func IsConnError(err error) bool {
+	err = errors.Cause(err)
+
+	switch err {
+	case driver.ErrBadConn, mysql.ErrInvalidConn, io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+
+	switch e := err.(type) {
+	case net.Error:
+		return true
+	case *pq.Error:
+		// Class 08 is connection exceptions, 57P03 cannot_connect_now.
+		return strings.HasPrefix(string(e.Code), "08") || e.Code == "57P03"
+	case *mysql.MySQLError:
+		// Too many connections, and server shutdown in progress.
+		return e.Number == 1040 || e.Number == 1053
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package drivers
+
+import (
+	"database/sql/driver"
+	"net"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+func TestIsConnError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Err  error
+		Want bool
+	}{
+		{driver.ErrBadConn, true},
+		{errors.Wrap(driver.ErrBadConn, "unable to list schemas"), true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{&pq.Error{Code: "57P03"}, true},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "42P01"}, false},
+		{&mysql.MySQLError{Number: 1040}, true},
+		{&mysql.MySQLError{Number: 1146}, false},
+		{errors.New("boom"), false},
+	}
+
+	for i, test := range tests {
+		if got := IsConnError(test.Err); got != test.Want {
+			t.Errorf("%d) want: %t, got: %t (%v)", i, test.Want, got, test.Err)
+		}
+	}
+}
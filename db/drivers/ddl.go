@@ -0,0 +1,863 @@
+package drivers
+
+import (
+	"context"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/pkg/errors"
+	"github.com/vattle/sqlboiler/strmangle"
+)
+
+// DDLFileDriver reads the schema from a file of MySQL CREATE TABLE
+// statements, such as a mysqldump --no-data dump, instead of a live database.
+// Other statements in the file are skipped. As in MySQL, only table level
+// FOREIGN KEY clauses create foreign keys; inline column REFERENCES are
+// ignored.
+type DDLFileDriver struct {
+	path   string
+	tables []ddlTable
+
+	// MySQL translates the column types, the DDL being MySQL's, so its
+	// BoolColumns, JSONAsRawMessage, IgnoreUnsigned and geometry settings
+	// apply to the file's columns as they would to a live database's.
+	MySQL MySQLDriver
+}
+
+// ddlTable is a parsed CREATE TABLE statement.
+type ddlTable struct {
+	name    string
+	columns []db.Column
+	pkey    *db.PrimaryKey
+	fkeys   []db.ForeignKey
+	indexes []db.Index
+	comment string
+	// generated are the generation expressions of generated columns, by
+	// column name, resolved into their GeneratedFrom once every column is
+	// parsed.
+	generated map[string]string
+}
+
+// NewDDLFileDriver returns a driver reading the schema from the DDL file at
+// path. The file is read and parsed by Open.
+func NewDDLFileDriver(path string) *DDLFileDriver {
+	return &DDLFileDriver{path: path}
+}
+
+// Open reads and parses the DDL file.
+func (d *DDLFileDriver) Open() error {
+	b, err := ioutil.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.tables, err = parseDDL(string(b))
+	return errors.Wrapf(err, "unable to parse %s", d.path)
+}
+
+// Close does nothing, there is no connection.
+func (d *DDLFileDriver) Close() {}
+
+// Schemas returns nothing, a DDL file's tables aren't in a schema.
+func (d *DDLFileDriver) Schemas(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// TableNames returns the tables in the order they are created in the file.
+func (d *DDLFileDriver) TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error) {
+	var names []string
+	for _, t := range d.tables {
+		if len(whitelist) > 0 && !strmangle.SetInclude(t.name, whitelist) {
+			continue
+		}
+		names = append(names, t.name)
+	}
+
+	return strmangle.SetComplement(names, blacklist), nil
+}
+
+// Columns returns the columns of a table.
+func (d *DDLFileDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
+	t, err := d.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]db.Column, len(t.columns))
+	for i, c := range t.columns {
+		columns[i] = d.MySQL.forceBool(tableName, c)
+	}
+
+	return columns, nil
+}
+
+// PrimaryKeyInfo returns the primary key of a table, nil if it has none.
+func (d *DDLFileDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*db.PrimaryKey, error) {
+	t, err := d.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.pkey, nil
+}
+
+// ForeignKeyInfo returns the foreign keys of a table, with the columns of
+// composite keys in order.
+func (d *DDLFileDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]db.ForeignKey, error) {
+	t, err := d.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GroupForeignKeys(t.fkeys), nil
+}
+
+// IndexInfo returns the secondary indexes of a table.
+func (d *DDLFileDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]db.Index, error) {
+	t, err := d.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.indexes, nil
+}
+
+// TableComment returns the COMMENT table option of a table.
+func (d *DDLFileDriver) TableComment(ctx context.Context, schema, tableName string) (string, error) {
+	t, err := d.table(tableName)
+	if err != nil {
+		return "", err
+	}
+
+	return t.comment, nil
+}
+
+// TranslateColumnType converts MySQL types to Go types, as the MySQL driver
+// does.
+func (d *DDLFileDriver) TranslateColumnType(c db.Column) db.Column {
+	return d.MySQL.TranslateColumnType(c)
+}
+
+// UseLastInsertID returns true, the DDL being MySQL's.
+func (d *DDLFileDriver) UseLastInsertID() bool {
+	return true
+}
+
+// UseTopClause returns false to indicate MySQL doesnt support SQL TOP clause
+func (d *DDLFileDriver) UseTopClause() bool {
+	return false
+}
+
+// RightQuote is the quoting character for the right side of the identifier
+func (d *DDLFileDriver) RightQuote() byte {
+	return '`'
+}
+
+// LeftQuote is the quoting character for the left side of the identifier
+func (d *DDLFileDriver) LeftQuote() byte {
+	return '`'
+}
+
+// IndexPlaceholders returns false to indicate MySQL doesnt support indexed placeholders
+func (d *DDLFileDriver) IndexPlaceholders() bool {
+	return false
+}
+
+// MaxPlaceholders returns MySQL's limit of 65535
+func (d *DDLFileDriver) MaxPlaceholders() int {
+	return d.MySQL.MaxPlaceholders()
+}
+
+// QuoteLiteral quotes s as a MySQL string literal
+func (d *DDLFileDriver) QuoteLiteral(s string) string {
+	return d.MySQL.QuoteLiteral(s)
+}
+
+// UpsertClause returns MySQL's ON DUPLICATE KEY UPDATE clause
+func (d *DDLFileDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	return d.MySQL.UpsertClause(conflictColumns, updateColumns)
+}
+
+func (d *DDLFileDriver) table(name string) (ddlTable, error) {
+	for _, t := range d.tables {
+		if t.name == name {
+			return t, nil
+		}
+	}
+
+	return ddlTable{}, errors.Errorf("table %s is not in %s", name, d.path)
+}
+
+// ddlToken is a lexical token of a DDL file.
+type ddlToken struct {
+	kind ddlTokenKind
+	text string
+}
+
+type ddlTokenKind int
+
+const (
+	// ddlWord is a keyword or bare identifier.
+	ddlWord ddlTokenKind = iota
+	// ddlQuoted is a `quoted` identifier, text is unquoted.
+	ddlQuoted
+	// ddlString is a 'string' literal, text is unquoted.
+	ddlString
+	// ddlLiteral is a number or b'...'/x'...' literal, text is verbatim.
+	ddlLiteral
+	// ddlPunct is any other single character.
+	ddlPunct
+)
+
+// is reports whether t is the keyword word, in any case.
+func (t ddlToken) is(word string) bool {
+	return t.kind == ddlWord && strings.EqualFold(t.text, word)
+}
+
+// isPunct reports whether t is the punctuation p.
+func (t ddlToken) isPunct(p string) bool {
+	return t.kind == ddlPunct && t.text == p
+}
+
+// name reports whether t can be an identifier.
+func (t ddlToken) name() bool {
+	return t.kind == ddlWord || t.kind == ddlQuoted
+}
+
+// lexDDL splits src into tokens, dropping whitespace and comments.
+func lexDDL(src string) ([]ddlToken, error) {
+	var tokens []ddlToken
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(src[i:], "-- ")):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += end + 4
+		case c == '`':
+			end := i + 1
+			var buf strings.Builder
+			for ; end < len(src); end++ {
+				if src[end] == '`' {
+					if end+1 < len(src) && src[end+1] == '`' {
+						buf.WriteByte('`')
+						end++
+						continue
+					}
+					break
+				}
+				buf.WriteByte(src[end])
+			}
+			if end >= len(src) {
+				return nil, errors.New("unterminated quoted identifier")
+			}
+			tokens = append(tokens, ddlToken{kind: ddlQuoted, text: buf.String()})
+			i = end + 1
+		case c == '\'' || c == '"':
+			text, n, err := lexDDLString(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, ddlToken{kind: ddlString, text: text})
+			i += n
+		case (c == 'b' || c == 'B' || c == 'x' || c == 'X') && i+1 < len(src) && src[i+1] == '\'':
+			end := strings.IndexByte(src[i+2:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated literal")
+			}
+			tokens = append(tokens, ddlToken{kind: ddlLiteral, text: strings.ToLower(src[i:i+1]) + src[i+1:i+end+3]})
+			i += end + 3
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			end := i
+			for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.' || src[end] == 'e' || src[end] == 'E') {
+				end++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlLiteral, text: src[i:end]})
+			i = end
+		case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80:
+			end := i
+			for end < len(src) && (src[end] == '_' || src[end] == '$' || src[end] >= 'a' && src[end] <= 'z' ||
+				src[end] >= 'A' && src[end] <= 'Z' || src[end] >= '0' && src[end] <= '9' || src[end] >= 0x80) {
+				end++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlWord, text: src[i:end]})
+			i = end
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlPunct, text: src[i : i+1]})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// lexDDLString reads the string literal at the start of src, returning its
+// unescaped contents and its length in src.
+func lexDDLString(src string) (string, int, error) {
+	quote := src[0]
+	var buf strings.Builder
+
+	for i := 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case '0':
+				buf.WriteByte(0)
+			default:
+				buf.WriteByte(src[i])
+			}
+		case c == quote && i+1 < len(src) && src[i+1] == quote:
+			buf.WriteByte(quote)
+			i++
+		case c == quote:
+			return buf.String(), i + 1, nil
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return "", 0, errors.New("unterminated string")
+}
+
+// parseDDL parses every CREATE TABLE statement in src.
+func parseDDL(src string) ([]ddlTable, error) {
+	tokens, err := lexDDL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []ddlTable
+	for len(tokens) != 0 {
+		// Split off the next statement.
+		end := 0
+		for end < len(tokens) && !tokens[end].isPunct(";") {
+			end++
+		}
+		stmt := tokens[:end]
+		if end < len(tokens) {
+			end++
+		}
+		tokens = tokens[end:]
+
+		p := &ddlParser{tokens: stmt}
+		if !p.createTable() {
+			continue
+		}
+
+		t, err := p.table()
+		if err != nil {
+			return nil, errors.Wrapf(err, "in create table %s", t.name)
+		}
+		tables = append(tables, t)
+	}
+
+	return tables, nil
+}
+
+// ddlParser walks the tokens of a single statement.
+type ddlParser struct {
+	tokens []ddlToken
+	pos    int
+
+	// unterminated is set when skipParens runs out of tokens.
+	unterminated bool
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.pos >= len(p.tokens) {
+		return ddlToken{kind: ddlPunct}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// accept consumes the keywords words if they come next.
+func (p *ddlParser) accept(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// skipParens consumes a parenthesized group, if one comes next, returning
+// its tokens.
+func (p *ddlParser) skipParens() []ddlToken {
+	if !p.peek().isPunct("(") {
+		return nil
+	}
+
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.tokens) {
+		t := p.next()
+		if t.isPunct("(") {
+			depth++
+		} else if t.isPunct(")") {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+
+	if depth != 0 {
+		p.unterminated = true
+		return p.tokens[start+1:]
+	}
+
+	return p.tokens[start+1 : p.pos-1]
+}
+
+// createTable consumes CREATE [TEMPORARY] TABLE [IF NOT EXISTS], reporting
+// whether the statement is one.
+func (p *ddlParser) createTable() bool {
+	if !p.accept("create") {
+		return false
+	}
+	p.accept("temporary")
+	if !p.accept("table") {
+		return false
+	}
+	p.accept("if", "not", "exists")
+	return true
+}
+
+// qualifiedName consumes a possibly schema qualified name, returning the
+// last part.
+func (p *ddlParser) qualifiedName() (string, error) {
+	t := p.next()
+	if !t.name() {
+		return "", errors.Errorf("expected a name, got %q", t.text)
+	}
+	for p.peek().isPunct(".") {
+		p.next()
+		if t = p.next(); !t.name() {
+			return "", errors.Errorf("expected a name, got %q", t.text)
+		}
+	}
+
+	return t.text, nil
+}
+
+// table parses the rest of a CREATE TABLE statement.
+func (p *ddlParser) table() (ddlTable, error) {
+	var t ddlTable
+	var err error
+
+	if t.name, err = p.qualifiedName(); err != nil {
+		return t, err
+	}
+	if p.accept("like") {
+		return t, errors.New("create table like is not supported")
+	}
+	if !p.peek().isPunct("(") {
+		return t, errors.New("expected a column list")
+	}
+
+	body := p.skipParens()
+	if p.unterminated {
+		return t, errors.New("unterminated column list")
+	}
+
+	var uniques [][]string
+	for _, def := range splitDDLList(body) {
+		if err := t.definition(def, &uniques); err != nil {
+			return t, err
+		}
+	}
+	for i, c := range t.columns {
+		if expr, ok := t.generated[c.Name]; ok {
+			t.columns[i].GeneratedFrom = mysqlGeneratedFrom(expr, t.columns)
+		}
+	}
+
+	// Of the table options, only the comment is kept.
+	for p.pos < len(p.tokens) {
+		if !p.accept("comment") {
+			p.next()
+			continue
+		}
+		if p.peek().isPunct("=") {
+			p.next()
+		}
+		if tok := p.next(); tok.kind == ddlString {
+			t.comment = tok.text
+		}
+	}
+
+	// Primary key columns are implicitly not null, and single column keys
+	// make their column unique.
+	if t.pkey != nil {
+		for i := range t.columns {
+			for _, name := range t.pkey.Columns {
+				if t.columns[i].Name == name {
+					t.columns[i].Nullable = false
+				}
+			}
+		}
+		uniques = append(uniques, t.pkey.Columns)
+	}
+	for _, u := range uniques {
+		if len(u) != 1 {
+			continue
+		}
+		for i := range t.columns {
+			if t.columns[i].Name == u[0] {
+				t.columns[i].Unique = true
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// splitDDLList splits tokens on the commas outside of parentheses.
+func splitDDLList(tokens []ddlToken) [][]ddlToken {
+	var parts [][]ddlToken
+
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+		case t.isPunct(",") && depth == 0:
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+
+	return parts
+}
+
+// definition parses one column or constraint definition of the table.
+func (t *ddlTable) definition(tokens []ddlToken, uniques *[][]string) error {
+	p := &ddlParser{tokens: tokens}
+
+	var constraint string
+	if p.accept("constraint") {
+		if p.peek().name() && !p.peek().is("primary") && !p.peek().is("unique") &&
+			!p.peek().is("foreign") && !p.peek().is("check") {
+			constraint = p.next().text
+		}
+	}
+
+	switch {
+	case p.accept("primary", "key"):
+		t.pkey = &db.PrimaryKey{Name: "PRIMARY", Columns: p.keyColumns()}
+	case p.accept("unique"):
+		if !p.accept("key") {
+			p.accept("index")
+		}
+		name := p.indexName()
+		if len(name) == 0 {
+			name = constraint
+		}
+		columns := p.keyColumns()
+		if len(name) == 0 && len(columns) != 0 {
+			name = columns[0]
+		}
+		*uniques = append(*uniques, columns)
+		t.indexes = append(t.indexes, db.Index{Name: name, Columns: columns, Unique: true, Cardinality: -1})
+	case p.accept("key"), p.accept("index"):
+		name := p.indexName()
+		columns := p.keyColumns()
+		if len(name) == 0 && len(columns) != 0 {
+			name = columns[0]
+		}
+		t.indexes = append(t.indexes, db.Index{Name: name, Columns: columns, Cardinality: -1})
+	case p.accept("fulltext"), p.accept("spatial"), p.accept("check"):
+	case p.accept("foreign", "key"):
+		name := p.indexName()
+		if len(constraint) != 0 {
+			name = constraint
+		}
+		columns := p.keyColumns()
+		if !p.accept("references") {
+			return errors.New("expected references in foreign key")
+		}
+		foreignTable, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		foreignColumns := p.keyColumns()
+		if len(columns) != len(foreignColumns) {
+			return errors.Errorf("foreign key %s has %d columns referencing %d", name, len(columns), len(foreignColumns))
+		}
+		onDelete, onUpdate := p.referentialActions()
+		if len(name) == 0 {
+			name = t.name + "_ibfk_" + strconv.Itoa(len(t.fkeys)+1)
+		}
+		for i := range columns {
+			t.fkeys = append(t.fkeys, db.ForeignKey{
+				Table:         t.name,
+				Name:          name,
+				Column:        columns[i],
+				ForeignTable:  foreignTable,
+				ForeignColumn: foreignColumns[i],
+				OnDelete:      onDelete,
+				OnUpdate:      onUpdate,
+			})
+		}
+	default:
+		return t.column(p, uniques)
+	}
+
+	return nil
+}
+
+// referentialActions consumes the MATCH, ON DELETE and ON UPDATE clauses
+// of a foreign key, returning its actions, empty if not given.
+func (p *ddlParser) referentialActions() (onDelete, onUpdate string) {
+	for {
+		var action *string
+		switch {
+		case p.accept("match"):
+			p.next()
+			continue
+		case p.accept("on", "delete"):
+			action = &onDelete
+		case p.accept("on", "update"):
+			action = &onUpdate
+		default:
+			return onDelete, onUpdate
+		}
+
+		switch {
+		case p.accept("set", "null"):
+			*action = "SET NULL"
+		case p.accept("set", "default"):
+			*action = "SET DEFAULT"
+		case p.accept("no", "action"):
+			*action = "NO ACTION"
+		case p.accept("cascade"):
+			*action = "CASCADE"
+		case p.accept("restrict"):
+			*action = "RESTRICT"
+		default:
+			return onDelete, onUpdate
+		}
+	}
+}
+
+// indexName consumes the optional name of an index, which precedes its
+// column list.
+func (p *ddlParser) indexName() string {
+	if p.peek().name() && !p.peek().is("using") {
+		return p.next().text
+	}
+	return ""
+}
+
+// keyColumns consumes the skipped over (optional) USING clause and the
+// parenthesized list of key parts, returning their column names. Prefix
+// lengths and sort orders are dropped, and expression parts skipped.
+func (p *ddlParser) keyColumns() []string {
+	if p.accept("using") {
+		p.next()
+	}
+
+	var columns []string
+	for _, part := range splitDDLList(p.skipParens()) {
+		if len(part) != 0 && part[0].name() {
+			columns = append(columns, part[0].text)
+		}
+	}
+
+	return columns
+}
+
+// column parses a column definition.
+func (t *ddlTable) column(p *ddlParser, uniques *[][]string) error {
+	name := p.next()
+	if !name.name() {
+		return errors.Errorf("expected a column name, got %q", name.text)
+	}
+
+	typ := p.next()
+	if typ.kind != ddlWord {
+		return errors.Errorf("expected a type for column %s, got %q", name.text, typ.text)
+	}
+
+	c := db.Column{Name: name.text, DBType: strings.ToLower(typ.text), Nullable: true}
+	args := p.skipParens()
+
+	// MySQL reports these aliases as the type they stand for.
+	switch c.DBType {
+	case "bool", "boolean":
+		c.DBType, args = "tinyint", []ddlToken{{kind: ddlLiteral, text: "1"}}
+	case "serial":
+		c.DBType = "bigint"
+		c.Unsigned, c.Nullable, c.AutoIncrement = true, false, true
+		*uniques = append(*uniques, []string{c.Name})
+	case "integer":
+		c.DBType = "int"
+	case "dec", "numeric", "fixed":
+		c.DBType = "decimal"
+	}
+
+	c.FullDBType = c.DBType
+	if args != nil {
+		c.FullDBType += "(" + joinDDLArgs(args) + ")"
+	}
+	if c.DBType == "enum" || c.DBType == "set" {
+		c.DBType = c.FullDBType
+	}
+
+	for p.pos < len(p.tokens) {
+		switch {
+		case p.accept("unsigned"):
+			c.Unsigned = true
+		case p.accept("stored"):
+			c.GeneratedStored = c.Generated
+		case p.accept("signed"), p.accept("zerofill"), p.accept("binary"),
+			p.accept("virtual"), p.accept("invisible"), p.accept("visible"):
+		case p.accept("not", "null"):
+			c.Nullable = false
+		case p.accept("null"):
+			c.Nullable = true
+		case p.accept("auto_increment"):
+			c.AutoIncrement = true
+		case p.accept("primary", "key"), p.accept("key"):
+			t.pkey = &db.PrimaryKey{Name: "PRIMARY", Columns: []string{c.Name}}
+		case p.accept("unique"):
+			p.accept("key")
+			*uniques = append(*uniques, []string{c.Name})
+		case p.accept("default"):
+			c.Default = p.defaultValue()
+		case p.accept("on", "update"):
+			// MySQL only allows CURRENT_TIMESTAMP and its synonyms here.
+			c.AutoUpdateTime = true
+			p.next()
+			p.skipParens()
+		case p.accept("comment"):
+			c.Comment = p.next().text
+		case p.accept("srid"):
+			c.SRID, _ = strconv.Atoi(p.next().text)
+		case p.accept("collate"), p.accept("charset"),
+			p.accept("character", "set"), p.accept("column_format"), p.accept("storage"):
+			p.next()
+		case p.accept("generated", "always"), p.accept("as"):
+			p.accept("as")
+			c.Generated = true
+			if t.generated == nil {
+				t.generated = map[string]string{}
+			}
+			t.generated[c.Name] = joinDDLTokens(p.skipParens(), " ")
+		case p.accept("references"):
+			// Inline references are parsed but ignored, as MySQL does.
+			p.qualifiedName()
+			p.skipParens()
+			for p.accept("match") || p.accept("on", "delete") || p.accept("on", "update") {
+				p.next()
+				if p.peek().is("null") || p.peek().is("action") || p.peek().is("default") {
+					p.next()
+				}
+			}
+		case p.accept("check"):
+			p.skipParens()
+		default:
+			p.next()
+		}
+	}
+
+	if c.Unsigned {
+		c.FullDBType += " unsigned"
+	}
+
+	if c.AutoIncrement {
+		c.Default = "auto_increment"
+	}
+	switch c.DBType {
+	case "bit":
+		c.Default = mysqlBitDefault(c.FullDBType, c.Default)
+	case "date", "datetime", "timestamp":
+		c.ZeroDateDefault = mysqlIsZeroDate(c.Default)
+	}
+
+	t.columns = append(t.columns, c)
+	return nil
+}
+
+// defaultValue consumes a DEFAULT value, returning it the way
+// information_schema.columns.column_default reports it.
+func (p *ddlParser) defaultValue() string {
+	t := p.next()
+	switch {
+	case t.kind == ddlString, t.kind == ddlLiteral:
+		return t.text
+	case t.isPunct("-") || t.isPunct("+"):
+		return strings.TrimPrefix(t.text, "+") + p.next().text
+	case t.is("null"):
+		return ""
+	case t.is("true"):
+		return "1"
+	case t.is("false"):
+		return "0"
+	case t.isPunct("("):
+		p.pos--
+		return "(" + joinDDLArgs(p.skipParens()) + ")"
+	}
+
+	// A function such as CURRENT_TIMESTAMP, with an optional precision.
+	def := strings.ToUpper(t.text)
+	if args := p.skipParens(); args != nil {
+		def += "(" + joinDDLArgs(args) + ")"
+	}
+
+	return def
+}
+
+// joinDDLArgs formats the tokens of a type's arguments, e.g. 10,2 or
+// 'a','b'.
+func joinDDLArgs(tokens []ddlToken) string {
+	return joinDDLTokens(tokens, "")
+}
+
+// joinDDLTokens formats tokens as SQL, separated by sep, requoting strings
+// and quoted identifiers.
+func joinDDLTokens(tokens []ddlToken, sep string) string {
+	var parts []string
+	for _, t := range tokens {
+		switch t.kind {
+		case ddlString:
+			parts = append(parts, "'"+strings.Replace(t.text, "'", "''", -1)+"'")
+		case ddlQuoted:
+			parts = append(parts, "`"+t.text+"`")
+		default:
+			parts = append(parts, t.text)
+		}
+	}
+
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,387 @@
+package drivers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+const testDDL = `
+-- Dumped without data.
+DROP TABLE IF EXISTS users;
+CREATE TABLE users (
+  id int(10) unsigned NOT NULL AUTO_INCREMENT,
+  email varchar(255) NOT NULL COMMENT 'login; unique',
+  name varchar(100) DEFAULT NULL,
+  active tinyint(1) NOT NULL DEFAULT '1',
+  created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY users_email_key (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Registered users';
+
+/*!40101 SET character_set_client = @saved_cs_client */;
+CREATE TABLE IF NOT EXISTS ` + "`posts`" + ` (
+  ` + "`id`" + ` int(10) unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  ` + "`user_id`" + ` int(10) unsigned NOT NULL,
+  ` + "`status`" + ` enum('draft','published') NOT NULL DEFAULT 'draft',
+  ` + "`price`" + ` decimal(10,2),
+  ` + "`flags`" + ` set('pinned','top story') NOT NULL DEFAULT '',
+  ` + "`updated_at`" + ` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  ` + "`location`" + ` point NOT NULL,
+  KEY ` + "`posts_user_id_idx`" + ` (` + "`user_id`" + `),
+  CONSTRAINT ` + "`posts_user_id_fkey`" + ` FOREIGN KEY (` + "`user_id`" + `) REFERENCES ` + "`users`" + ` (` + "`id`" + `) MATCH SIMPLE ON DELETE CASCADE
+);
+`
+
+func TestDDLFileDriver(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "ddl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "schema.sql")
+	if err := ioutil.WriteFile(path, []byte(testDDL), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDDLFileDriver(path)
+	if err := d.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	names, err := d.TableNames(context.Background(), "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"users", "posts"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want tables %v, got %v", want, names)
+	}
+
+	columns, err := d.Columns(context.Background(), "", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantColumns := []db.Column{
+		{Name: "id", DBType: "int", FullDBType: "int(10) unsigned", Unsigned: true, Unique: true, Default: "auto_increment", AutoIncrement: true},
+		{Name: "email", DBType: "varchar", FullDBType: "varchar(255)", Unique: true, Comment: "login; unique"},
+		{Name: "name", DBType: "varchar", FullDBType: "varchar(100)", Nullable: true},
+		{Name: "active", DBType: "tinyint", FullDBType: "tinyint(1)", Default: "1"},
+		{Name: "created_at", DBType: "datetime", FullDBType: "datetime", Default: "CURRENT_TIMESTAMP"},
+	}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Errorf("want columns:\n%#v\ngot:\n%#v", wantColumns, columns)
+	}
+
+	columns, err = d.Columns(context.Background(), "", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := columns[2]; c.DBType != "enum('draft','published')" || c.Default != "draft" {
+		t.Errorf("want status to be an enum defaulting to draft, got %#v", c)
+	}
+	if c := columns[3]; c.FullDBType != "decimal(10,2)" || !c.Nullable {
+		t.Errorf("want a nullable decimal(10,2) price, got %#v", c)
+	}
+	if c := columns[4]; c.DBType != "set('pinned','top story')" {
+		t.Errorf("want flags to be a set, got %#v", c)
+	}
+	if c := columns[5]; !c.AutoUpdateTime || c.Default != "CURRENT_TIMESTAMP" {
+		t.Errorf("want updated_at to auto update, got %#v", c)
+	}
+
+	pkey, err := d.PrimaryKeyInfo(context.Background(), "", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&db.PrimaryKey{Name: "PRIMARY", Columns: []string{"id"}}); !reflect.DeepEqual(pkey, want) {
+		t.Errorf("want pkey %#v, got %#v", want, pkey)
+	}
+
+	indexes, err := d.IndexInfo(context.Background(), "", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []db.Index{{Name: "posts_user_id_idx", Columns: []string{"user_id"}, Cardinality: -1}}; !reflect.DeepEqual(indexes, want) {
+		t.Errorf("want indexes %#v, got %#v", want, indexes)
+	}
+
+	tables, err := db.Tables(context.Background(), d, "", nil, nil, db.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts := db.GetTable(tables, "posts")
+	wantFKeys := []db.ForeignKey{{
+		Table:               "posts",
+		Name:                "posts_user_id_fkey",
+		Column:              "user_id",
+		ForeignTable:        "users",
+		ForeignColumn:       "id",
+		ForeignColumnUnique: true,
+		Columns:             []string{"user_id"},
+		ForeignColumns:      []string{"id"},
+		OnDelete:            "CASCADE",
+		OnUpdate:            "NO ACTION",
+	}}
+	if !reflect.DeepEqual(posts.FKeys, wantFKeys) {
+		t.Errorf("want fkeys:\n%#v\ngot:\n%#v", wantFKeys, posts.FKeys)
+	}
+	if c := posts.GetColumn("user_id"); c.TypeName != "uint" {
+		t.Errorf("want user_id translated to uint, got %s", c.TypeName)
+	}
+	if c := posts.GetColumn("location"); c.TypeName != "[]byte" {
+		t.Errorf("want the location point translated to []byte, got %s", c.TypeName)
+	}
+	if c := posts.GetColumn("flags"); c.TypeName != "Set" || !reflect.DeepEqual(c.EnumValues, []string{"pinned", "top story"}) {
+		t.Errorf("want flags translated to a Set of its members, got %#v", c)
+	}
+
+	users := db.GetTable(tables, "users")
+	if users.Comment != "Registered users" {
+		t.Errorf("want the users table comment, got %q", users.Comment)
+	}
+	if posts.Comment != "" {
+		t.Errorf("want no posts table comment, got %q", posts.Comment)
+	}
+	if len(users.ToManyRelationships) != 1 || users.ToManyRelationships[0].ForeignTable != "posts" {
+		t.Errorf("want users to have many posts, got %#v", users.ToManyRelationships)
+	}
+}
+
+func TestDDLFileDriverTestdata(t *testing.T) {
+	t.Parallel()
+
+	d := NewDDLFileDriver(filepath.Join("..", "..", "testdata", "mysql_test_schema.sql"))
+	if err := d.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Tables(context.Background(), d, "", nil, nil, db.Options{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseDDLSRID(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table places (id int primary key, location point not null srid 4326, area polygon);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tables[0].columns[1].SRID; got != 4326 {
+		t.Errorf("want SRID 4326 on location, got %d", got)
+	}
+	if got := tables[0].columns[2].SRID; got != 0 {
+		t.Errorf("want no SRID on area, got %d", got)
+	}
+}
+
+func TestParseDDLReferentialActions(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table t (a int, b int, foreign key (a) references u (a) on update set null on delete restrict, foreign key (b) references u (b));")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tables[0].fkeys[0]; got.OnDelete != "RESTRICT" || got.OnUpdate != "SET NULL" {
+		t.Errorf("want on delete restrict and on update set null, got %#v", got)
+	}
+	if got := tables[0].fkeys[1]; got.OnDelete != "" || got.OnUpdate != "" {
+		t.Errorf("want no actions given, got %#v", got)
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"create table t (id int",
+		"create table t (id int default 'oops);",
+		"create table t (a int, foreign key (a) references u (a, b));",
+		"create table t like u;",
+	}
+
+	for i, test := range tests {
+		if _, err := parseDDL(test); err == nil {
+			t.Errorf("%d) want an error parsing %q", i, test)
+		}
+	}
+}
+
+func TestDDLFileDriverEnumOrder(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table tasks (id int primary key, priority enum('low','medium','high') not null);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DDLFileDriver{tables: tables}
+	all, err := db.Tables(context.Background(), d, "", nil, nil, db.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := all[0].GetColumn("priority")
+	if want := []string{"low", "medium", "high"}; !reflect.DeepEqual(c.EnumValues, want) {
+		t.Errorf("want enum values in declared order %v, got %v", want, c.EnumValues)
+	}
+	for i, v := range []string{"", "low", "medium", "high"} {
+		if got := c.EnumOrdinal(v); got != i {
+			t.Errorf("want ordinal %d for %q, got %d", i, v, got)
+		}
+	}
+}
+
+func TestDDLFileDriverBit(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table flags (id int primary key, active bit(1) not null default b'1', mask bit(12) default b'100000000001');")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := db.Tables(context.Background(), &DDLFileDriver{tables: tables}, "", nil, nil, db.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name        string
+		WantType    string
+		WantDefault string
+	}{
+		{"active", "bool", "true"},
+		{"mask", "null.Bytes", "[]byte{0x08, 0x01}"},
+	}
+	for i, test := range tests {
+		c := all[0].GetColumn(test.Name)
+		if got := c.GoTypeExpr(); got != test.WantType {
+			t.Errorf("%d) want: %s, got: %s", i, test.WantType, got)
+		}
+		if c.Default != test.WantDefault {
+			t.Errorf("%d) want default: %s, got: %s", i, test.WantDefault, c.Default)
+		}
+	}
+}
+
+func TestDDLFileDriverZeroDate(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table events (id int primary key, starts_on date not null default '0000-00-00', " +
+		"ends_at datetime default '0000-00-00 00:00:00', created_at timestamp not null default current_timestamp);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := db.Tables(context.Background(), &DDLFileDriver{tables: tables}, "", nil, nil, db.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name     string
+		WantZero bool
+		WantExpr string
+	}{
+		{"starts_on", true, "time.Time{}"},
+		{"ends_at", true, "null.Time{}"},
+		{"created_at", false, "time.Time{}"},
+	}
+	for i, test := range tests {
+		c := all[0].GetColumn(test.Name)
+		if c.ZeroDateDefault != test.WantZero {
+			t.Errorf("%d) want zero date default: %t, got: %t", i, test.WantZero, c.ZeroDateDefault)
+		}
+		if test.WantZero && !strings.HasPrefix(c.Default, "0000-00-00") {
+			t.Errorf("%d) want the default kept as SQL, got: %s", i, c.Default)
+		}
+		if got := c.ZeroValueExpr(); got != test.WantExpr {
+			t.Errorf("%d) want: %s, got: %s", i, test.WantExpr, got)
+		}
+	}
+}
+
+func TestDDLFileDriverGenerated(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table people (id int primary key, first_name varchar(50), last_name varchar(50), " +
+		"full_name varchar(101) generated always as (concat(`first_name`, ' ', last_name)) stored, " +
+		"initials char(2) as (left(first_name, 1)) virtual);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := db.Tables(context.Background(), &DDLFileDriver{tables: tables}, "", nil, nil, db.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	people := all[0]
+
+	tests := []struct {
+		Name     string
+		Stored   bool
+		WantFrom []string
+	}{
+		{"full_name", true, []string{"first_name", "last_name"}},
+		{"initials", false, []string{"first_name"}},
+	}
+	for i, test := range tests {
+		c := people.GetColumn(test.Name)
+		if !c.Generated || c.GeneratedStored != test.Stored {
+			t.Errorf("%d) want generated, stored %t, got: %t, %t", i, test.Stored, c.Generated, c.GeneratedStored)
+		}
+		if !reflect.DeepEqual(c.GeneratedFrom, test.WantFrom) {
+			t.Errorf("%d) want: %v, got: %v", i, test.WantFrom, c.GeneratedFrom)
+		}
+	}
+
+	for _, c := range append(people.InsertColumns(), people.UpdateColumns()...) {
+		if c.Generated {
+			t.Errorf("want generated columns left out of inserts and updates, got: %s", c.Name)
+		}
+	}
+}
+
+func TestDDLFileDriverMySQLOptions(t *testing.T) {
+	t.Parallel()
+
+	tables, err := parseDDL("create table places (id int unsigned primary key, open bit(1) not null, area polygon, doc json not null);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DDLFileDriver{tables: tables}
+	d.MySQL.BoolColumns = []string{"places.open"}
+	d.MySQL.IgnoreUnsigned = true
+	d.MySQL.JSONAsRawMessage = true
+	d.MySQL.GeometryPackage = "github.com/paulmach/orb"
+	d.MySQL.GeometryType = "Polygon"
+
+	all, err := db.Tables(context.Background(), d, "", nil, nil, db.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"id": "int", "open": "bool", "area": "*orb.Polygon", "doc": "json.RawMessage"}
+	for name, typ := range want {
+		if got := all[0].GetColumn(name).GoTypeExpr(); got != typ {
+			t.Errorf("%s: want: %s, got: %s", name, typ, got)
+		}
+	}
+	if got := all[0].GetColumn("open").DBType; got != "bit" {
+		t.Errorf("want the bool column's DBType kept, got: %s", got)
+	}
+}
@@ -0,0 +1,32 @@
+package drivers
+
+import "github.com/mickeyreiss/sqlgen/db"
+
+// addIndexPart adds one key part of the named index to indexes, starting a
+// new index when name differs from the last one seen. Rows must be ordered by
+// index name and key position. A part is either a column or, for functional
+// indexes, an expression. cardinality is the estimated number of distinct
+// keys up to and including this part, nil if unknown, so the last part's
+// becomes the index's.
+func addIndexPart(indexes []db.Index, name string, unique bool, column, expression *string, cardinality *int64) []db.Index {
+	if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+		indexes = append(indexes, db.Index{Name: name, Unique: unique})
+	}
+
+	idx := &indexes[len(indexes)-1]
+	idx.Cardinality = -1
+	if cardinality != nil {
+		idx.Cardinality = *cardinality
+	}
+
+	if column != nil && len(*column) != 0 {
+		idx.Columns = append(idx.Columns, *column)
+	} else if expression != nil && len(*expression) != 0 {
+		if len(idx.Expression) != 0 {
+			idx.Expression += ", "
+		}
+		idx.Expression += *expression
+	}
+
+	return indexes
+}
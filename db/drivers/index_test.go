@@ -0,0 +1,34 @@
+package drivers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestAddIndexPart(t *testing.T) {
+	t.Parallel()
+
+	str := func(s string) *string { return &s }
+	num := func(n int64) *int64 { return &n }
+
+	var indexes []db.Index
+	indexes = addIndexPart(indexes, "email_key", true, str("email"), nil, num(1000))
+	indexes = addIndexPart(indexes, "name_idx", false, str("last_name"), nil, num(40))
+	indexes = addIndexPart(indexes, "name_idx", false, str("first_name"), nil, num(350))
+	indexes = addIndexPart(indexes, "tags_idx", false, nil, str("cast(json_extract(`data`,_utf8mb4'$.tags') as char(32) array)"), nil)
+
+	want := []db.Index{
+		{Name: "email_key", Columns: []string{"email"}, Unique: true, Cardinality: 1000},
+		{Name: "name_idx", Columns: []string{"last_name", "first_name"}, Cardinality: 350},
+		{Name: "tags_idx", Expression: "cast(json_extract(`data`,_utf8mb4'$.tags') as char(32) array)", Cardinality: -1},
+	}
+
+	if !reflect.DeepEqual(indexes, want) {
+		t.Errorf("want: %#v\ngot:  %#v", want, indexes)
+	}
+	if !mysqlIsMultiValued(indexes[2].Expression) {
+		t.Error("want the tags index to be flagged multi-valued")
+	}
+}
@@ -1,15 +1,56 @@
 package drivers
 
 import (
+	"context"
+	"sort"
+
 	"github.com/mickeyreiss/sqlgen/db"
 	"github.com/vattle/sqlboiler/strmangle"
 )
 
-// MockDriver is a mock implementation of the db driver Interface
-type MockDriver struct{}
+// MockDriver is a mock implementation of the db driver Interface. It serves a
+// built-in schema of pilots, jets and airports, or the one seeded in
+// MockTables, so the whole generation can run without a database.
+type MockDriver struct {
+	// MockTables seeds the schema with the columns of each table, replacing
+	// the built-in one. MockPKeys and MockFKeys are the primary and foreign
+	// keys of the seeded tables. Seeded tables have no indexes and no row
+	// count estimate.
+	MockTables map[string][]db.Column
+	MockPKeys  map[string]*db.PrimaryKey
+	MockFKeys  map[string][]db.ForeignKey
+
+	// Setting any of these makes the corresponding method fail with the
+	// given error, so callers' error handling can be exercised.
+	FailOnOpen        error
+	FailOnTableNames  error
+	FailOnColumns     error
+	FailOnPrimaryKeys error
+	FailOnForeignKeys error
+	FailOnIndexes     error
+	FailOnDDL         error
+}
+
+// Schemas returns a single mock schema
+func (m *MockDriver) Schemas(ctx context.Context) ([]string, error) {
+	return []string{"public"}, nil
+}
 
 // TableNames returns a list of mock table names
-func (m *MockDriver) TableNames(schema string, whitelist, blacklist []string) ([]string, error) {
+func (m *MockDriver) TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error) {
+	if m.FailOnTableNames != nil {
+		return nil, m.FailOnTableNames
+	}
+	if m.MockTables != nil {
+		var tables []string
+		for name := range m.MockTables {
+			if (len(whitelist) == 0 || strmangle.SetInclude(name, whitelist)) && !strmangle.SetInclude(name, blacklist) {
+				tables = append(tables, name)
+			}
+		}
+		sort.Strings(tables)
+		return tables, nil
+	}
 	if len(whitelist) > 0 {
 		return whitelist, nil
 	}
@@ -18,7 +59,13 @@ func (m *MockDriver) TableNames(schema string, whitelist, blacklist []string) ([
 }
 
 // Columns returns a list of mock columns
-func (m *MockDriver) Columns(schema, tableName string) ([]db.Column, error) {
+func (m *MockDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
+	if m.FailOnColumns != nil {
+		return nil, m.FailOnColumns
+	}
+	if m.MockTables != nil {
+		return m.MockTables[tableName], nil
+	}
 	return map[string][]db.Column{
 		"pilots": {
 			{Name: "id", TypeName: "int", DBType: "integer"},
@@ -59,7 +106,13 @@ func (m *MockDriver) Columns(schema, tableName string) ([]db.Column, error) {
 }
 
 // ForeignKeyInfo returns a list of mock foreignkeys
-func (m *MockDriver) ForeignKeyInfo(schema, tableName string) ([]db.ForeignKey, error) {
+func (m *MockDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]db.ForeignKey, error) {
+	if m.FailOnForeignKeys != nil {
+		return nil, m.FailOnForeignKeys
+	}
+	if m.MockTables != nil {
+		return m.MockFKeys[tableName], nil
+	}
 	return map[string][]db.ForeignKey{
 		"jets": {
 			{Table: "jets", Name: "jets_pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id", ForeignColumnUnique: true},
@@ -75,6 +128,44 @@ func (m *MockDriver) ForeignKeyInfo(schema, tableName string) ([]db.ForeignKey,
 	}[tableName], nil
 }
 
+// RowCountEstimate returns a mock row count estimate
+func (m *MockDriver) RowCountEstimate(ctx context.Context, schema, tableName string) (int64, error) {
+	if m.MockTables != nil {
+		return -1, nil
+	}
+	return map[string]int64{
+		"pilots": 40,
+		"jets":   120,
+	}[tableName], nil
+}
+
+// IndexInfo returns a list of mock indexes
+func (m *MockDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]db.Index, error) {
+	if m.FailOnIndexes != nil {
+		return nil, m.FailOnIndexes
+	}
+	if m.MockTables != nil {
+		return nil, nil
+	}
+	return map[string][]db.Index{
+		"jets": {
+			{Name: "jets_name_idx", Columns: []string{"name"}, Cardinality: 120},
+			{Name: "jets_pilot_id_airport_id_idx", Columns: []string{"pilot_id", "airport_id"}, Cardinality: 480},
+		},
+		"hangars": {
+			{Name: "hangars_name_key", Columns: []string{"name"}, Unique: true, Cardinality: -1},
+		},
+	}[tableName], nil
+}
+
+// CreateStatement returns a mock CREATE TABLE statement
+func (m *MockDriver) CreateStatement(ctx context.Context, schema, tableName string) (string, error) {
+	if m.FailOnDDL != nil {
+		return "", m.FailOnDDL
+	}
+	return "CREATE TABLE " + tableName + " (id integer PRIMARY KEY)", nil
+}
+
 // TranslateColumnType converts a column to its "null." form if it is nullable
 func (m *MockDriver) TranslateColumnType(c db.Column) db.Column {
 	p := &PostgresDriver{}
@@ -82,7 +173,13 @@ func (m *MockDriver) TranslateColumnType(c db.Column) db.Column {
 }
 
 // PrimaryKeyInfo returns mock primary key info for the passed in table name
-func (m *MockDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKey, error) {
+func (m *MockDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*db.PrimaryKey, error) {
+	if m.FailOnPrimaryKeys != nil {
+		return nil, m.FailOnPrimaryKeys
+	}
+	if m.MockTables != nil {
+		return m.MockPKeys[tableName], nil
+	}
 	return map[string]*db.PrimaryKey{
 		"pilots": {
 			Name:    "pilot_id_pkey",
@@ -121,8 +218,8 @@ func (m *MockDriver) UseLastInsertID() bool { return false }
 // UseTopClause returns a database mock SQL TOP clause compatibility flag
 func (m *MockDriver) UseTopClause() bool { return false }
 
-// Open mimics a database open call and returns nil for no error
-func (m *MockDriver) Open() error { return nil }
+// Open mimics a database open call and returns FailOnOpen
+func (m *MockDriver) Open() error { return m.FailOnOpen }
 
 // Close mimics a database close call
 func (m *MockDriver) Close() {}
@@ -141,3 +238,18 @@ func (m *MockDriver) LeftQuote() byte {
 func (m *MockDriver) IndexPlaceholders() bool {
 	return false
 }
+
+// MaxPlaceholders returns a fake placeholder limit
+func (m *MockDriver) MaxPlaceholders() int {
+	return 65535
+}
+
+// QuoteLiteral quotes s like Postgres
+func (m *MockDriver) QuoteLiteral(s string) string {
+	return (&PostgresDriver{}).QuoteLiteral(s)
+}
+
+// UpsertClause returns a fake upsert clause
+func (m *MockDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	return "ON CONFLICT DO NOTHING", nil
+}
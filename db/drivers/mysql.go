@@ -1,14 +1,18 @@
 package drivers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/mickeyreiss/sqlgen/db"
 	"github.com/pkg/errors"
+	"github.com/vattle/sqlboiler/strmangle"
 )
 
 // TinyintAsBool is a global that is set from main.go if a user specifies
@@ -20,24 +24,59 @@ var TinyintAsBool bool
 // MySQLDriver holds the database connection string and a handle
 // to the database connection.
 type MySQLDriver struct {
+	Pool
+	Snapshot
+
 	connStr string
 	dbConn  *sql.DB
+
+	// BoolColumns are table.column names that are generated as bools
+	// whatever their type, overriding the tinyint(1) detection.
+	BoolColumns []string
+	// JSONAsRawMessage maps json columns to encoding/json's RawMessage, and
+	// null.JSON when nullable, rather than the sqlboiler types package.
+	JSONAsRawMessage bool
+	// IgnoreUnsigned maps unsigned integer columns to signed Go types, e.g.
+	// int unsigned to int rather than uint. Column.Unsigned is kept.
+	IgnoreUnsigned bool
+	// GeometryPackage and GeometryType, when GeometryType is set, are the
+	// import path and name of the Go type of geometry columns, such as one
+	// from a geometry library keeping the column's SRID. Nullable columns
+	// are a pointer to it.
+	GeometryPackage string
+	GeometryType    string
 }
 
+// Zero date handling modes for MySQL, see MySQLBuildQueryString.
+const (
+	// MySQLZeroDateParse parses dates into time.Time, so zero dates
+	// (0000-00-00) scan as the zero time.Time. This is the default.
+	MySQLZeroDateParse = "parse"
+	// MySQLZeroDateString leaves dates as text, so zero dates scan as the
+	// string "0000-00-00".
+	MySQLZeroDateString = "string"
+)
+
 // NewMySQLDriver takes the database connection details as parameters and
 // returns a pointer to a MySQLDriver object. Note that it is required to
 // call MySQLDriver.Open() and MySQLDriver.Close() to open and close
 // the database connection once an object has been obtained.
-func NewMySQLDriver(user, pass, dbname, host string, port int, sslmode string) *MySQLDriver {
+func NewMySQLDriver(user, pass, dbname, host string, port int, sslmode, charset, collation, zeroDate string) *MySQLDriver {
 	driver := MySQLDriver{
-		connStr: MySQLBuildQueryString(user, pass, dbname, host, port, sslmode),
+		connStr: MySQLBuildQueryString(user, pass, dbname, host, port, sslmode, charset, collation, zeroDate),
 	}
 
 	return &driver
 }
 
-// MySQLBuildQueryString builds a query string for MySQL.
-func MySQLBuildQueryString(user, pass, dbname, host string, port int, sslmode string) string {
+// MySQLBuildQueryString builds a query string for MySQL. A host starting with
+// a / is the path of a Unix socket to connect over, rather than TCP to
+// host:port, and port is ignored. charset is the connection's character set,
+// empty meaning utf8mb4 so comments read right whatever the database's
+// default. If collation is not empty every connection sets it as its
+// collation_connection. zeroDate is one of the MySQLZeroDate modes, empty
+// meaning MySQLZeroDateParse.
+func MySQLBuildQueryString(user, pass, dbname, host string, port int, sslmode, charset, collation, zeroDate string) string {
 	var config mysql.Config
 
 	config.User = user
@@ -45,19 +84,39 @@ func MySQLBuildQueryString(user, pass, dbname, host string, port int, sslmode st
 		config.Passwd = pass
 	}
 	config.DBName = dbname
-	config.Net = "tcp"
-	config.Addr = host
-	if port == 0 {
-		port = 3306
+	if strings.HasPrefix(host, "/") {
+		config.Net = "unix"
+		config.Addr = host
+	} else {
+		config.Net = "tcp"
+		if port == 0 {
+			port = 3306
+		}
+		config.Addr = host + ":" + strconv.Itoa(port)
 	}
-	config.Addr += ":" + strconv.Itoa(port)
 	config.TLSConfig = sslmode
 
 	// MySQL is a bad, and by default reads date/datetime into a []byte
-	// instead of a time.Time. Tell it to stop being a bad.
-	config.ParseTime = true
+	// instead of a time.Time. Tell it to stop being a bad, unless dates
+	// were asked for as text.
+	config.ParseTime = zeroDate != MySQLZeroDateString
 
-	return config.FormatDSN()
+	// The params are appended, escaped once, rather than left to FormatDSN,
+	// which doesn't escape them in every driver version.
+	if len(charset) == 0 {
+		charset = "utf8mb4"
+	}
+	params := []string{"charset=" + url.QueryEscape(charset)}
+	if len(collation) != 0 {
+		// Unknown DSN params are run as SET statements on each new connection.
+		params = append(params, "collation_connection="+url.QueryEscape("'"+collation+"'"))
+	}
+
+	dsn := config.FormatDSN()
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + strings.Join(params, "&")
+	}
+	return dsn + "?" + strings.Join(params, "&")
 }
 
 // Open opens the database connection using the connection string
@@ -67,15 +126,23 @@ func (m *MySQLDriver) Open() error {
 	if err != nil {
 		return err
 	}
+	m.apply(m.dbConn)
 
-	return nil
+	return m.begin(m.dbConn)
 }
 
 // Close closes the database connection
 func (m *MySQLDriver) Close() {
+	m.commit()
 	m.dbConn.Close()
 }
 
+// conn is what introspection queries run on, the snapshot transaction if
+// there is one.
+func (m *MySQLDriver) conn() queryer {
+	return m.queryer(m.dbConn)
+}
+
 // UseLastInsertID returns false for postgres
 func (m *MySQLDriver) UseLastInsertID() bool {
 	return true
@@ -86,10 +153,51 @@ func (m *MySQLDriver) UseTopClause() bool {
 	return false
 }
 
+// mysqlSystemSchemas are the databases MySQL keeps for itself.
+var mysqlSystemSchemas = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+// Schemas lists the databases on the server, minus MySQL's system schemas.
+func (m *MySQLDriver) Schemas(ctx context.Context) ([]string, error) {
+	var names []string
+
+	rows, err := m.conn().QueryContext(ctx, `select schema_name from information_schema.schemata order by schema_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mysqlUserSchemas(names), nil
+}
+
+// mysqlUserSchemas drops the system schemas from names. They are compared
+// case-insensitively, as MySQL does on case-insensitive file systems.
+func mysqlUserSchemas(names []string) []string {
+	var user []string
+	for _, name := range names {
+		if !strmangle.SetInclude(strings.ToLower(name), mysqlSystemSchemas) {
+			user = append(user, name)
+		}
+	}
+
+	return user
+}
+
 // TableNames connects to the postgres database and
 // retrieves all table names from the information_schema where the
 // table schema is public.
-func (m *MySQLDriver) TableNames(schema string, whitelist, blacklist []string) ([]string, error) {
+func (m *MySQLDriver) TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error) {
 	var names []string
 
 	query := fmt.Sprintf(`select table_name from information_schema.tables where table_schema = ? and table_type = 'BASE TABLE'`)
@@ -106,7 +214,7 @@ func (m *MySQLDriver) TableNames(schema string, whitelist, blacklist []string) (
 		}
 	}
 
-	rows, err := m.dbConn.Query(query, args...)
+	rows, err := m.conn().QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -121,6 +229,10 @@ func (m *MySQLDriver) TableNames(schema string, whitelist, blacklist []string) (
 		names = append(names, name)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return names, nil
 }
 
@@ -128,17 +240,19 @@ func (m *MySQLDriver) TableNames(schema string, whitelist, blacklist []string) (
 // from the database information_schema.columns. It retrieves the column names
 // and column types and returns those as a []Column after TranslateColumnType()
 // converts the SQL types to Go types, for example: "varchar" to "string"
-func (m *MySQLDriver) Columns(schema, tableName string) ([]db.Column, error) {
+func (m *MySQLDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
 	var columns []db.Column
 
-	rows, err := m.dbConn.Query(`
+	rows, err := m.conn().QueryContext(ctx, `
 	select
 	c.column_name,
 	c.column_type,
-	if(c.data_type = 'enum', c.column_type, c.data_type),
+	if(c.data_type in ('enum', 'set'), c.column_type, c.data_type),
 	if(extra = 'auto_increment','auto_increment', c.column_default),
 	c.is_nullable = 'YES',
 	c.column_type LIKE '% unsigned',
+	c.extra,
+	c.column_comment,
 		exists (
 			select c.column_name
 			from information_schema.table_constraints tc
@@ -158,10 +272,10 @@ func (m *MySQLDriver) Columns(schema, tableName string) ([]db.Column, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var colName, colType, colFullType string
+		var colName, colType, colFullType, extra, comment string
 		var nullable, unsigned, unique bool
 		var defaultValue *string
-		if err := rows.Scan(&colName, &colFullType, &colType, &defaultValue, &nullable, &unsigned, &unique); err != nil {
+		if err := rows.Scan(&colName, &colFullType, &colType, &defaultValue, &nullable, &unsigned, &extra, &comment, &unique); err != nil {
 			return nil, errors.Wrapf(err, "unable to scan for table %s", tableName)
 		}
 
@@ -172,20 +286,473 @@ func (m *MySQLDriver) Columns(schema, tableName string) ([]db.Column, error) {
 			Nullable:   nullable,
 			Unsigned:   unsigned,
 			Unique:     unique,
+			Comment:    comment,
+
+			AutoUpdateTime:  mysqlIsAutoUpdateTime(extra),
+			Generated:       mysqlIsGenerated(extra),
+			GeneratedStored: mysqlIsGeneratedStored(extra),
 		}
 
 		if defaultValue != nil && *defaultValue != "NULL" {
 			column.Default = *defaultValue
+			column.AutoIncrement = column.Default == "auto_increment"
+			switch colType {
+			case "bit":
+				column.Default = mysqlBitDefault(colFullType, column.Default)
+			case "date", "datetime", "timestamp":
+				column.ZeroDateDefault = mysqlIsZeroDate(column.Default)
+			}
 		}
 
-		columns = append(columns, column)
+		columns = append(columns, m.forceBool(tableName, column))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Generation expressions take another query, only made for tables with
+	// generated columns.
+	for _, c := range columns {
+		if !c.Generated {
+			continue
+		}
+
+		exprs, err := m.generationExpressions(ctx, schema, tableName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range columns {
+			if expr, ok := exprs[columns[i].Name]; ok && columns[i].Generated {
+				columns[i].GeneratedFrom = mysqlGeneratedFrom(expr, columns)
+			}
+		}
+		break
+	}
+
+	// SRIDs take another query, only made for tables with geometry.
+	for _, c := range columns {
+		if !mysqlIsSpatial(c.DBType) {
+			continue
+		}
+
+		srids, err := m.spatialSRIDs(ctx, schema, tableName)
+		if err != nil {
+			return nil, err
+		}
+		for i := range columns {
+			columns[i].SRID = srids[columns[i].Name]
+		}
+		break
 	}
 
 	return columns, nil
 }
 
+// mysqlSpatialTypes are the data types of MySQL geometry columns.
+var mysqlSpatialTypes = []string{
+	"geometry", "point", "linestring", "polygon",
+	"multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection",
+}
+
+// mysqlIsSpatial reports whether dbType is a geometry type.
+func mysqlIsSpatial(dbType string) bool {
+	return strmangle.SetInclude(dbType, mysqlSpatialTypes)
+}
+
+// spatialSRIDs returns the SRID attribute (MySQL 8.0+) of the columns of a
+// table that have one. Older servers have no SRIDs to report.
+func (m *MySQLDriver) spatialSRIDs(ctx context.Context, schema, tableName string) (map[string]int, error) {
+	srids := map[string]int{}
+
+	rows, err := m.conn().QueryContext(ctx, `
+	select column_name, srs_id
+	from information_schema.columns
+	where table_schema = ? and table_name = ? and srs_id is not null`, schema, tableName)
+	if merr, ok := err.(*mysql.MySQLError); ok && merr.Number == 1054 {
+		return srids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var srid int
+		if err := rows.Scan(&name, &srid); err != nil {
+			return nil, err
+		}
+		srids[name] = srid
+	}
+
+	return srids, rows.Err()
+}
+
+// generationExpressions returns the expressions of the generated columns of a
+// table (MySQL 5.7+). Older servers have no generated columns.
+func (m *MySQLDriver) generationExpressions(ctx context.Context, schema, tableName string) (map[string]string, error) {
+	exprs := map[string]string{}
+
+	rows, err := m.conn().QueryContext(ctx, `
+	select column_name, generation_expression
+	from information_schema.columns
+	where table_schema = ? and table_name = ? and generation_expression <> ''`, schema, tableName)
+	if merr, ok := err.(*mysql.MySQLError); ok && merr.Number == 1054 {
+		return exprs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, expr string
+		if err := rows.Scan(&name, &expr); err != nil {
+			return nil, err
+		}
+		exprs[name] = expr
+	}
+
+	return exprs, rows.Err()
+}
+
+// mysqlIsAutoUpdateTime reports whether a column's extra information marks
+// it ON UPDATE CURRENT_TIMESTAMP, e.g. "on update CURRENT_TIMESTAMP(3)" or,
+// from MySQL 8.0, "DEFAULT_GENERATED on update CURRENT_TIMESTAMP".
+func mysqlIsAutoUpdateTime(extra string) bool {
+	return strings.Contains(strings.ToLower(extra), "on update current_timestamp")
+}
+
+// mysqlIsGenerated reports whether a column's extra information marks it a
+// generated column, "VIRTUAL GENERATED" or "STORED GENERATED". Servers
+// older than 5.7 have no generated columns, so never mark one.
+// "DEFAULT_GENERATED" is a default expression, not a generated column.
+func mysqlIsGenerated(extra string) bool {
+	extra = strings.ToLower(extra)
+	return strings.Contains(extra, "virtual generated") || strings.Contains(extra, "stored generated")
+}
+
+// mysqlIsGeneratedStored reports whether a column's extra information marks
+// it a STORED generated column, rather than a VIRTUAL one.
+func mysqlIsGeneratedStored(extra string) bool {
+	return strings.Contains(strings.ToLower(extra), "stored generated")
+}
+
+// mysqlGeneratedFrom returns the columns the generation expression expr
+// refers to, e.g. first_name and last_name for
+// concat(`first_name`,_utf8mb4\' \',`last_name`). Identifiers that aren't
+// columns, like function names, are ignored. An expression that can't be
+// tokenized, such as one with an unterminated quote, refers to none.
+func mysqlGeneratedFrom(expr string, columns []db.Column) []string {
+	var from []string
+	ok := mysqlScanIdentifiers(expr, func(name string, quoted bool) {
+		for _, c := range columns {
+			if strings.EqualFold(c.Name, name) && !strmangle.SetInclude(c.Name, from) {
+				from = append(from, c.Name)
+			}
+		}
+	})
+	if !ok {
+		return nil
+	}
+
+	return from
+}
+
+// mysqlScanIdentifiers calls fn with each identifier of the expression expr
+// from information_schema that may be a column, in order: backquoted ones,
+// and bare ones other than function names and charset introducers. It
+// returns false if expr can't be tokenized, such as with an unterminated
+// quote.
+func mysqlScanIdentifiers(expr string, fn func(name string, quoted bool)) bool {
+	// information_schema escapes the quotes of string literals.
+	expr = strings.Replace(expr, `\'`, "'", -1)
+
+	for i := 0; i < len(expr); {
+		switch ch := expr[i]; {
+		case ch == '\'' || ch == '"' || ch == '`':
+			// Quotes are escaped by doubling them.
+			j := i + 1
+			var quoted []byte
+			for ; j < len(expr); j++ {
+				if expr[j] == ch {
+					if j+1 < len(expr) && expr[j+1] == ch {
+						quoted = append(quoted, ch)
+						j++
+						continue
+					}
+					break
+				}
+				quoted = append(quoted, expr[j])
+			}
+			if j == len(expr) {
+				return false
+			}
+			if ch == '`' {
+				fn(string(quoted), true)
+			}
+			i = j + 1
+		case mysqlIsIdentByte(ch):
+			j := i
+			for j < len(expr) && mysqlIsIdentByte(expr[j]) {
+				j++
+			}
+			if rest := strings.TrimLeft(expr[j:], " "); !strings.HasPrefix(rest, "(") && expr[i] != '_' {
+				fn(expr[i:j], false)
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return true
+}
+
+// mysqlIsIdentByte reports whether b can be part of an unquoted identifier,
+// counting every byte of a multibyte character.
+func mysqlIsIdentByte(b byte) bool {
+	return b == '_' || b == '$' || b >= 0x80 || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+// CheckConstraints retrieves the CHECK constraints (MySQL 8.0.16+) for a
+// given table name. Older servers have no checks to report.
+func (m *MySQLDriver) CheckConstraints(ctx context.Context, schema, tableName string) ([]db.CheckConstraint, error) {
+	var checks []db.CheckConstraint
+
+	query := `
+	select cc.constraint_name, cc.check_clause
+	from information_schema.check_constraints as cc
+	inner join information_schema.table_constraints as tc
+		on tc.constraint_schema = cc.constraint_schema and tc.constraint_name = cc.constraint_name
+	where tc.table_schema = ? and tc.table_name = ? and tc.constraint_type = 'CHECK'
+	order by cc.constraint_name`
+
+	rows, err := m.conn().QueryContext(ctx, query, schema, tableName)
+	if merr, ok := err.(*mysql.MySQLError); ok && merr.Number == 1109 {
+		// information_schema.check_constraints is unknown before 8.0.16.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var check db.CheckConstraint
+		if err := rows.Scan(&check.Name, &check.Expression); err != nil {
+			return nil, err
+		}
+
+		check.Column, check.Check = mysqlParseCheck(check.Expression)
+		// Check clauses always backquote their columns.
+		mysqlScanIdentifiers(check.Expression, func(name string, quoted bool) {
+			if quoted && !strmangle.SetInclude(name, check.Columns) {
+				check.Columns = append(check.Columns, name)
+			}
+		})
+		checks = append(checks, check)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+const mysqlLiteral = `-?[0-9]+(?:\.[0-9]+)?|(?:_[a-z0-9]+)?'(?:[^']|'')*'`
+
+var (
+	rgxCheckAnd     = regexp.MustCompile(`(?i)\s+and\s+`)
+	rgxCheckRange   = regexp.MustCompile("(?i)^`?([a-z0-9_$]+)`?\\s*(>=|>|<=|<)\\s*(" + mysqlLiteral + ")$")
+	rgxCheckIn      = regexp.MustCompile("(?i)^`?([a-z0-9_$]+)`?\\s+in\\s*\\((.+)\\)$")
+	rgxCheckLiteral = regexp.MustCompile("(?i)^(?:" + mysqlLiteral + ")$")
+)
+
+// mysqlParseCheck parses a check clause made of range comparisons against
+// literals, or an IN list of literals, over a single column, e.g.
+// ((`age` >= 0) and (`age` < 150)). It returns a nil check for anything more
+// complex.
+func mysqlParseCheck(expression string) (column string, check *db.ColumnCheck) {
+	check = &db.ColumnCheck{}
+
+	for _, part := range rgxCheckAnd.Split(trimParens(expression), -1) {
+		part = trimParens(part)
+
+		var name string
+		if match := rgxCheckRange.FindStringSubmatch(part); match != nil {
+			name = match[1]
+			value := mysqlTrimIntroducer(match[3])
+			switch match[2] {
+			case ">=", ">":
+				check.Min, check.MinExclusive = value, match[2] == ">"
+			case "<=", "<":
+				check.Max, check.MaxExclusive = value, match[2] == "<"
+			}
+		} else if match := rgxCheckIn.FindStringSubmatch(part); match != nil {
+			name = match[1]
+			for _, value := range strings.Split(match[2], ",") {
+				value = strings.TrimSpace(value)
+				if !rgxCheckLiteral.MatchString(value) {
+					return "", nil
+				}
+				check.In = append(check.In, mysqlTrimIntroducer(value))
+			}
+		} else {
+			return "", nil
+		}
+
+		if len(column) != 0 && !strings.EqualFold(column, name) {
+			return "", nil
+		}
+		column = name
+	}
+
+	return column, check
+}
+
+// trimParens removes whitespace and any parentheses that enclose all of s.
+func trimParens(s string) string {
+	for {
+		s = strings.TrimSpace(s)
+		if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+			return s
+		}
+
+		// The opening paren must be closed by the last character, not
+		// earlier as in (a) and (b).
+		depth := 0
+		for i := 0; i < len(s); i++ {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 && i != len(s)-1 {
+				return s
+			}
+		}
+
+		s = s[1 : len(s)-1]
+	}
+}
+
+// mysqlTrimIntroducer drops the character set introducer MySQL adds to
+// string literals in stored expressions, e.g. _utf8mb4'active'.
+func mysqlTrimIntroducer(literal string) string {
+	if strings.HasPrefix(literal, "_") {
+		if i := strings.IndexByte(literal, '\''); i > 0 {
+			return literal[i:]
+		}
+	}
+
+	return literal
+}
+
+// CreateStatement returns the SHOW CREATE TABLE output for a table. It also
+// works for views, whose result has the CREATE VIEW statement in the same
+// position but extra columns after it.
+func (m *MySQLDriver) CreateStatement(ctx context.Context, schema, tableName string) (string, error) {
+	rows, err := m.conn().QueryContext(ctx, "show create table "+mysqlQuoteIdent(schema)+"."+mysqlQuoteIdent(tableName))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if len(cols) < 2 {
+		return "", errors.Errorf("unexpected show create table result for %s: %v", tableName, cols)
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", errors.Errorf("no create statement for %s", tableName)
+	}
+
+	vals := make([]sql.RawBytes, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return "", err
+	}
+
+	return string(vals[1]), nil
+}
+
+// forceBool sets ForceBool on c if it is listed in BoolColumns, for
+// TranslateColumnType to make it a bool.
+func (m *MySQLDriver) forceBool(tableName string, c db.Column) db.Column {
+	name := tableName + "." + c.Name
+	for _, b := range m.BoolColumns {
+		if b == name {
+			c.ForceBool = true
+			break
+		}
+	}
+
+	return c
+}
+
+// mysqlBitDefault converts a bit column default from MySQL's b'...' form into
+// a Go literal: true or false for bit(1), and a big-endian []byte literal for
+// wider columns. Defaults that can't be parsed are returned unchanged.
+func mysqlBitDefault(fullType, def string) string {
+	if !strings.HasPrefix(def, "b'") || !strings.HasSuffix(def, "'") {
+		return def
+	}
+
+	val, err := strconv.ParseUint(def[2:len(def)-1], 2, 64)
+	if err != nil {
+		return def
+	}
+
+	width, ok := mysqlBitWidth(fullType)
+	if !ok {
+		return def
+	}
+
+	if width == 1 {
+		return strconv.FormatBool(val == 1)
+	}
+
+	byteVals := make([]string, (width+7)/8)
+	for i := len(byteVals) - 1; i >= 0; i-- {
+		byteVals[i] = fmt.Sprintf("0x%02x", byte(val))
+		val >>= 8
+	}
+
+	return "[]byte{" + strings.Join(byteVals, ", ") + "}"
+}
+
+// mysqlBitWidth is the width in bits of a bit column's full type, e.g. 8
+// for bit(8), and 1 for plain bit.
+func mysqlBitWidth(fullType string) (int, bool) {
+	i := strings.IndexByte(fullType, '(')
+	if i < 0 || !strings.HasSuffix(fullType, ")") {
+		return 1, true
+	}
+
+	width, err := strconv.Atoi(fullType[i+1 : len(fullType)-1])
+	return width, err == nil
+}
+
+// mysqlIsZeroDate reports whether a date or time default is the zero date
+// (0000-00-00), optionally with a zero time.
+func mysqlIsZeroDate(def string) bool {
+	return strings.HasPrefix(def, "0000-00-00") && strings.Trim(def, "0-:. ") == ""
+}
+
 // PrimaryKeyInfo looks up the primary key for a table.
-func (m *MySQLDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKey, error) {
+func (m *MySQLDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*db.PrimaryKey, error) {
 	pkey := &db.PrimaryKey{}
 	var err error
 
@@ -194,7 +761,7 @@ func (m *MySQLDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKey,
 	from information_schema.table_constraints as tc
 	where tc.table_name = ? and tc.constraint_type = 'PRIMARY KEY' and tc.table_schema = ?;`
 
-	row := m.dbConn.QueryRow(query, tableName, schema)
+	row := m.conn().QueryRowContext(ctx, query, tableName, schema)
 	if err = row.Scan(&pkey.Name); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -208,7 +775,7 @@ func (m *MySQLDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKey,
 	where  table_name = ? and constraint_name = ? and table_schema = ?;`
 
 	var rows *sql.Rows
-	if rows, err = m.dbConn.Query(queryColumns, tableName, pkey.Name, schema); err != nil {
+	if rows, err = m.conn().QueryContext(ctx, queryColumns, tableName, pkey.Name, schema); err != nil {
 		return nil, err
 	}
 	defer rows.Close()
@@ -234,28 +801,34 @@ func (m *MySQLDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKey,
 	return pkey, nil
 }
 
-// ForeignKeyInfo retrieves the foreign keys for a given table name.
-func (m *MySQLDriver) ForeignKeyInfo(schema, tableName string) ([]db.ForeignKey, error) {
+// ForeignKeyInfo retrieves the foreign keys for a given table name, with the
+// columns of composite keys in order.
+func (m *MySQLDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]db.ForeignKey, error) {
 	var fkeys []db.ForeignKey
 
 	query := `
-	select constraint_name, table_name, column_name, referenced_table_name, referenced_column_name
-	from information_schema.key_column_usage
-	where table_schema = ? and referenced_table_schema = ? and table_name = ?
+	select kcu.constraint_name, kcu.table_name, kcu.column_name, kcu.referenced_table_name, kcu.referenced_column_name,
+		rc.delete_rule, rc.update_rule
+	from information_schema.key_column_usage kcu
+		inner join information_schema.referential_constraints rc
+			on rc.constraint_schema = kcu.table_schema and rc.constraint_name = kcu.constraint_name and rc.table_name = kcu.table_name
+	where kcu.table_schema = ? and kcu.referenced_table_schema = ? and kcu.table_name = ?
+	order by kcu.constraint_name, kcu.ordinal_position
 	`
 
 	var rows *sql.Rows
 	var err error
-	if rows, err = m.dbConn.Query(query, schema, schema, tableName); err != nil {
+	if rows, err = m.conn().QueryContext(ctx, query, schema, schema, tableName); err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var fkey db.ForeignKey
 		var sourceTable string
 
 		fkey.Table = tableName
-		err = rows.Scan(&fkey.Name, &sourceTable, &fkey.Column, &fkey.ForeignTable, &fkey.ForeignColumn)
+		err = rows.Scan(&fkey.Name, &sourceTable, &fkey.Column, &fkey.ForeignTable, &fkey.ForeignColumn, &fkey.OnDelete, &fkey.OnUpdate)
 		if err != nil {
 			return nil, err
 		}
@@ -267,13 +840,149 @@ func (m *MySQLDriver) ForeignKeyInfo(schema, tableName string) ([]db.ForeignKey,
 		return nil, err
 	}
 
-	return fkeys, nil
+	return db.GroupForeignKeys(fkeys), nil
+}
+
+// rgxMultiValued matches the CAST(... AS ... ARRAY) key of a multi-valued index.
+var rgxMultiValued = regexp.MustCompile(`(?i)\bas\s+[a-z0-9_() ]+\s+array\s*\)`)
+
+// TableComment returns the comment of a table, empty for views, whose
+// table_comment is the word VIEW.
+func (m *MySQLDriver) TableComment(ctx context.Context, schema, tableName string) (string, error) {
+	var comment string
+
+	query := `
+	select if(table_type = 'VIEW', '', table_comment)
+	from information_schema.tables
+	where table_schema = ? and table_name = ?`
+
+	if err := m.conn().QueryRowContext(ctx, query, schema, tableName).Scan(&comment); err != nil {
+		return "", err
+	}
+
+	return comment, nil
+}
+
+// RowCountEstimate returns information_schema's table_rows for a table, or
+// -1 for views, which have none. For InnoDB tables it is an estimate that
+// is refreshed by ANALYZE TABLE and may be far from the actual count.
+func (m *MySQLDriver) RowCountEstimate(ctx context.Context, schema, tableName string) (int64, error) {
+	var rows sql.NullInt64
+
+	query := `
+	select table_rows
+	from information_schema.tables
+	where table_schema = ? and table_name = ?`
+
+	if err := m.conn().QueryRowContext(ctx, query, schema, tableName).Scan(&rows); err != nil {
+		return 0, err
+	}
+
+	if !rows.Valid {
+		return -1, nil
+	}
+
+	return rows.Int64, nil
+}
+
+// IndexInfo retrieves the secondary indexes for a given table name, with
+// their key parts in order. Functional key parts (MySQL 8.0.13+) are recorded
+// as the index Expression.
+func (m *MySQLDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]db.Index, error) {
+	var indexes []db.Index
+
+	query := `
+	select index_name, non_unique = 0, column_name, %s, cardinality
+	from information_schema.statistics
+	where table_schema = ? and table_name = ? and index_name <> 'PRIMARY'
+	order by index_name, seq_in_index`
+
+	rows, err := m.conn().QueryContext(ctx, fmt.Sprintf(query, "expression"), schema, tableName)
+	if merr, ok := err.(*mysql.MySQLError); ok && merr.Number == 1054 {
+		// statistics.expression doesn't exist before functional indexes did.
+		rows, err = m.conn().QueryContext(ctx, fmt.Sprintf(query, "null"), schema, tableName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var unique bool
+		var column, expression *string
+		var cardinality *int64
+		if err := rows.Scan(&name, &unique, &column, &expression, &cardinality); err != nil {
+			return nil, err
+		}
+
+		indexes = addIndexPart(indexes, name, unique, column, expression, cardinality)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		indexes[i].MultiValued = mysqlIsMultiValued(indexes[i].Expression)
+	}
+
+	return indexes, nil
+}
+
+// mysqlIsMultiValued reports whether a functional index expression is the
+// key of a multi-valued index, e.g. cast(json_extract(data, '$.tags') as
+// unsigned array).
+func mysqlIsMultiValued(expression string) bool {
+	return rgxMultiValued.MatchString(expression)
 }
 
 // TranslateColumnType converts postgres database types to Go types, for example
 // "varchar" to "string" and "bigint" to "int64". It returns this parsed data
 // as a Column object.
 func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
+	if mysqlIsSpatial(c.DBType) {
+		// Geometry is read in MySQL's internal format, an SRID followed by
+		// WKB, which only survives as bytes without a configured type.
+		switch {
+		case len(m.GeometryType) != 0:
+			c.PkgName = m.GeometryPackage
+			c.TypeName = m.GeometryType
+			if c.Nullable {
+				c.TypeName = "*" + m.GeometryType
+			}
+		case c.Nullable:
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Bytes"
+		default:
+			c.TypeName = "[]byte"
+		}
+		return c
+	}
+
+	// Set columns' DBType holds their members, e.g. set('a','b').
+	if strings.HasPrefix(c.DBType, "set(") {
+		c.PkgName = "github.com/mickeyreiss/sqlgen/types"
+		if c.Nullable {
+			c.TypeName = "NullSet"
+		} else {
+			c.TypeName = "Set"
+		}
+		return c
+	}
+
+	if c.ForceBool {
+		if c.Nullable {
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Bool"
+		} else {
+			c.TypeName = "bool"
+		}
+		return c
+	}
+
+	unsigned := c.Unsigned && !m.IgnoreUnsigned
+
 	if c.Nullable {
 		switch c.DBType {
 		case "tinyint":
@@ -282,7 +991,7 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 				c.PkgName = "gopkg.in/nullbio/null.v6"
 				c.TypeName = "Bool"
 			} else {
-				if c.Unsigned {
+				if unsigned {
 					c.PkgName = "gopkg.in/nullbio/null.v6"
 					c.TypeName = "Uint8"
 				} else {
@@ -291,7 +1000,7 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 				}
 			}
 		case "smallint":
-			if c.Unsigned {
+			if unsigned {
 				c.PkgName = "gopkg.in/nullbio/null.v6"
 				c.TypeName = "Uint16"
 			} else {
@@ -299,7 +1008,7 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 				c.TypeName = "Int16"
 			}
 		case "mediumint":
-			if c.Unsigned {
+			if unsigned {
 				c.PkgName = "gopkg.in/nullbio/null.v6"
 				c.TypeName = "Uint32"
 			} else {
@@ -307,7 +1016,7 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 				c.TypeName = "Int32"
 			}
 		case "int", "integer":
-			if c.Unsigned {
+			if unsigned {
 				c.PkgName = "gopkg.in/nullbio/null.v6"
 				c.TypeName = "Uint"
 			} else {
@@ -315,7 +1024,7 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 				c.TypeName = "Int"
 			}
 		case "bigint":
-			if c.Unsigned {
+			if unsigned {
 				c.PkgName = "gopkg.in/nullbio/null.v6"
 				c.TypeName = "Uint64"
 			} else {
@@ -334,11 +1043,27 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 		case "date", "datetime", "timestamp", "time":
 			c.PkgName = "gopkg.in/nullbio/null.v6"
 			c.TypeName = "Time"
+		case "bit":
+			// bit(1) is a flag, and wider bit strings are big-endian bytes,
+			// matching their defaults (see mysqlBitDefault).
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			if width, _ := mysqlBitWidth(c.FullDBType); width == 1 {
+				c.TypeName = "Bool"
+			} else {
+				c.TypeName = "Bytes"
+			}
 		case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
 			c.PkgName = "gopkg.in/nullbio/null.v6"
 			c.TypeName = "Bytes"
+		case "decimal", "numeric", "dec", "fixed":
+			c.PkgName = "github.com/mickeyreiss/sqlgen/types"
+			c.TypeName = "NullDecimal"
 		case "json":
-			c.PkgName = "github.com/vattle/sqlboiler/types"
+			if m.JSONAsRawMessage {
+				c.PkgName = "gopkg.in/nullbio/null.v6"
+			} else {
+				c.PkgName = "github.com/mickeyreiss/sqlgen/types"
+			}
 			c.TypeName = "JSON"
 		default:
 			c.PkgName = "gopkg.in/nullbio/null.v6"
@@ -351,32 +1076,32 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 			if TinyintAsBool && c.FullDBType == "tinyint(1)" {
 				c.TypeName = "bool"
 			} else {
-				if c.Unsigned {
+				if unsigned {
 					c.TypeName = "uint8"
 				} else {
 					c.TypeName = "int8"
 				}
 			}
 		case "smallint":
-			if c.Unsigned {
+			if unsigned {
 				c.TypeName = "uint16"
 			} else {
 				c.TypeName = "int16"
 			}
 		case "mediumint":
-			if c.Unsigned {
+			if unsigned {
 				c.TypeName = "uint32"
 			} else {
 				c.TypeName = "int32"
 			}
 		case "int", "integer":
-			if c.Unsigned {
+			if unsigned {
 				c.TypeName = "uint"
 			} else {
 				c.TypeName = "int"
 			}
 		case "bigint":
-			if c.Unsigned {
+			if unsigned {
 				c.TypeName = "uint64"
 			} else {
 				c.TypeName = "int64"
@@ -390,11 +1115,26 @@ func (m *MySQLDriver) TranslateColumnType(c db.Column) db.Column {
 		case "date", "datetime", "timestamp", "time":
 			c.PkgName = "time"
 			c.TypeName = "Time"
+		case "bit":
+			if width, _ := mysqlBitWidth(c.FullDBType); width == 1 {
+				c.TypeName = "bool"
+			} else {
+				c.TypeName = "[]byte"
+			}
 		case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
 			c.TypeName = "[]byte"
+		case "decimal", "numeric", "dec", "fixed":
+			// Unsigned only forbids negatives, it's still a decimal.
+			c.PkgName = "github.com/mickeyreiss/sqlgen/types"
+			c.TypeName = "Decimal"
 		case "json":
-			c.PkgName = "github.com/vattle/sqlboiler/types"
-			c.TypeName = "JSON"
+			if m.JSONAsRawMessage {
+				c.PkgName = "encoding/json"
+				c.TypeName = "RawMessage"
+			} else {
+				c.PkgName = "github.com/mickeyreiss/sqlgen/types"
+				c.TypeName = "JSON"
+			}
 		default:
 			c.TypeName = "string"
 		}
@@ -417,3 +1157,52 @@ func (m *MySQLDriver) LeftQuote() byte {
 func (m *MySQLDriver) IndexPlaceholders() bool {
 	return false
 }
+
+// MaxPlaceholders returns 65535, the most placeholders MySQL allows in a
+// prepared statement. Large rows can still exceed max_allowed_packet first.
+func (m *MySQLDriver) MaxPlaceholders() int {
+	return 65535
+}
+
+// mysqlLiteralEscaper escapes what mysql_real_escape_string does.
+var mysqlLiteralEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"'", `\'`,
+	`"`, `\"`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+// QuoteLiteral quotes s with single quotes, backslash escaping quotes,
+// backslashes and the control characters mysql_real_escape_string does. It
+// isn't safe on servers in NO_BACKSLASH_ESCAPES mode.
+func (m *MySQLDriver) QuoteLiteral(s string) string {
+	return "'" + mysqlLiteralEscaper.Replace(s) + "'"
+}
+
+// UpsertClause returns an ON DUPLICATE KEY UPDATE clause. MySQL upserts on
+// any unique key, so conflictColumns are only used with no updateColumns,
+// to update the first of them to itself: MySQL has no DO NOTHING, and
+// without either it's an error.
+func (m *MySQLDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	var sets []string
+	for _, c := range updateColumns {
+		sets = append(sets, mysqlQuoteIdent(c)+" = VALUES("+mysqlQuoteIdent(c)+")")
+	}
+	if len(sets) == 0 {
+		if len(conflictColumns) == 0 {
+			return "", errors.New("mysql needs a conflict or update column to upsert, having no DO NOTHING")
+		}
+		sets = append(sets, mysqlQuoteIdent(conflictColumns[0])+" = "+mysqlQuoteIdent(conflictColumns[0]))
+	}
+
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "), nil
+}
+
+// mysqlQuoteIdent quotes name as an identifier, doubling the backticks in
+// it.
+func mysqlQuoteIdent(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
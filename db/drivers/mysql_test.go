@@ -0,0 +1,583 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestMySQLBuildQueryStringCollation(t *testing.T) {
+	t.Parallel()
+
+	dsn := MySQLBuildQueryString("user", "pass", "dbname", "localhost", 3306, "false", "", "utf8mb4_bin", "")
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := config.Params["collation_connection"]; got != "'utf8mb4_bin'" {
+		t.Errorf("want the session collation set, got: %q", got)
+	}
+
+	dsn = MySQLBuildQueryString("user", "pass", "dbname", "localhost", 3306, "false", "", "", "")
+	if config, err = mysql.ParseDSN(dsn); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := config.Params["collation_connection"]; ok {
+		t.Error("want no session collation by default")
+	}
+}
+
+func TestMySQLBuildQueryStringCharset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Charset   string
+		Collation string
+		Want      []string
+	}{
+		{"", "", []string{"charset=utf8mb4"}},
+		{"latin1", "", []string{"charset=latin1"}},
+		{"utf8mb4", "utf8mb4 bin", []string{"charset=utf8mb4", "collation_connection=%27utf8mb4+bin%27"}},
+	}
+
+	for i, test := range tests {
+		dsn := MySQLBuildQueryString("user", "pass", "dbname", "localhost", 3306, "false", test.Charset, test.Collation, "")
+		for _, want := range test.Want {
+			if !strings.Contains(dsn, want) {
+				t.Errorf("%d) want %s in the dsn, got: %s", i, want, dsn)
+			}
+		}
+	}
+}
+
+func TestMySQLBuildQueryStringSocket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Host string
+		Port int
+		Net  string
+		Addr string
+	}{
+		{"localhost", 3306, "tcp", "localhost:3306"},
+		{"db.internal", 0, "tcp", "db.internal:3306"},
+		{"/var/run/mysqld/mysqld.sock", 3306, "unix", "/var/run/mysqld/mysqld.sock"},
+	}
+
+	for i, test := range tests {
+		config, err := mysql.ParseDSN(MySQLBuildQueryString("user", "pass", "dbname", test.Host, test.Port, "false", "", "", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Net != test.Net || config.Addr != test.Addr {
+			t.Errorf("%d) want: %s(%s), got: %s(%s)", i, test.Net, test.Addr, config.Net, config.Addr)
+		}
+	}
+}
+
+func TestMySQLBuildQueryStringZeroDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ZeroDate  string
+		ParseTime bool
+	}{
+		{"", true},
+		{MySQLZeroDateParse, true},
+		{MySQLZeroDateString, false},
+	}
+
+	for i, test := range tests {
+		dsn := MySQLBuildQueryString("user", "pass", "dbname", "localhost", 3306, "false", "", "", test.ZeroDate)
+		config, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.ParseTime != test.ParseTime {
+			t.Errorf("%d) want parseTime %t, got: %t", i, test.ParseTime, config.ParseTime)
+		}
+	}
+}
+
+func TestMySQLIsZeroDate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Default string
+		Want    bool
+	}{
+		{"0000-00-00", true},
+		{"0000-00-00 00:00:00", true},
+		{"0000-00-00 00:00:00.000000", true},
+		{"2017-01-01 00:00:00", false},
+		{"0000-00-00 00:00:01", false},
+		{"CURRENT_TIMESTAMP", false},
+	}
+
+	for i, test := range tests {
+		if got := mysqlIsZeroDate(test.Default); got != test.Want {
+			t.Errorf("%d) want: %t, got: %t", i, test.Want, got)
+		}
+	}
+}
+
+func TestMySQLBitDefault(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		FullType string
+		Default  string
+		Want     string
+	}{
+		{"bit(1)", "b'1'", "true"},
+		{"bit(1)", "b'0'", "false"},
+		{"bit", "b'1'", "true"},
+		{"bit(8)", "b'101'", "[]byte{0x05}"},
+		{"bit(12)", "b'100000000001'", "[]byte{0x08, 0x01}"},
+		{"bit(1)", "something", "something"},
+		{"bit(1)", "b'2'", "b'2'"},
+	}
+
+	for i, test := range tests {
+		if got := mysqlBitDefault(test.FullType, test.Default); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestMySQLTranslateColumnTypeBit(t *testing.T) {
+	t.Parallel()
+
+	m := &MySQLDriver{}
+
+	tests := []struct {
+		Column      db.Column
+		WantType    string
+		WantDefault string
+	}{
+		{db.Column{Name: "active", DBType: "bit", FullDBType: "bit(1)", Default: "b'1'"}, "bool", "true"},
+		{db.Column{Name: "active", DBType: "bit", FullDBType: "bit", Default: "b'0'"}, "bool", "false"},
+		{db.Column{Name: "active", DBType: "bit", FullDBType: "bit(1)", Nullable: true}, "null.Bool", ""},
+		{db.Column{Name: "mask", DBType: "bit", FullDBType: "bit(12)", Default: "b'100000000001'"}, "[]byte", "[]byte{0x08, 0x01}"},
+		{db.Column{Name: "mask", DBType: "bit", FullDBType: "bit(8)", Nullable: true}, "null.Bytes", ""},
+	}
+
+	for i, test := range tests {
+		c := test.Column
+		c.Default = mysqlBitDefault(c.FullDBType, c.Default)
+		c = m.TranslateColumnType(c)
+		if got := c.GoTypeExpr(); got != test.WantType {
+			t.Errorf("%d) want: %s, got: %s", i, test.WantType, got)
+		}
+		if c.Default != test.WantDefault {
+			t.Errorf("%d) want default: %s, got: %s", i, test.WantDefault, c.Default)
+		}
+	}
+}
+
+func TestMySQLIsMultiValued(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Expression string
+		Want       bool
+	}{
+		{"cast(json_extract(`data`,_utf8mb4'$.tags') as unsigned array)", true},
+		{"CAST(data->'$.zips' AS CHAR(10) ARRAY)", true},
+		{"lower(`email`)", false},
+		{"cast(`created_at` as date)", false},
+		{"", false},
+	}
+
+	for i, test := range tests {
+		if got := mysqlIsMultiValued(test.Expression); got != test.Want {
+			t.Errorf("%d) want: %t, got: %t", i, test.Want, got)
+		}
+	}
+}
+
+func TestMySQLForceBool(t *testing.T) {
+	t.Parallel()
+
+	m := &MySQLDriver{BoolColumns: []string{"users.active"}}
+
+	tests := []struct {
+		Table  string
+		Column db.Column
+		Want   string
+	}{
+		{"users", db.Column{Name: "active", DBType: "tinyint", FullDBType: "tinyint(4)"}, "bool"},
+		{"users", db.Column{Name: "active", DBType: "tinyint", FullDBType: "tinyint(4)", Nullable: true}, "Bool"},
+		{"users", db.Column{Name: "age", DBType: "tinyint", FullDBType: "tinyint(4)"}, "int8"},
+		{"admins", db.Column{Name: "active", DBType: "tinyint", FullDBType: "tinyint(4)"}, "int8"},
+	}
+
+	for i, test := range tests {
+		c := m.TranslateColumnType(m.forceBool(test.Table, test.Column))
+		if c.TypeName != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, c.TypeName)
+		}
+		if c.DBType != test.Column.DBType {
+			t.Errorf("%d) want the DBType kept, got: %s", i, c.DBType)
+		}
+	}
+}
+
+func TestMySQLTranslateColumnTypeDecimal(t *testing.T) {
+	t.Parallel()
+
+	m := &MySQLDriver{}
+
+	tests := []struct {
+		Column db.Column
+		Want   string
+	}{
+		{db.Column{Name: "price", DBType: "decimal", FullDBType: "decimal(10,2)"}, "Decimal"},
+		{db.Column{Name: "price", DBType: "decimal", FullDBType: "decimal(10,2)", Nullable: true}, "NullDecimal"},
+		{db.Column{Name: "price", DBType: "decimal", FullDBType: "decimal(10,2) unsigned", Unsigned: true}, "Decimal"},
+		{db.Column{Name: "price", DBType: "numeric", FullDBType: "numeric(8)"}, "Decimal"},
+		{db.Column{Name: "price", DBType: "fixed", FullDBType: "fixed(8,4)", Nullable: true}, "NullDecimal"},
+	}
+
+	for i, test := range tests {
+		c := m.TranslateColumnType(test.Column)
+		if c.TypeName != test.Want || c.PkgName != "github.com/mickeyreiss/sqlgen/types" {
+			t.Errorf("%d) want: types.%s, got: %s.%s", i, test.Want, c.PkgName, c.TypeName)
+		}
+	}
+}
+
+func TestMySQLTranslateColumnTypeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		RawMessage bool
+		Nullable   bool
+		PkgName    string
+		TypeName   string
+	}{
+		{false, false, "github.com/mickeyreiss/sqlgen/types", "JSON"},
+		{false, true, "github.com/mickeyreiss/sqlgen/types", "JSON"},
+		{true, false, "encoding/json", "RawMessage"},
+		{true, true, "gopkg.in/nullbio/null.v6", "JSON"},
+	}
+
+	for i, test := range tests {
+		m := &MySQLDriver{JSONAsRawMessage: test.RawMessage}
+		c := m.TranslateColumnType(db.Column{Name: "data", DBType: "json", Nullable: test.Nullable})
+		if c.PkgName != test.PkgName || c.TypeName != test.TypeName {
+			t.Errorf("%d) want: %s.%s, got: %s.%s", i, test.PkgName, test.TypeName, c.PkgName, c.TypeName)
+		}
+	}
+}
+
+func TestMySQLTranslateColumnTypeSet(t *testing.T) {
+	t.Parallel()
+
+	m := &MySQLDriver{}
+
+	tests := []struct {
+		Column db.Column
+		Want   string
+	}{
+		{db.Column{Name: "tags", DBType: "set('a','b')", FullDBType: "set('a','b')"}, "Set"},
+		{db.Column{Name: "tags", DBType: "set('a','b')", FullDBType: "set('a','b')", Nullable: true}, "NullSet"},
+		{db.Column{Name: "tags", DBType: "set()", FullDBType: "set()"}, "Set"},
+	}
+
+	for i, test := range tests {
+		c := m.TranslateColumnType(test.Column)
+		if c.TypeName != test.Want || c.PkgName != "github.com/mickeyreiss/sqlgen/types" {
+			t.Errorf("%d) want: types.%s, got: %s.%s", i, test.Want, c.PkgName, c.TypeName)
+		}
+	}
+}
+
+func TestMySQLParseCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Expression string
+		Column     string
+		Check      *db.ColumnCheck
+	}{
+		{"(`age` >= 0)", "age", &db.ColumnCheck{Min: "0"}},
+		{"((`age` > 0) and (`age` <= 150))", "age", &db.ColumnCheck{Min: "0", MinExclusive: true, Max: "150"}},
+		{"(`ratio` < 1.5)", "ratio", &db.ColumnCheck{Max: "1.5", MaxExclusive: true}},
+		{"(`status` in (_utf8mb4'active',_utf8mb4'banned'))", "status", &db.ColumnCheck{In: []string{"'active'", "'banned'"}}},
+		{"((`min` >= 0) and (`max` >= 0))", "", nil},
+		{"(`starts_at` < `ends_at`)", "", nil},
+		{"(char_length(`name`) > 2)", "", nil},
+		{"((`a` > 0) or (`a` < -10))", "", nil},
+	}
+
+	for i, test := range tests {
+		column, check := mysqlParseCheck(test.Expression)
+		if column != test.Column || !reflect.DeepEqual(check, test.Check) {
+			t.Errorf("%d) want: %s %#v, got: %s %#v", i, test.Column, test.Check, column, check)
+		}
+	}
+}
+
+func TestMySQLMaxPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	if got := (&MySQLDriver{}).MaxPlaceholders(); got != 65535 {
+		t.Errorf("want 65535 placeholders, got %d", got)
+	}
+	if got := NewDDLFileDriver("").MaxPlaceholders(); got != 65535 {
+		t.Errorf("want 65535 placeholders for ddl files, got %d", got)
+	}
+}
+
+func TestMySQLIsAutoUpdateTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Extra string
+		Want  bool
+	}{
+		{"on update CURRENT_TIMESTAMP", true},
+		{"on update CURRENT_TIMESTAMP(3)", true},
+		{"DEFAULT_GENERATED on update CURRENT_TIMESTAMP", true},
+		{"DEFAULT_GENERATED", false},
+		{"auto_increment", false},
+		{"", false},
+	}
+
+	for i, test := range tests {
+		if got := mysqlIsAutoUpdateTime(test.Extra); got != test.Want {
+			t.Errorf("%d) want: %t, got: %t", i, test.Want, got)
+		}
+	}
+}
+
+func TestMySQLIsGenerated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Extra      string
+		Want       bool
+		WantStored bool
+	}{
+		{"VIRTUAL GENERATED", true, false},
+		{"STORED GENERATED", true, true},
+		{"virtual generated", true, false},
+		{"stored generated", true, true},
+		{"DEFAULT_GENERATED", false, false},
+		{"DEFAULT_GENERATED on update CURRENT_TIMESTAMP", false, false},
+		{"auto_increment", false, false},
+		{"", false, false},
+	}
+
+	for i, test := range tests {
+		if got := mysqlIsGenerated(test.Extra); got != test.Want {
+			t.Errorf("%d) want: %t, got: %t", i, test.Want, got)
+		}
+		if got := mysqlIsGeneratedStored(test.Extra); got != test.WantStored {
+			t.Errorf("%d) stored want: %t, got: %t", i, test.WantStored, got)
+		}
+	}
+}
+
+func TestMySQLGeneratedFrom(t *testing.T) {
+	t.Parallel()
+
+	columns := []db.Column{{Name: "id"}, {Name: "first_name"}, {Name: "last_name"}, {Name: "price"}, {Name: "qty"}, {Name: "total"}}
+
+	tests := []struct {
+		Expr string
+		Want []string
+	}{
+		{"concat(`first_name`,_utf8mb4\\' \\',`last_name`)", []string{"first_name", "last_name"}},
+		{"(`price` * `qty`)", []string{"price", "qty"}},
+		{"(price * qty + price)", []string{"price", "qty"}},
+		{"concat(`last_name`,_utf8mb4\\'`first_name`\\')", []string{"last_name"}},
+		{"upper(`LAST_NAME`)", []string{"last_name"}},
+		{"json_unquote(json_extract(`doc`,_utf8mb4\\'$.name\\'))", nil},
+		{"concat(`first_name`,\\'unterminated)", nil},
+	}
+
+	for i, test := range tests {
+		if got := mysqlGeneratedFrom(test.Expr, columns); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("%d) want: %v, got: %v", i, test.Want, got)
+		}
+	}
+}
+
+func TestMySQLUserSchemas(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"app", "information_schema", "mysql", "performance_schema", "reporting", "sys", "SYS"}
+	if got, want := mysqlUserSchemas(names), []string{"app", "reporting"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestMySQLCheckConstraintsColumns(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-mysql-checks-test", &recordingDriver{rows: [][]driver.Value{
+		{"age_range", "((`age` >= 0) and (`age` < 150))"},
+		{"sale_below_price", "(`sale_price` < `price`)"},
+	}})
+	conn, err := sql.Open("sqlgen-mysql-checks-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	checks, err := (&MySQLDriver{dbConn: conn}).CheckConstraints(context.Background(), "app", "products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("want 2 checks, got: %#v", checks)
+	}
+	if want := []string{"age"}; !reflect.DeepEqual(checks[0].Columns, want) || checks[0].Column != "age" {
+		t.Errorf("want the check on age, got: %#v", checks[0])
+	}
+	if want := []string{"sale_price", "price"}; !reflect.DeepEqual(checks[1].Columns, want) || checks[1].Check != nil {
+		t.Errorf("want the unparsed check on sale_price and price, got: %#v", checks[1])
+	}
+}
+
+func TestMySQLQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		In   string
+		Want string
+	}{
+		{"plain", "'plain'"},
+		{"it's", `'it\'s'`},
+		{`C:\temp`, `'C:\\temp'`},
+		{`say "\'"`, `'say \"\\\'\"'`},
+		{"line\nbreak\x00", `'line\nbreak\0'`},
+		{"", "''"},
+	}
+
+	for i, test := range tests {
+		if got := (&MySQLDriver{}).QuoteLiteral(test.In); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+		if got := NewDDLFileDriver("").QuoteLiteral(test.In); got != test.Want {
+			t.Errorf("%d) ddl want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestMySQLUpsertClause(t *testing.T) {
+	t.Parallel()
+
+	m := &MySQLDriver{}
+
+	tests := []struct {
+		Conflict []string
+		Update   []string
+		Want     string
+	}{
+		{[]string{"id"}, []string{"name", "email"}, "ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `email` = VALUES(`email`)"},
+		{[]string{"id"}, nil, "ON DUPLICATE KEY UPDATE `id` = `id`"},
+		{nil, []string{"odd`name"}, "ON DUPLICATE KEY UPDATE `odd``name` = VALUES(`odd``name`)"},
+		{[]string{`odd"id`}, nil, "ON DUPLICATE KEY UPDATE `odd\"id` = `odd\"id`"},
+	}
+
+	for i, test := range tests {
+		got, err := m.UpsertClause(test.Conflict, test.Update)
+		if err != nil {
+			t.Errorf("%d) %s", i, err)
+		} else if got != test.Want {
+			t.Errorf("%d) want: %s\ngot:  %s", i, test.Want, got)
+		}
+	}
+
+	if _, err := m.UpsertClause(nil, nil); err == nil {
+		t.Error("want an error with neither conflict nor update columns")
+	}
+}
+
+func TestMySQLIgnoreUnsigned(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Ignore bool
+		Column db.Column
+		Want   string
+	}{
+		{false, db.Column{Name: "id", DBType: "int", FullDBType: "int(10) unsigned", Unsigned: true}, "uint"},
+		{true, db.Column{Name: "id", DBType: "int", FullDBType: "int(10) unsigned", Unsigned: true}, "int"},
+		{false, db.Column{Name: "id", DBType: "bigint", FullDBType: "bigint(20) unsigned", Unsigned: true}, "uint64"},
+		{true, db.Column{Name: "id", DBType: "bigint", FullDBType: "bigint(20) unsigned", Unsigned: true}, "int64"},
+		{true, db.Column{Name: "id", DBType: "bigint", FullDBType: "bigint(20) unsigned", Unsigned: true, Nullable: true}, "Int64"},
+	}
+
+	for i, test := range tests {
+		m := &MySQLDriver{IgnoreUnsigned: test.Ignore}
+		c := m.TranslateColumnType(test.Column)
+		if c.TypeName != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, c.TypeName)
+		}
+		if !c.Unsigned {
+			t.Errorf("%d) want the column still marked unsigned", i)
+		}
+	}
+}
+
+func TestMySQLTranslateColumnTypeGeometry(t *testing.T) {
+	t.Parallel()
+
+	point := db.Column{Name: "location", DBType: "point", FullDBType: "point", SRID: 4326}
+
+	for i, test := range []struct {
+		Column  db.Column
+		PkgName string
+		Type    string
+	}{
+		{point, "", "[]byte"},
+		{db.Column{Name: "location", DBType: "point", Nullable: true}, "gopkg.in/nullbio/null.v6", "Bytes"},
+		{db.Column{Name: "shape", DBType: "geometry"}, "", "[]byte"},
+		{db.Column{Name: "route", DBType: "linestring"}, "", "[]byte"},
+		{db.Column{Name: "area", DBType: "polygon"}, "", "[]byte"},
+		{db.Column{Name: "stops", DBType: "multipoint"}, "", "[]byte"},
+		{db.Column{Name: "routes", DBType: "multilinestring"}, "", "[]byte"},
+		{db.Column{Name: "areas", DBType: "multipolygon"}, "", "[]byte"},
+		{db.Column{Name: "things", DBType: "geometrycollection", Nullable: true}, "gopkg.in/nullbio/null.v6", "Bytes"},
+		{db.Column{Name: "things", DBType: "geomcollection"}, "", "[]byte"},
+	} {
+		c := (&MySQLDriver{}).TranslateColumnType(test.Column)
+		if c.PkgName != test.PkgName || c.TypeName != test.Type {
+			t.Errorf("%d) want geometry as bytes without a configured type: %s.%s, got: %s.%s", i, test.PkgName, test.Type, c.PkgName, c.TypeName)
+		}
+	}
+
+	m := &MySQLDriver{GeometryPackage: "github.com/paulmach/orb", GeometryType: "Point"}
+	for i, test := range []struct {
+		Column db.Column
+		Want   string
+	}{
+		{point, "orb.Point"},
+		{db.Column{Name: "area", DBType: "polygon", Nullable: true}, "*orb.Point"},
+	} {
+		column := test.Column
+		c := m.TranslateColumnType(column)
+		if c.PkgName != "github.com/paulmach/orb" || c.GoTypeExpr() != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, c.GoTypeExpr())
+		}
+		if c.SRID != column.SRID {
+			t.Errorf("%d) want the SRID kept, got: %d", i, c.SRID)
+		}
+	}
+
+	if c := m.TranslateColumnType(db.Column{Name: "name", DBType: "varchar"}); c.TypeName != "string" {
+		t.Errorf("want other columns left alone, got: %s", c.TypeName)
+	}
+}
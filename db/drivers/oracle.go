@@ -0,0 +1,439 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+// OracleDriver holds the database connection string and a handle to the
+// database connection. Schemas are Oracle users, and their names, like
+// those of tables and columns, are upper case unless they were quoted when
+// created. Introspection reads the ALL_ dictionary views, so it sees what
+// the connecting user is allowed to.
+//
+// godror, the database/sql driver it connects with, needs cgo, so it's only
+// linked in when building with -tags oracle.
+type OracleDriver struct {
+	Pool
+
+	connStr string
+	dbConn  *sql.DB
+}
+
+// NewOracleDriver takes the database connection details as parameters and
+// returns a pointer to an OracleDriver object. serviceName is the service
+// the database is registered with the listener as, e.g. ORCLPDB1. Note that
+// it is required to call OracleDriver.Open() and OracleDriver.Close() to
+// open and close the database connection once an object has been obtained.
+func NewOracleDriver(user, pass, host string, port int, serviceName string) *OracleDriver {
+	driver := OracleDriver{
+		connStr: OracleBuildQueryString(user, pass, host, port, serviceName),
+	}
+
+	return &driver
+}
+
+// OracleBuildQueryString builds a godror connection string, connecting to
+// host:port/serviceName. port defaults to 1521.
+func OracleBuildQueryString(user, pass, host string, port int, serviceName string) string {
+	if port == 0 {
+		port = 1521
+	}
+
+	parts := []string{fmt.Sprintf("user=%q", user)}
+	if len(pass) != 0 {
+		parts = append(parts, fmt.Sprintf("password=%q", pass))
+	}
+	parts = append(parts, fmt.Sprintf("connectString=%q", host+":"+strconv.Itoa(port)+"/"+serviceName))
+
+	return strings.Join(parts, " ")
+}
+
+// Open opens the database connection using the connection string
+func (o *OracleDriver) Open() error {
+	if !oracleLinked() {
+		return errors.New("oracle support is not built in, rebuild with -tags oracle")
+	}
+
+	var err error
+	o.dbConn, err = sql.Open("godror", o.connStr)
+	if err != nil {
+		return err
+	}
+	o.apply(o.dbConn)
+
+	return nil
+}
+
+// oracleLinked reports whether godror has registered itself with
+// database/sql.
+func oracleLinked() bool {
+	for _, name := range sql.Drivers() {
+		if name == "godror" {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes the database connection
+func (o *OracleDriver) Close() {
+	o.dbConn.Close()
+}
+
+// conn is what introspection queries run on.
+func (o *OracleDriver) conn() queryer {
+	return o.dbConn
+}
+
+// UseLastInsertID returns false, Oracle returning generated keys with
+// RETURNING ... INTO instead.
+func (o *OracleDriver) UseLastInsertID() bool {
+	return false
+}
+
+// UseTopClause returns false, Oracle limiting rows with ROWNUM or FETCH
+// FIRST rather than TOP.
+func (o *OracleDriver) UseTopClause() bool {
+	return false
+}
+
+// Schemas lists the users of the database, minus the ones Oracle maintains
+// itself, such as SYS.
+func (o *OracleDriver) Schemas(ctx context.Context) ([]string, error) {
+	var names []string
+
+	rows, err := o.conn().QueryContext(ctx, `
+	select username from all_users
+	where oracle_maintained = 'N'
+	order by username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// oraclePlaceholders returns count :n placeholders, numbered from start.
+func oraclePlaceholders(count, start int) string {
+	placeholders := make([]string, count)
+	for i := range placeholders {
+		placeholders[i] = ":" + strconv.Itoa(start+i)
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+// TableNames retrieves the names of the tables owned by schema from
+// all_tables. It uses a whitelist and blacklist.
+func (o *OracleDriver) TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error) {
+	var names []string
+
+	query := `select table_name from all_tables where owner = :1`
+	args := []interface{}{schema}
+	if len(whitelist) > 0 {
+		query += fmt.Sprintf(" and table_name in (%s)", oraclePlaceholders(len(whitelist), 2))
+		for _, w := range whitelist {
+			args = append(args, w)
+		}
+	} else if len(blacklist) > 0 {
+		query += fmt.Sprintf(" and table_name not in (%s)", oraclePlaceholders(len(blacklist), 2))
+		for _, b := range blacklist {
+			args = append(args, b)
+		}
+	}
+	query += " order by table_name"
+
+	rows, err := o.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// Columns retrieves the columns of a table from all_tab_columns, with their
+// comments. Columns are unique if a single column primary key or unique
+// constraint covers them, and auto incremented if they are identity columns
+// or default to a sequence's nextval. NUMBER(*,0) columns, which Oracle only
+// tells apart from a plain NUMBER by their NULL precision and 0 scale, get
+// the DBType INTEGER.
+func (o *OracleDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
+	var columns []db.Column
+
+	rows, err := o.conn().QueryContext(ctx, `
+	select
+		c.column_name,
+		c.data_type,
+		c.data_precision,
+		c.data_scale,
+		c.char_length,
+		c.nullable,
+		c.data_default,
+		c.identity_column,
+		(select count(*)
+			from all_constraints uc
+				inner join all_cons_columns ucc on ucc.owner = uc.owner and ucc.constraint_name = uc.constraint_name
+			where uc.owner = c.owner and uc.table_name = c.table_name and uc.constraint_type in ('P', 'U') and
+				ucc.column_name = c.column_name and
+				(select count(*) from all_cons_columns x where x.owner = uc.owner and x.constraint_name = uc.constraint_name) = 1
+		) as is_unique,
+		cc.comments
+	from all_tab_columns c
+		left join all_col_comments cc on cc.owner = c.owner and cc.table_name = c.table_name and cc.column_name = c.column_name
+	where c.owner = :1 and c.table_name = :2
+	order by c.column_id`, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var colName, colType, nullable, identity string
+		var precision, scale *int
+		var charLength, unique int
+		var defaultValue, comment *string
+		if err := rows.Scan(&colName, &colType, &precision, &scale, &charLength, &nullable, &defaultValue, &identity, &unique, &comment); err != nil {
+			return nil, err
+		}
+
+		column := db.Column{
+			Name:          colName,
+			DBType:        colType,
+			Nullable:      nullable == "Y",
+			Unique:        unique != 0,
+			MaxLength:     charLength,
+			AutoIncrement: identity == "YES",
+		}
+		if precision != nil {
+			column.Precision = *precision
+		}
+		if scale != nil {
+			column.Scale = *scale
+		}
+		if colType == "NUMBER" && precision == nil && scale != nil && *scale == 0 {
+			// NUMBER(*,0), what INTEGER, INT and SMALLINT are declared as:
+			// whole numbers of up to 38 digits. A plain NUMBER has neither.
+			column.DBType = "INTEGER"
+		}
+		if defaultValue != nil {
+			// Oracle keeps the whitespace after the default in the DDL.
+			column.Default = strings.TrimSpace(*defaultValue)
+			column.AutoIncrement = column.AutoIncrement || strings.Contains(strings.ToLower(column.Default), ".nextval")
+		}
+		if comment != nil {
+			column.Comment = *comment
+		}
+
+		columns = append(columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// PrimaryKeyInfo looks up the primary key for a table, with its columns in
+// order.
+func (o *OracleDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*db.PrimaryKey, error) {
+	var pkey *db.PrimaryKey
+
+	query := `
+	select c.constraint_name, cc.column_name
+	from all_constraints c
+		inner join all_cons_columns cc on cc.owner = c.owner and cc.constraint_name = c.constraint_name
+	where c.owner = :1 and c.table_name = :2 and c.constraint_type = 'P'
+	order by cc.position`
+
+	rows, err := o.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, err
+		}
+
+		if pkey == nil {
+			pkey = &db.PrimaryKey{Name: name}
+		}
+		pkey.Columns = append(pkey.Columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pkey, nil
+}
+
+// ForeignKeyInfo retrieves the foreign keys for a given table name, with the
+// columns of composite keys in order. Oracle has no ON UPDATE actions.
+func (o *OracleDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]db.ForeignKey, error) {
+	var fkeys []db.ForeignKey
+
+	query := `
+	select c.constraint_name, cc.column_name, rc.table_name, rcc.column_name, c.delete_rule, c.deferrable, c.deferred
+	from all_constraints c
+		inner join all_cons_columns cc on cc.owner = c.owner and cc.constraint_name = c.constraint_name
+		inner join all_constraints rc on rc.owner = c.r_owner and rc.constraint_name = c.r_constraint_name
+		inner join all_cons_columns rcc on rcc.owner = rc.owner and rcc.constraint_name = rc.constraint_name and rcc.position = cc.position
+	where c.owner = :1 and c.table_name = :2 and c.constraint_type = 'R'
+	order by c.constraint_name, cc.position`
+
+	rows, err := o.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		fkey := db.ForeignKey{Table: tableName}
+		var deferrable, deferred string
+		if err := rows.Scan(&fkey.Name, &fkey.Column, &fkey.ForeignTable, &fkey.ForeignColumn, &fkey.OnDelete, &deferrable, &deferred); err != nil {
+			return nil, err
+		}
+		fkey.Deferrable = deferrable == "DEFERRABLE"
+		fkey.InitiallyDeferred = deferred == "DEFERRED"
+
+		fkeys = append(fkeys, fkey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return db.GroupForeignKeys(fkeys), nil
+}
+
+// TranslateColumnType converts Oracle database types to Go types, for
+// example "VARCHAR2" to "string". NUMBER columns with a precision and no
+// scale are integers, int up to 9 digits and int64 up to 18, INTEGER ones
+// (NUMBER(*,0), see Columns) are int64, and others, such as a plain NUMBER,
+// are float64.
+func (o *OracleDriver) TranslateColumnType(c db.Column) db.Column {
+	dbType := c.DBType
+	if strings.HasPrefix(dbType, "TIMESTAMP") {
+		// TIMESTAMP(6), TIMESTAMP(6) WITH TIME ZONE and so on.
+		dbType = "TIMESTAMP"
+	}
+
+	switch {
+	case c.DBType == "INTEGER":
+		dbType = "BIGINT"
+	case c.DBType == "NUMBER" && c.Scale == 0 && c.Precision != 0 && c.Precision <= 18:
+		dbType = "INTEGER"
+		if c.Precision > 9 {
+			dbType = "BIGINT"
+		}
+	}
+
+	if c.Nullable {
+		switch dbType {
+		case "INTEGER":
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Int"
+		case "BIGINT":
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Int64"
+		case "BINARY_FLOAT":
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Float32"
+		case "NUMBER", "FLOAT", "BINARY_DOUBLE":
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Float64"
+		case "DATE", "TIMESTAMP":
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Time"
+		case "BLOB", "RAW", "LONG RAW":
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "Bytes"
+		default:
+			c.PkgName = "gopkg.in/nullbio/null.v6"
+			c.TypeName = "String"
+		}
+	} else {
+		switch dbType {
+		case "INTEGER":
+			c.TypeName = "int"
+		case "BIGINT":
+			c.TypeName = "int64"
+		case "BINARY_FLOAT":
+			c.TypeName = "float32"
+		case "NUMBER", "FLOAT", "BINARY_DOUBLE":
+			c.TypeName = "float64"
+		case "DATE", "TIMESTAMP":
+			c.PkgName = "time"
+			c.TypeName = "Time"
+		case "BLOB", "RAW", "LONG RAW":
+			c.TypeName = "[]byte"
+		default:
+			// VARCHAR2, NVARCHAR2, CHAR, NCHAR, CLOB, NCLOB and LONG.
+			c.TypeName = "string"
+		}
+	}
+
+	return c
+}
+
+// RightQuote is the quoting character for the right side of the identifier
+func (o *OracleDriver) RightQuote() byte {
+	return '"'
+}
+
+// LeftQuote is the quoting character for the left side of the identifier
+func (o *OracleDriver) LeftQuote() byte {
+	return '"'
+}
+
+// IndexPlaceholders returns true, Oracle binding :1, :2 and so on.
+func (o *OracleDriver) IndexPlaceholders() bool {
+	return true
+}
+
+// MaxPlaceholders returns 65535, the most bind variables Oracle allows in a
+// statement.
+func (o *OracleDriver) MaxPlaceholders() int {
+	return 65535
+}
+
+// QuoteLiteral quotes s with single quotes, doubling those inside it.
+// Oracle has no backslash escapes.
+func (o *OracleDriver) QuoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// UpsertClause returns an error: Oracle has no ON CONFLICT, and upserts
+// with MERGE instead.
+func (o *OracleDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	return "", errors.New("oracle has no upsert clause, use MERGE")
+}
@@ -0,0 +1,9 @@
+//go:build oracle
+// +build oracle
+
+package drivers
+
+import (
+	// godror registers the database/sql driver OracleDriver connects with.
+	_ "github.com/godror/godror"
+)
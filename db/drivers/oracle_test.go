@@ -0,0 +1,117 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestOracleBuildQueryString(t *testing.T) {
+	t.Parallel()
+
+	got := OracleBuildQueryString("scott", "tiger", "localhost", 0, "ORCLPDB1")
+	want := `user="scott" password="tiger" connectString="localhost:1521/ORCLPDB1"`
+	if got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+
+	got = OracleBuildQueryString("scott", "", "db.example.com", 1522, "APP")
+	want = `user="scott" connectString="db.example.com:1522/APP"`
+	if got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+}
+
+func TestOraclePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	if got := oraclePlaceholders(3, 2); got != ":2, :3, :4" {
+		t.Errorf("want :2, :3, :4, got: %s", got)
+	}
+}
+
+func TestOracleTranslateColumnType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Column  db.Column
+		PkgName string
+		Type    string
+	}{
+		{db.Column{DBType: "NUMBER", Precision: 9}, "", "int"},
+		{db.Column{DBType: "NUMBER", Precision: 10}, "", "int64"},
+		{db.Column{DBType: "NUMBER", Precision: 19}, "", "float64"},
+		{db.Column{DBType: "NUMBER", Precision: 10, Scale: 2}, "", "float64"},
+		{db.Column{DBType: "NUMBER"}, "", "float64"},
+		{db.Column{DBType: "INTEGER"}, "", "int64"},
+		{db.Column{DBType: "INTEGER", Nullable: true}, "gopkg.in/nullbio/null.v6", "Int64"},
+		{db.Column{DBType: "NUMBER", Precision: 18, Nullable: true}, "gopkg.in/nullbio/null.v6", "Int64"},
+		{db.Column{DBType: "VARCHAR2", MaxLength: 100}, "", "string"},
+		{db.Column{DBType: "VARCHAR2", Nullable: true}, "gopkg.in/nullbio/null.v6", "String"},
+		{db.Column{DBType: "CLOB"}, "", "string"},
+		{db.Column{DBType: "BLOB"}, "", "[]byte"},
+		{db.Column{DBType: "BLOB", Nullable: true}, "gopkg.in/nullbio/null.v6", "Bytes"},
+		{db.Column{DBType: "DATE"}, "time", "Time"},
+		{db.Column{DBType: "TIMESTAMP(6)"}, "time", "Time"},
+		{db.Column{DBType: "TIMESTAMP(6) WITH TIME ZONE", Nullable: true}, "gopkg.in/nullbio/null.v6", "Time"},
+	}
+
+	o := &OracleDriver{}
+	for i, test := range tests {
+		c := o.TranslateColumnType(test.Column)
+		if c.PkgName != test.PkgName || c.TypeName != test.Type {
+			t.Errorf("%d) want: %s.%s, got: %s.%s", i, test.PkgName, test.Type, c.PkgName, c.TypeName)
+		}
+	}
+}
+
+func TestOracleColumnsScale(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-oracle-columns-test", &recordingDriver{rows: [][]driver.Value{
+		{"COUNT", "NUMBER", nil, int64(0), int64(0), "N", nil, "NO", int64(0), nil},
+		{"RATIO", "NUMBER", nil, nil, int64(0), "N", nil, "NO", int64(0), nil},
+		{"PRICE", "NUMBER", int64(10), int64(0), int64(0), "N", nil, "NO", int64(0), nil},
+	}})
+	conn, err := sql.Open("sqlgen-oracle-columns-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	o := &OracleDriver{dbConn: conn}
+	cols, err := o.Columns(context.Background(), "APP", "STATS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"int64", "float64", "int64"}
+	for i, c := range cols {
+		if got := o.TranslateColumnType(c).TypeName; got != want[i] {
+			t.Errorf("%d) %s: want: %s, got: %s", i, c.Name, want[i], got)
+		}
+	}
+	if cols[0].DBType != "INTEGER" || cols[1].DBType != "NUMBER" {
+		t.Errorf("want only the NULL precision, 0 scale column to be INTEGER, got: %s, %s", cols[0].DBType, cols[1].DBType)
+	}
+}
+
+func TestOracleQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	if got := (&OracleDriver{}).QuoteLiteral(`it's a \n`); got != `'it''s a \n'` {
+		t.Errorf("want quotes doubled and backslashes kept, got: %s", got)
+	}
+}
+
+func TestOracleUpsertClause(t *testing.T) {
+	t.Parallel()
+
+	if clause, err := (&OracleDriver{}).UpsertClause([]string{"id"}, nil); err == nil || !strings.Contains(err.Error(), "MERGE") {
+		t.Errorf("want an error pointing at MERGE, got: %q, %v", clause, err)
+	}
+}
@@ -0,0 +1,27 @@
+package drivers
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Pool holds the connection pool settings applied to a driver's *sql.DB when
+// it is opened. Zero values leave database/sql's defaults in place.
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// apply sets the non-zero pool settings on conn.
+func (p Pool) apply(conn *sql.DB) {
+	if p.MaxOpenConns != 0 {
+		conn.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns != 0 {
+		conn.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime != 0 {
+		conn.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+}
@@ -0,0 +1,73 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestPoolApplied(t *testing.T) {
+	t.Parallel()
+
+	// sql.Open doesn't connect, so no server is needed.
+	m := NewMySQLDriver("user", "pass", "dbname", "localhost", 3306, "false", "", "", "")
+	m.Pool = Pool{MaxOpenConns: 3, MaxIdleConns: 2, ConnMaxLifetime: time.Minute}
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if got := m.dbConn.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("want 3 max open connections, got: %d", got)
+	}
+
+	p := NewPostgresDriver("user", "pass", "dbname", "localhost", 5432, "disable", "", "", "")
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if got := p.dbConn.Stats().MaxOpenConnections; got != 0 {
+		t.Errorf("want the unlimited default, got: %d", got)
+	}
+}
+
+func TestPoolIdleAndLifetime(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-pool-test", &recordingDriver{})
+	conn, err := sql.Open("sqlgen-pool-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	Pool{MaxIdleConns: 1, ConnMaxLifetime: 10 * time.Millisecond}.apply(conn)
+
+	ctx := context.Background()
+	first, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+	second.Close()
+
+	if stats := conn.Stats(); stats.Idle != 1 || stats.MaxIdleClosed != 1 {
+		t.Errorf("want 1 idle connection kept and 1 closed, got: %d and %d", stats.Idle, stats.MaxIdleClosed)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	third, err := conn.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	third.Close()
+
+	if got := conn.Stats().MaxLifetimeClosed; got != 1 {
+		t.Errorf("want the expired connection closed, got: %d", got)
+	}
+}
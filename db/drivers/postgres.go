@@ -1,9 +1,9 @@
 package drivers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
 	"strings"
 
 	_ "github.com/lib/pq"
@@ -15,6 +15,14 @@ import (
 // PostgresDriver holds the database connection string and a handle
 // to the database connection.
 type PostgresDriver struct {
+	Pool
+	Snapshot
+
+	// Warnf is given the columns TranslateColumnType can't map faithfully,
+	// such as those of unknown user-defined types. They are only mapped to
+	// string if it is nil.
+	Warnf func(format string, args ...interface{})
+
 	connStr string
 	dbConn  *sql.DB
 }
@@ -22,17 +30,19 @@ type PostgresDriver struct {
 // NewPostgresDriver takes the database connection details as parameters and
 // returns a pointer to a PostgresDriver object. Note that it is required to
 // call PostgresDriver.Open() and PostgresDriver.Close() to open and close
-// the database connection once an object has been obtained.
-func NewPostgresDriver(user, pass, dbname, host string, port int, sslmode string) *PostgresDriver {
+// the database connection once an object has been obtained. sslcert, sslkey
+// and sslrootcert are the paths of the client certificate and key, and of
+// the certificate authorities the server's certificate is verified against.
+func NewPostgresDriver(user, pass, dbname, host string, port int, sslmode, sslcert, sslkey, sslrootcert string) *PostgresDriver {
 	driver := PostgresDriver{
-		connStr: PostgresBuildQueryString(user, pass, dbname, host, port, sslmode),
+		connStr: PostgresBuildQueryString(user, pass, dbname, host, port, sslmode, sslcert, sslkey, sslrootcert),
 	}
 
 	return &driver
 }
 
 // PostgresBuildQueryString builds a query string.
-func PostgresBuildQueryString(user, pass, dbname, host string, port int, sslmode string) string {
+func PostgresBuildQueryString(user, pass, dbname, host string, port int, sslmode, sslcert, sslkey, sslrootcert string) string {
 	parts := []string{}
 	if len(user) != 0 {
 		parts = append(parts, fmt.Sprintf("user=%s", user))
@@ -52,10 +62,26 @@ func PostgresBuildQueryString(user, pass, dbname, host string, port int, sslmode
 	if len(sslmode) != 0 {
 		parts = append(parts, fmt.Sprintf("sslmode=%s", sslmode))
 	}
+	if len(sslcert) != 0 {
+		parts = append(parts, "sslcert="+pgConnValue(sslcert))
+	}
+	if len(sslkey) != 0 {
+		parts = append(parts, "sslkey="+pgConnValue(sslkey))
+	}
+	if len(sslrootcert) != 0 {
+		parts = append(parts, "sslrootcert="+pgConnValue(sslrootcert))
+	}
 
 	return strings.Join(parts, " ")
 }
 
+// pgConnValue quotes a connection string value the way libpq reads it,
+// escaping backslashes and single quotes, for paths with spaces in them.
+func pgConnValue(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+}
+
 // Open opens the database connection using the connection string
 func (p *PostgresDriver) Open() error {
 	var err error
@@ -63,15 +89,23 @@ func (p *PostgresDriver) Open() error {
 	if err != nil {
 		return err
 	}
+	p.apply(p.dbConn)
 
-	return nil
+	return p.begin(p.dbConn)
 }
 
 // Close closes the database connection
 func (p *PostgresDriver) Close() {
+	p.commit()
 	p.dbConn.Close()
 }
 
+// conn is what introspection queries run on, the snapshot transaction if
+// there is one.
+func (p *PostgresDriver) conn() queryer {
+	return p.queryer(p.dbConn)
+}
+
 // UseLastInsertID returns false for postgres
 func (p *PostgresDriver) UseLastInsertID() bool {
 	return false
@@ -82,10 +116,35 @@ func (m *PostgresDriver) UseTopClause() bool {
 	return false
 }
 
+// Schemas lists the schemas of the database, minus the pg_ system schemas
+// and information_schema.
+func (p *PostgresDriver) Schemas(ctx context.Context) ([]string, error) {
+	var names []string
+
+	rows, err := p.conn().QueryContext(ctx, `
+	select nspname from pg_namespace
+	where nspname <> 'information_schema' and nspname not like 'pg\_%'
+	order by nspname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
 // TableNames connects to the postgres database and
 // retrieves all table names from the information_schema where the
 // table schema is schema. It uses a whitelist and blacklist.
-func (p *PostgresDriver) TableNames(schema string, whitelist, blacklist []string) ([]string, error) {
+func (p *PostgresDriver) TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error) {
 	var names []string
 
 	query := fmt.Sprintf(`select table_name from information_schema.tables where table_schema = $1`)
@@ -102,7 +161,7 @@ func (p *PostgresDriver) TableNames(schema string, whitelist, blacklist []string
 		}
 	}
 
-	rows, err := p.dbConn.Query(query, args...)
+	rows, err := p.conn().QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -124,10 +183,10 @@ func (p *PostgresDriver) TableNames(schema string, whitelist, blacklist []string
 // from the database information_schema.columns. It retrieves the column names
 // and column types and returns those as a []Column after TranslateColumnType()
 // converts the SQL types to Go types, for example: "varchar" to "string"
-func (p *PostgresDriver) Columns(schema, tableName string) ([]db.Column, error) {
+func (p *PostgresDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
 	var columns []db.Column
 
-	rows, err := p.dbConn.Query(`
+	rows, err := p.conn().QueryContext(ctx, `
 		select
 		c.column_name,
 		(
@@ -172,7 +231,11 @@ func (p *PostgresDriver) Columns(schema, tableName string) ([]db.Column, error)
 			inner join pg_attribute pga on pga.attrelid = pgi.indrelid and pga.attnum = ANY(pgi.indkey)
 			where
 				pgix.schemaname = $1 and pgix.tablename = c.table_name and pga.attname = c.column_name and pgi.indisunique = true
-		)) as is_unique
+		)) as is_unique,
+		col_description(format('%I.%I', c.table_schema, c.table_name)::regclass, c.ordinal_position::int) as column_comment,
+		c.character_maximum_length,
+		coalesce(pgt.typtype = 'c', false) as is_composite,
+		c.udt_schema
 
 		from information_schema.columns as c
 		inner join pg_namespace as pgn on pgn.nspname = c.udt_schema
@@ -188,13 +251,20 @@ func (p *PostgresDriver) Columns(schema, tableName string) ([]db.Column, error)
 	}
 	defer rows.Close()
 
+	// compositeSchemas are the schemas of the composite types of columns, by
+	// column index.
+	compositeSchemas := map[int]string{}
 	for rows.Next() {
-		var colName, colType, udtName string
-		var defaultValue, arrayType *string
-		var nullable, unique bool
-		if err := rows.Scan(&colName, &colType, &udtName, &arrayType, &defaultValue, &nullable, &unique); err != nil {
+		var colName, colType, udtName, udtSchema string
+		var defaultValue, arrayType, comment *string
+		var nullable, unique, composite bool
+		var maxLength *int
+		if err := rows.Scan(&colName, &colType, &udtName, &arrayType, &defaultValue, &nullable, &unique, &comment, &maxLength, &composite, &udtSchema); err != nil {
 			return nil, errors.Wrapf(err, "unable to scan for table %s", tableName)
 		}
+		if composite {
+			compositeSchemas[len(columns)] = udtSchema
+		}
 
 		column := db.Column{
 			Name:     colName,
@@ -206,16 +276,105 @@ func (p *PostgresDriver) Columns(schema, tableName string) ([]db.Column, error)
 		}
 		if defaultValue != nil {
 			column.Default = *defaultValue
+			column.AutoIncrement = strings.HasPrefix(column.Default, "nextval(")
+		}
+		if comment != nil {
+			column.Comment = *comment
+		}
+		if maxLength != nil {
+			column.MaxLength = *maxLength
 		}
 
 		columns = append(columns, column)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	// The attributes of composite types are queried once the columns are
+	// read, a snapshot's transaction running one query at a time.
+	rows.Close()
+
+	composites := map[string]*db.CompositeType{}
+	for i, udtSchema := range compositeSchemas {
+		key := udtSchema + "." + columns[i].UDTName
+		if composites[key] == nil {
+			composite, err := p.compositeType(ctx, udtSchema, columns[i].UDTName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to get composite type of %s.%s", tableName, columns[i].Name)
+			}
+			composites[key] = composite
+		}
+		columns[i].Composite = composites[key]
+	}
+
 	return columns, nil
 }
 
+// compositeType retrieves the attributes of a composite type, the fields of
+// the struct generated for it, with their Go types. Attributes are always
+// nullable. Attributes of composite types themselves aren't resolved, and
+// are translated like any other user-defined type.
+func (p *PostgresDriver) compositeType(ctx context.Context, schema, name string) (*db.CompositeType, error) {
+	rows, err := p.conn().QueryContext(ctx, `
+		select a.attribute_name, a.data_type, a.attribute_udt_name, e.data_type as array_type, a.character_maximum_length
+		from information_schema.attributes a
+		left join information_schema.element_types e
+			on ((a.udt_catalog, a.udt_schema, a.udt_name, 'USER-DEFINED TYPE', a.dtd_identifier)
+			= (e.object_catalog, e.object_schema, e.object_name, e.object_type, e.collection_type_identifier))
+		where a.udt_schema = $1 and a.udt_name = $2
+		order by a.ordinal_position
+	`, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	composite := &db.CompositeType{Name: name, GoName: db.GoName(name)}
+	for rows.Next() {
+		var attrName, attrType, udtName string
+		var arrayType *string
+		var maxLength *int
+		if err := rows.Scan(&attrName, &attrType, &udtName, &arrayType, &maxLength); err != nil {
+			return nil, errors.Wrapf(err, "unable to scan for composite type %s", name)
+		}
+
+		field := db.Column{
+			Name:     attrName,
+			DBType:   attrType,
+			ArrType:  arrayType,
+			UDTName:  udtName,
+			Nullable: true,
+		}
+		if maxLength != nil {
+			field.MaxLength = *maxLength
+		}
+
+		composite.Fields = append(composite.Fields, p.TranslateColumnType(field))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return composite, nil
+}
+
+// TableComment returns the comment of a table, empty if it has none.
+func (p *PostgresDriver) TableComment(ctx context.Context, schema, tableName string) (string, error) {
+	var comment string
+
+	query := `select coalesce(obj_description(format('%I.%I', $1::text, $2::text)::regclass, 'pg_class'), '')`
+
+	if err := p.conn().QueryRowContext(ctx, query, schema, tableName).Scan(&comment); err != nil {
+		return "", err
+	}
+
+	return comment, nil
+}
+
 // PrimaryKeyInfo looks up the primary key for a table.
-func (p *PostgresDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKey, error) {
+func (p *PostgresDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*db.PrimaryKey, error) {
 	pkey := &db.PrimaryKey{}
 	var err error
 
@@ -224,7 +383,7 @@ func (p *PostgresDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKe
 	from information_schema.table_constraints as tc
 	where tc.table_name = $1 and tc.constraint_type = 'PRIMARY KEY' and tc.table_schema = $2;`
 
-	row := p.dbConn.QueryRow(query, tableName, schema)
+	row := p.conn().QueryRowContext(ctx, query, tableName, schema)
 	if err = row.Scan(&pkey.Name); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -238,7 +397,7 @@ func (p *PostgresDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKe
 	where  constraint_name = $1 and table_schema = $2;`
 
 	var rows *sql.Rows
-	if rows, err = p.dbConn.Query(queryColumns, pkey.Name, schema); err != nil {
+	if rows, err = p.conn().QueryContext(ctx, queryColumns, pkey.Name, schema); err != nil {
 		return nil, err
 	}
 	defer rows.Close()
@@ -264,37 +423,47 @@ func (p *PostgresDriver) PrimaryKeyInfo(schema, tableName string) (*db.PrimaryKe
 	return pkey, nil
 }
 
-// ForeignKeyInfo retrieves the foreign keys for a given table name.
-func (p *PostgresDriver) ForeignKeyInfo(schema, tableName string) ([]db.ForeignKey, error) {
+// ForeignKeyInfo retrieves the foreign keys for a given table name, with the
+// columns of composite keys in order.
+func (p *PostgresDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]db.ForeignKey, error) {
 	var fkeys []db.ForeignKey
 
+	// conkey and confkey pair the columns by position, which k walks; no key
+	// has more than 32 columns.
 	query := `
 	select
 		pgcon.conname,
 		pgc.relname as source_table,
 		pgasrc.attname as source_column,
 		dstlookupname.relname as dest_table,
-		pgadst.attname as dest_column
+		pgadst.attname as dest_column,
+		pgcon.condeferrable,
+		pgcon.condeferred,
+		case pgcon.confdeltype when 'c' then 'CASCADE' when 'n' then 'SET NULL' when 'd' then 'SET DEFAULT' when 'r' then 'RESTRICT' else 'NO ACTION' end,
+		case pgcon.confupdtype when 'c' then 'CASCADE' when 'n' then 'SET NULL' when 'd' then 'SET DEFAULT' when 'r' then 'RESTRICT' else 'NO ACTION' end
 	from pg_namespace pgn
 		inner join pg_class pgc on pgn.oid = pgc.relnamespace and pgc.relkind = 'r'
 		inner join pg_constraint pgcon on pgn.oid = pgcon.connamespace and pgc.oid = pgcon.conrelid
 		inner join pg_class dstlookupname on pgcon.confrelid = dstlookupname.oid
-		inner join pg_attribute pgasrc on pgc.oid = pgasrc.attrelid and pgasrc.attnum = ANY(pgcon.conkey)
-		inner join pg_attribute pgadst on pgcon.confrelid = pgadst.attrelid and pgadst.attnum = ANY(pgcon.confkey)
-	where pgn.nspname = $2 and pgc.relname = $1 and pgcon.contype = 'f'`
+		inner join generate_series(1, 32) as k(i) on k.i <= array_upper(pgcon.conkey, 1)
+		inner join pg_attribute pgasrc on pgc.oid = pgasrc.attrelid and pgasrc.attnum = pgcon.conkey[k.i]
+		inner join pg_attribute pgadst on pgcon.confrelid = pgadst.attrelid and pgadst.attnum = pgcon.confkey[k.i]
+	where pgn.nspname = $2 and pgc.relname = $1 and pgcon.contype = 'f'
+	order by pgcon.conname, k.i`
 
 	var rows *sql.Rows
 	var err error
-	if rows, err = p.dbConn.Query(query, tableName, schema); err != nil {
+	if rows, err = p.conn().QueryContext(ctx, query, tableName, schema); err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var fkey db.ForeignKey
 		var sourceTable string
 
 		fkey.Table = tableName
-		err = rows.Scan(&fkey.Name, &sourceTable, &fkey.Column, &fkey.ForeignTable, &fkey.ForeignColumn)
+		err = rows.Scan(&fkey.Name, &sourceTable, &fkey.Column, &fkey.ForeignTable, &fkey.ForeignColumn, &fkey.Deferrable, &fkey.InitiallyDeferred, &fkey.OnDelete, &fkey.OnUpdate)
 		if err != nil {
 			return nil, err
 		}
@@ -306,7 +475,97 @@ func (p *PostgresDriver) ForeignKeyInfo(schema, tableName string) ([]db.ForeignK
 		return nil, err
 	}
 
-	return fkeys, nil
+	return db.GroupForeignKeys(fkeys), nil
+}
+
+// IndexInfo retrieves the secondary indexes for a given table name, with
+// their key parts in order. Expression key parts are recorded as the index
+// Expression.
+func (p *PostgresDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]db.Index, error) {
+	var indexes []db.Index
+
+	query := `
+	select
+		pgci.relname as index_name,
+		pgi.indisunique,
+		pga.attname as column_name,
+		pg_get_indexdef(pgi.indexrelid, k.n::int, true) as expression
+	from pg_index pgi
+		inner join pg_class pgc on pgc.oid = pgi.indrelid
+		inner join pg_class pgci on pgci.oid = pgi.indexrelid
+		inner join pg_namespace pgn on pgn.oid = pgc.relnamespace
+		cross join lateral unnest(pgi.indkey::int2[]) with ordinality as k(attnum, n)
+		left join pg_attribute pga on pga.attrelid = pgc.oid and pga.attnum = k.attnum and k.attnum <> 0
+	where pgn.nspname = $1 and pgc.relname = $2 and not pgi.indisprimary
+	order by pgci.relname, k.n`
+
+	rows, err := p.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var unique bool
+		var column, expression *string
+		if err := rows.Scan(&name, &unique, &column, &expression); err != nil {
+			return nil, err
+		}
+
+		indexes = addIndexPart(indexes, name, unique, column, expression, nil)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return indexes, nil
+}
+
+// CheckConstraints retrieves the CHECK constraints of a table, with the
+// columns each covers in alphabetical order.
+func (p *PostgresDriver) CheckConstraints(ctx context.Context, schema, tableName string) ([]db.CheckConstraint, error) {
+	var checks []db.CheckConstraint
+
+	// Check constraint names are only unique per table, so they're looked up
+	// by the table's oid rather than by schema and name.
+	query := `
+	select pgcon.conname, pg_get_expr(pgcon.conbin, pgcon.conrelid), pga.attname
+	from pg_namespace pgn
+		inner join pg_class pgc on pgn.oid = pgc.relnamespace
+		inner join pg_constraint pgcon on pgc.oid = pgcon.conrelid and pgcon.contype = 'c'
+		left join pg_attribute pga on pgc.oid = pga.attrelid and pga.attnum = any(pgcon.conkey)
+	where pgn.nspname = $1 and pgc.relname = $2
+	order by pgcon.conname, pga.attname`
+
+	rows, err := p.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, expression string
+		var column *string
+		if err := rows.Scan(&name, &expression, &column); err != nil {
+			return nil, err
+		}
+
+		if len(checks) == 0 || checks[len(checks)-1].Name != name {
+			checks = append(checks, db.CheckConstraint{Name: name, Expression: expression})
+		}
+		if column != nil {
+			check := &checks[len(checks)-1]
+			check.Columns = append(check.Columns, *column)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return checks, nil
 }
 
 // TranslateColumnType converts postgres database types to Go types, for example
@@ -325,7 +584,9 @@ func (p *PostgresDriver) TranslateColumnType(c db.Column) db.Column {
 			c.TypeName = "null.Float64"
 		case "real":
 			c.TypeName = "null.Float32"
-		case "bit", "interval", "bit varying", "character", "money", "character varying", "cidr", "inet", "macaddr", "text", "uuid", "xml":
+		case "bit", "bit varying", "interval", "character", "money", "character varying", "cidr", "inet", "macaddr", "text", "uuid", "xml":
+			// Bit strings scan as their text, e.g. "1010". MaxLength is
+			// their length in bits.
 			c.TypeName = "null.String"
 		case `"char"`:
 			c.TypeName = "null.Byte"
@@ -338,19 +599,21 @@ func (p *PostgresDriver) TranslateColumnType(c db.Column) db.Column {
 		case "date", "time", "timestamp without time zone", "timestamp with time zone":
 			c.TypeName = "null.Time"
 		case "ARRAY":
-			if c.ArrType == nil {
-				panic("unable to get postgres ARRAY underlying type")
-			}
-			c.TypeName = getArrayType(c)
+			c.TypeName = "types.Null" + strings.TrimPrefix(getArrayType(c), "types.")
 			// Make DBType something like ARRAYinteger for parsing with randomize.Struct
-			c.DBType = c.DBType + *c.ArrType
+			c.DBType = c.DBType + arrayElemType(c)
 		case "USER-DEFINED":
-			if c.UDTName == "hstore" {
+			if c.Composite != nil {
+				c.TypeName = "*" + c.Composite.GoName
+			} else if c.UDTName == "hstore" {
 				c.TypeName = "types.HStore"
 				c.DBType = "hstore"
+			} else if c.UDTName == "citext" {
+				c.TypeName = "null.String"
+				c.CaseInsensitive = true
 			} else {
 				c.TypeName = "string"
-				fmt.Fprintln(os.Stderr, "Warning: Incompatible data type detected: %s\n", c.UDTName)
+				p.warnf("incompatible data type %s of column %s, mapped to string", c.UDTName, c.Name)
 			}
 		default:
 			c.TypeName = "null.String"
@@ -367,7 +630,7 @@ func (p *PostgresDriver) TranslateColumnType(c db.Column) db.Column {
 			c.TypeName = "float64"
 		case "real":
 			c.TypeName = "float32"
-		case "bit", "interval", "uuint", "bit varying", "character", "money", "character varying", "cidr", "inet", "macaddr", "text", "uuid", "xml":
+		case "bit", "bit varying", "interval", "uuint", "character", "money", "character varying", "cidr", "inet", "macaddr", "text", "uuid", "xml":
 			c.TypeName = "string"
 		case `"char"`:
 			c.TypeName = "types.Byte"
@@ -382,14 +645,19 @@ func (p *PostgresDriver) TranslateColumnType(c db.Column) db.Column {
 		case "ARRAY":
 			c.TypeName = getArrayType(c)
 			// Make DBType something like ARRAYinteger for parsing with randomize.Struct
-			c.DBType = c.DBType + *c.ArrType
+			c.DBType = c.DBType + arrayElemType(c)
 		case "USER-DEFINED":
-			if c.UDTName == "hstore" {
+			if c.Composite != nil {
+				c.TypeName = c.Composite.GoName
+			} else if c.UDTName == "hstore" {
 				c.TypeName = "types.HStore"
 				c.DBType = "hstore"
+			} else if c.UDTName == "citext" {
+				c.TypeName = "string"
+				c.CaseInsensitive = true
 			} else {
 				c.TypeName = "string"
-				fmt.Printf("Warning: Incompatible data type detected: %s\n", c.UDTName)
+				p.warnf("incompatible data type %s of column %s, mapped to string", c.UDTName, c.Name)
 			}
 		default:
 			c.TypeName = "string"
@@ -399,9 +667,39 @@ func (p *PostgresDriver) TranslateColumnType(c db.Column) db.Column {
 	return c
 }
 
+// pgArrayElemTypes are the data types of array elements by the udt_name of
+// the element, the array's udt_name without its leading underscore.
+var pgArrayElemTypes = map[string]string{
+	"int2":    "smallint",
+	"int4":    "integer",
+	"int8":    "bigint",
+	"float4":  "real",
+	"float8":  "double precision",
+	"bool":    "boolean",
+	"varchar": "character varying",
+	"bpchar":  "character",
+	"varbit":  "bit varying",
+}
+
+// arrayElemType returns the data type of the elements of an array column:
+// the element_types one when introspection found it, or else the one named
+// by the array's udt_name, e.g. _int4 for integer[]. Multi-dimensional
+// arrays have the udt_name of one-dimensional ones, so they map the same.
+func arrayElemType(c db.Column) string {
+	if c.ArrType != nil {
+		return *c.ArrType
+	}
+
+	elem := strings.TrimPrefix(c.UDTName, "_")
+	if t, ok := pgArrayElemTypes[elem]; ok {
+		return t
+	}
+	return elem
+}
+
 // getArrayType returns the correct boil.Array type for each database type
 func getArrayType(c db.Column) string {
-	switch *c.ArrType {
+	switch arrayElemType(c) {
 	case "bigint", "bigserial", "integer", "serial", "smallint", "smallserial":
 		return "types.Int64Array"
 	case "bytea":
@@ -417,6 +715,13 @@ func getArrayType(c db.Column) string {
 	}
 }
 
+// warnf calls Warnf, if there is one.
+func (p *PostgresDriver) warnf(format string, args ...interface{}) {
+	if p.Warnf != nil {
+		p.Warnf(format, args...)
+	}
+}
+
 // RightQuote is the quoting character for the right side of the identifier
 func (p *PostgresDriver) RightQuote() byte {
 	return '"'
@@ -431,3 +736,54 @@ func (p *PostgresDriver) LeftQuote() byte {
 func (p *PostgresDriver) IndexPlaceholders() bool {
 	return true
 }
+
+// MaxPlaceholders returns 65535, the wire protocol counting bind parameters
+// in 16 bits
+func (p *PostgresDriver) MaxPlaceholders() int {
+	return 65535
+}
+
+// QuoteLiteral quotes s with single quotes, doubling those inside it. A
+// string with backslashes is written as an E'...' escape string with them
+// doubled, so it reads the same whatever standard_conforming_strings is.
+func (p *PostgresDriver) QuoteLiteral(s string) string {
+	s = strings.Replace(s, "'", "''", -1)
+	if !strings.Contains(s, `\`) {
+		return "'" + s + "'"
+	}
+
+	return "E'" + strings.Replace(s, `\`, `\\`, -1) + "'"
+}
+
+// UpsertClause returns an ON CONFLICT clause, DO NOTHING with no
+// updateColumns. Updating needs the conflictColumns of a unique index, so
+// it's an error without them.
+func (p *PostgresDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	if len(conflictColumns) == 0 && len(updateColumns) != 0 {
+		return "", errors.New("postgres needs the conflict columns to upsert with DO UPDATE")
+	}
+
+	clause := "ON CONFLICT "
+	if len(conflictColumns) != 0 {
+		quoted := make([]string, len(conflictColumns))
+		for i, c := range conflictColumns {
+			quoted[i] = pgQuoteIdent(c)
+		}
+		clause += "(" + strings.Join(quoted, ", ") + ") "
+	}
+
+	if len(updateColumns) == 0 {
+		return clause + "DO NOTHING", nil
+	}
+
+	sets := make([]string, len(updateColumns))
+	for i, c := range updateColumns {
+		sets[i] = pgQuoteIdent(c) + " = EXCLUDED." + pgQuoteIdent(c)
+	}
+	return clause + "DO UPDATE SET " + strings.Join(sets, ", "), nil
+}
+
+// pgQuoteIdent quotes name as an identifier, doubling the quotes in it.
+func pgQuoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
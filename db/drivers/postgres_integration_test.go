@@ -0,0 +1,79 @@
+//go:build postgres
+// +build postgres
+
+package drivers
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+// TestPostgresIntegration runs against the database in the
+// SQLGEN_POSTGRES_DSN environment variable, with go test -tags postgres.
+func TestPostgresIntegration(t *testing.T) {
+	dsn := os.Getenv("SQLGEN_POSTGRES_DSN")
+	if len(dsn) == 0 {
+		t.Skip("SQLGEN_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+	p := &PostgresDriver{connStr: dsn}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	fixture := []string{
+		`drop schema if exists sqlgen_test cascade`,
+		`create schema sqlgen_test`,
+		`create table sqlgen_test.pilots (id integer primary key, constraint positive check (id > 0))`,
+		`create table sqlgen_test.jets (
+			id integer primary key,
+			pilot_id integer references sqlgen_test.pilots (id) deferrable initially deferred,
+			copilot_id integer references sqlgen_test.pilots (id) deferrable,
+			owner_id integer references sqlgen_test.pilots (id),
+			constraint positive check (pilot_id > 0 and copilot_id > 0)
+		)`,
+	}
+	for _, query := range fixture {
+		if _, err := p.dbConn.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer p.dbConn.Exec(`drop schema sqlgen_test cascade`)
+
+	fkeys, err := p.ForeignKeyInfo(ctx, "sqlgen_test", "jets")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]bool{
+		"pilot_id":   {true, true},
+		"copilot_id": {true, false},
+		"owner_id":   {false, false},
+	}
+	if len(fkeys) != len(want) {
+		t.Fatalf("want %d foreign keys, got: %#v", len(want), fkeys)
+	}
+	for _, fkey := range fkeys {
+		if got := [2]bool{fkey.Deferrable, fkey.InitiallyDeferred}; got != want[fkey.Column] {
+			t.Errorf("%s: want deferrable, initially deferred: %v, got: %v", fkey.Column, want[fkey.Column], got)
+		}
+	}
+
+	// Both tables have a check named positive.
+	checks, err := p.CheckConstraints(ctx, "sqlgen_test", "jets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantChecks := []db.CheckConstraint{
+		{Name: "positive", Columns: []string{"copilot_id", "pilot_id"}, Expression: "((pilot_id > 0) AND (copilot_id > 0))"},
+	}
+	if !reflect.DeepEqual(checks, wantChecks) {
+		t.Errorf("want:\n%#v\ngot:\n%#v", wantChecks, checks)
+	}
+}
@@ -0,0 +1,336 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mickeyreiss/sqlgen/db"
+)
+
+func TestPostgresBuildQueryStringSSL(t *testing.T) {
+	t.Parallel()
+
+	got := PostgresBuildQueryString("user", "pass", "dbname", "localhost", 5432, "verify-full", "/certs/client.crt", "/certs/client.key", "/certs/ca.crt")
+	want := "user=user password=pass dbname=dbname host=localhost port=5432 sslmode=verify-full sslcert='/certs/client.crt' sslkey='/certs/client.key' sslrootcert='/certs/ca.crt'"
+	if got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+
+	got = PostgresBuildQueryString("user", "", "dbname", "localhost", 5432, "require", "", "", "")
+	want = "user=user dbname=dbname host=localhost port=5432 sslmode=require"
+	if got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+
+	got = PostgresBuildQueryString("user", "", "dbname", "localhost", 5432, "verify-full", "/my certs/client.crt", `C:\certs\o'brien.key`, "/my certs/ca.crt")
+	want = `user=user dbname=dbname host=localhost port=5432 sslmode=verify-full sslcert='/my certs/client.crt' sslkey='C:\\certs\\o\'brien.key' sslrootcert='/my certs/ca.crt'`
+	if got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+}
+
+func TestPostgresTranslateColumnTypeCitext(t *testing.T) {
+	t.Parallel()
+
+	p := &PostgresDriver{}
+
+	c := p.TranslateColumnType(db.Column{Name: "email", DBType: "USER-DEFINED", UDTName: "citext"})
+	if c.TypeName != "string" || !c.CaseInsensitive {
+		t.Errorf("want a case insensitive string, got: %#v", c)
+	}
+
+	c = p.TranslateColumnType(db.Column{Name: "email", DBType: "USER-DEFINED", UDTName: "citext", Nullable: true})
+	if c.TypeName != "null.String" || !c.CaseInsensitive {
+		t.Errorf("want a case insensitive null.String, got: %#v", c)
+	}
+
+	c = p.TranslateColumnType(db.Column{Name: "name", DBType: "text"})
+	if c.CaseInsensitive {
+		t.Error("want text to be case sensitive")
+	}
+}
+
+func TestPostgresCheckConstraints(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-postgres-checks-test", &recordingDriver{rows: [][]driver.Value{
+		{"price_positive", "(price > (0)::numeric)", "price"},
+		{"sale_below_price", "(sale_price < price)", "price"},
+		{"sale_below_price", "(sale_price < price)", "sale_price"},
+		{"always", "true", nil},
+	}})
+	conn, err := sql.Open("sqlgen-postgres-checks-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	checks, err := (&PostgresDriver{dbConn: conn}).CheckConstraints(context.Background(), "public", "products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []db.CheckConstraint{
+		{Name: "price_positive", Columns: []string{"price"}, Expression: "(price > (0)::numeric)"},
+		{Name: "sale_below_price", Columns: []string{"price", "sale_price"}, Expression: "(sale_price < price)"},
+		{Name: "always", Expression: "true"},
+	}
+	if !reflect.DeepEqual(checks, want) {
+		t.Errorf("want:\n%#v\ngot:\n%#v", want, checks)
+	}
+}
+
+func TestPostgresQuoteLiteral(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		In   string
+		Want string
+	}{
+		{"plain", "'plain'"},
+		{"it's", "'it''s'"},
+		{`C:\temp`, `E'C:\\temp'`},
+		{`it's a \'`, `E'it''s a \\'''`},
+		{"", "''"},
+	}
+
+	p := &PostgresDriver{}
+	for i, test := range tests {
+		if got := p.QuoteLiteral(test.In); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+
+	r := &RedshiftDriver{PostgresDriver: p}
+	if got, want := r.QuoteLiteral(`it's a \'`), `'it''s a \\'''`; got != want {
+		t.Errorf("want: %s, got: %s", want, got)
+	}
+}
+
+func TestPostgresMaxPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	if got := (&PostgresDriver{}).MaxPlaceholders(); got != 65535 {
+		t.Errorf("want 65535 placeholders, got %d", got)
+	}
+}
+
+func TestPostgresTranslateColumnTypeBinary(t *testing.T) {
+	t.Parallel()
+
+	p := &PostgresDriver{}
+
+	tests := []struct {
+		Column db.Column
+		Want   string
+	}{
+		{db.Column{Name: "data", DBType: "bytea"}, "[]byte"},
+		{db.Column{Name: "data", DBType: "bytea", Nullable: true}, "null.Bytes"},
+		{db.Column{Name: "flags", DBType: "bit varying", MaxLength: 8}, "string"},
+		{db.Column{Name: "flags", DBType: "bit varying", MaxLength: 8, Nullable: true}, "null.String"},
+		{db.Column{Name: "flag", DBType: "bit", MaxLength: 1}, "string"},
+	}
+
+	for i, test := range tests {
+		c := p.TranslateColumnType(test.Column)
+		if c.TypeName != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, c.TypeName)
+		}
+		if c.MaxLength != test.Column.MaxLength {
+			t.Errorf("%d) want the bit length kept, got: %d", i, c.MaxLength)
+		}
+	}
+}
+
+func TestPostgresColumnsBitLength(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-postgres-bit-test", &recordingDriver{rows: [][]driver.Value{
+		{"flags", "bit varying", "varbit", nil, nil, false, false, nil, int64(8), false, "pg_catalog"},
+		{"flag", "bit", "bit", nil, nil, true, false, nil, int64(1), false, "pg_catalog"},
+	}})
+	conn, err := sql.Open("sqlgen-postgres-bit-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	p := &PostgresDriver{dbConn: conn}
+
+	columns, err := p.Columns(context.Background(), "public", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		TypeName  string
+		MaxLength int
+	}{
+		{"string", 8},
+		{"null.String", 1},
+	}
+	if len(columns) != len(want) {
+		t.Fatalf("want %d columns, got: %#v", len(want), columns)
+	}
+	for i, c := range columns {
+		c = p.TranslateColumnType(c)
+		if c.TypeName != want[i].TypeName || c.MaxLength != want[i].MaxLength {
+			t.Errorf("%d) want: %s(%d), got: %s(%d)", i, want[i].TypeName, want[i].MaxLength, c.TypeName, c.MaxLength)
+		}
+	}
+}
+
+func TestPostgresUpsertClause(t *testing.T) {
+	t.Parallel()
+
+	p := &PostgresDriver{}
+
+	tests := []struct {
+		Conflict []string
+		Update   []string
+		Want     string
+	}{
+		{[]string{"id"}, []string{"name", "email"}, `ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "email" = EXCLUDED."email"`},
+		{[]string{"a", "b"}, nil, `ON CONFLICT ("a", "b") DO NOTHING`},
+		{nil, nil, `ON CONFLICT DO NOTHING`},
+		{[]string{`odd"id`}, []string{"back`tick"}, `ON CONFLICT ("odd""id") DO UPDATE SET "back` + "`" + `tick" = EXCLUDED."back` + "`" + `tick"`},
+	}
+
+	for i, test := range tests {
+		got, err := p.UpsertClause(test.Conflict, test.Update)
+		if err != nil {
+			t.Errorf("%d) %s", i, err)
+		} else if got != test.Want {
+			t.Errorf("%d) want: %s\ngot:  %s", i, test.Want, got)
+		}
+	}
+
+	if _, err := p.UpsertClause(nil, []string{"name"}); err == nil {
+		t.Error("want an error updating without a conflict target")
+	}
+}
+
+func TestPostgresTranslateColumnTypeArray(t *testing.T) {
+	t.Parallel()
+
+	p := &PostgresDriver{}
+	text, integer := "text", "integer"
+
+	tests := []struct {
+		Column db.Column
+		Want   string
+		DBType string
+	}{
+		{db.Column{Name: "tags", DBType: "ARRAY", UDTName: "_text", ArrType: &text}, "types.StringArray", "ARRAYtext"},
+		{db.Column{Name: "tags", DBType: "ARRAY", UDTName: "_text", ArrType: &text, Nullable: true}, "types.NullStringArray", "ARRAYtext"},
+		{db.Column{Name: "ids", DBType: "ARRAY", UDTName: "_int4", ArrType: &integer}, "types.Int64Array", "ARRAYinteger"},
+		{db.Column{Name: "ids", DBType: "ARRAY", UDTName: "_int8"}, "types.Int64Array", "ARRAYbigint"},
+		{db.Column{Name: "ids", DBType: "ARRAY", UDTName: "_uuid", Nullable: true}, "types.NullStringArray", "ARRAYuuid"},
+		{db.Column{Name: "blobs", DBType: "ARRAY", UDTName: "_bytea"}, "types.BytesArray", "ARRAYbytea"},
+		{db.Column{Name: "flags", DBType: "ARRAY", UDTName: "_bool", Nullable: true}, "types.NullBoolArray", "ARRAYboolean"},
+		// integer[][] has the udt_name of integer[].
+		{db.Column{Name: "grid", DBType: "ARRAY", UDTName: "_int4"}, "types.Int64Array", "ARRAYinteger"},
+		{db.Column{Name: "tags", DBType: "ARRAY", UDTName: "_text", Default: "'{}'::text[]"}, "types.StringArray", "ARRAYtext"},
+	}
+
+	for i, test := range tests {
+		c := p.TranslateColumnType(test.Column)
+		if c.TypeName != test.Want || len(c.PkgName) != 0 || c.DBType != test.DBType {
+			t.Errorf("%d) want: %s %s, got: %s (%s) %s", i, test.Want, test.DBType, c.TypeName, c.PkgName, c.DBType)
+		}
+		if c.Default != test.Column.Default {
+			t.Errorf("%d) want the default kept, got: %s", i, c.Default)
+		}
+	}
+}
+
+func TestPostgresCompositeForeignKey(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-postgres-composite-fkey-test", &recordingDriver{rows: [][]driver.Value{
+		{"shipments_order_fkey", "shipments", "order_region", "orders", "region", false, false, "CASCADE", "NO ACTION"},
+		{"shipments_order_fkey", "shipments", "order_number", "orders", "number", false, false, "CASCADE", "NO ACTION"},
+	}})
+	conn, err := sql.Open("sqlgen-postgres-composite-fkey-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	p := &PostgresDriver{dbConn: conn}
+
+	fkeys, err := p.ForeignKeyInfo(context.Background(), "public", "shipments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fkeys) != 1 {
+		t.Fatalf("want the two columns grouped into one key, got: %#v", fkeys)
+	}
+	if want := []string{"order_region", "order_number"}; !reflect.DeepEqual(fkeys[0].Columns, want) {
+		t.Errorf("want: %v, got: %v", want, fkeys[0].Columns)
+	}
+	if want := []string{"region", "number"}; !reflect.DeepEqual(fkeys[0].ForeignColumns, want) {
+		t.Errorf("want: %v, got: %v", want, fkeys[0].ForeignColumns)
+	}
+}
+
+func TestPostgresCompositeType(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-postgres-composite-test", &recordingDriver{rows: [][]driver.Value{
+		{"street", "text", "text", nil, nil},
+		{"zip", "integer", "int4", nil, nil},
+	}})
+	conn, err := sql.Open("sqlgen-postgres-composite-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	p := &PostgresDriver{dbConn: conn}
+
+	composite, err := p.compositeType(context.Background(), "public", "address")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &db.CompositeType{Name: "address", GoName: "Address", Fields: []db.Column{
+		{Name: "street", DBType: "text", UDTName: "text", TypeName: "null.String", Nullable: true},
+		{Name: "zip", DBType: "integer", UDTName: "int4", TypeName: "null.Int", Nullable: true},
+	}}
+	if !reflect.DeepEqual(composite, want) {
+		t.Errorf("want:\n%#v\ngot:\n%#v", want, composite)
+	}
+
+	c := p.TranslateColumnType(db.Column{Name: "home", DBType: "USER-DEFINED", UDTName: "address", Composite: composite})
+	if c.TypeName != "Address" {
+		t.Errorf("want Address, got: %s", c.TypeName)
+	}
+	c = p.TranslateColumnType(db.Column{Name: "home", DBType: "USER-DEFINED", UDTName: "address", Composite: composite, Nullable: true})
+	if c.TypeName != "*Address" {
+		t.Errorf("want *Address, got: %s", c.TypeName)
+	}
+}
+
+func TestPostgresTranslateColumnTypeWarnings(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	p := &PostgresDriver{Warnf: func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}}
+
+	for _, nullable := range []bool{false, true} {
+		c := p.TranslateColumnType(db.Column{Name: "shape", DBType: "USER-DEFINED", UDTName: "box2d", Nullable: nullable})
+		if c.TypeName != "string" {
+			t.Errorf("want an unknown type mapped to string, got: %s", c.TypeName)
+		}
+	}
+
+	want := "incompatible data type box2d of column shape, mapped to string"
+	if len(warnings) != 2 || warnings[0] != want || warnings[1] != want {
+		t.Errorf("want: %q twice, got: %q", want, warnings)
+	}
+
+	// Without a Warnf the warning is dropped.
+	(&PostgresDriver{}).TranslateColumnType(db.Column{Name: "shape", DBType: "USER-DEFINED", UDTName: "box2d"})
+}
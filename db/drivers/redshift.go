@@ -0,0 +1,181 @@
+package drivers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mickeyreiss/sqlgen/db"
+	"github.com/pkg/errors"
+)
+
+// RedshiftDriver introspects Amazon Redshift. It is the PostgresDriver with
+// the queries Redshift's older catalog can't run replaced, and the DISTKEY
+// and SORTKEY columns of tables introspected. Redshift doesn't enforce
+// primary, unique or foreign keys, but they are declared and read all the
+// same, so relationships are generated from them. It has no secondary
+// indexes, and no upsert clause.
+type RedshiftDriver struct {
+	*PostgresDriver
+}
+
+// NewRedshiftDriver takes the database connection details as parameters and
+// returns a pointer to a RedshiftDriver object, which must be opened and
+// closed like a PostgresDriver.
+func NewRedshiftDriver(user, pass, dbname, host string, port int, sslmode, sslcert, sslkey, sslrootcert string) *RedshiftDriver {
+	return &RedshiftDriver{PostgresDriver: NewPostgresDriver(user, pass, dbname, host, port, sslmode, sslcert, sslkey, sslrootcert)}
+}
+
+// Columns retrieves the columns of a table from svv_columns, which unlike
+// information_schema.columns has their comments.
+func (r *RedshiftDriver) Columns(ctx context.Context, schema, tableName string) ([]db.Column, error) {
+	var columns []db.Column
+
+	rows, err := r.conn().QueryContext(ctx, `
+		select
+		c.column_name,
+		c.data_type,
+		c.column_default,
+		c.is_nullable = 'YES' as is_nullable,
+		(select exists(
+			select 1
+			from information_schema.table_constraints tc
+			inner join information_schema.constraint_column_usage as ccu on tc.constraint_name = ccu.constraint_name
+			where tc.table_schema = $1 and tc.constraint_type in ('PRIMARY KEY', 'UNIQUE') and ccu.constraint_schema = $1 and ccu.table_name = c.table_name and ccu.column_name = c.column_name and
+				(select count(*) from information_schema.constraint_column_usage where constraint_schema = $1 and constraint_name = tc.constraint_name) = 1
+		)) as is_unique,
+		c.remarks,
+		c.character_maximum_length
+		from svv_columns as c
+		where c.table_schema = $1 and c.table_name = $2
+		order by c.ordinal_position
+	`, schema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var colName, colType string
+		var defaultValue, comment *string
+		var nullable, unique bool
+		var maxLength *int
+		if err := rows.Scan(&colName, &colType, &defaultValue, &nullable, &unique, &comment, &maxLength); err != nil {
+			return nil, errors.Wrapf(err, "unable to scan for table %s", tableName)
+		}
+
+		column := db.Column{
+			Name:     colName,
+			DBType:   colType,
+			Nullable: nullable,
+			Unique:   unique,
+		}
+		if defaultValue != nil {
+			column.Default = *defaultValue
+			column.AutoIncrement = strings.HasPrefix(column.Default, `"identity"(`) || strings.HasPrefix(column.Default, "identity(")
+		}
+		if comment != nil {
+			column.Comment = *comment
+		}
+		if maxLength != nil {
+			column.MaxLength = *maxLength
+		}
+
+		columns = append(columns, column)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// TableComment returns the comment of a table, empty if it has none.
+func (r *RedshiftDriver) TableComment(ctx context.Context, schema, tableName string) (string, error) {
+	var comment string
+
+	query := `
+	select coalesce((
+		select d.description
+		from pg_description d
+			inner join pg_class c on c.oid = d.objoid and d.objsubid = 0
+			inner join pg_namespace n on n.oid = c.relnamespace
+		where n.nspname = $1 and c.relname = $2
+	), '')`
+
+	if err := r.conn().QueryRowContext(ctx, query, schema, tableName).Scan(&comment); err != nil {
+		return "", err
+	}
+
+	return comment, nil
+}
+
+// IndexInfo returns no indexes, Redshift having none.
+func (r *RedshiftDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]db.Index, error) {
+	return nil, nil
+}
+
+// CheckConstraints returns no constraints, Redshift having no CHECK
+// constraints.
+func (r *RedshiftDriver) CheckConstraints(ctx context.Context, schema, tableName string) ([]db.CheckConstraint, error) {
+	return nil, nil
+}
+
+// DistributionKeys returns the DISTKEY column of a table, empty if its rows
+// are distributed evenly or copied to every node, and its SORTKEY columns in
+// order. They are read from pg_attribute, like pg_table_def does, but without
+// pg_table_def's restriction to the schemas on the search_path.
+func (r *RedshiftDriver) DistributionKeys(ctx context.Context, schema, tableName string) (string, []string, error) {
+	var distKey string
+	var sortKeys []string
+
+	query := `
+	select a.attname, a.attisdistkey, a.attsortkeyord
+	from pg_attribute a
+		inner join pg_class c on c.oid = a.attrelid
+		inner join pg_namespace n on n.oid = c.relnamespace
+	where n.nspname = $1 and c.relname = $2 and a.attnum > 0 and not a.attisdropped and
+		(a.attisdistkey or a.attsortkeyord <> 0)
+	order by abs(a.attsortkeyord)`
+
+	rows, err := r.conn().QueryContext(ctx, query, schema, tableName)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column string
+		var isDistKey bool
+		var sortKeyOrd int
+		if err := rows.Scan(&column, &isDistKey, &sortKeyOrd); err != nil {
+			return "", nil, err
+		}
+
+		if isDistKey {
+			distKey = column
+		}
+		// Interleaved sort keys have negative ordinals.
+		if sortKeyOrd != 0 {
+			sortKeys = append(sortKeys, column)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", nil, err
+	}
+
+	return distKey, sortKeys, nil
+}
+
+// QuoteLiteral quotes s with single quotes, doubling those inside it and its
+// backslashes, which Redshift reads as escapes. It has no E'...' strings.
+func (r *RedshiftDriver) QuoteLiteral(s string) string {
+	return "'" + strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", "''", -1) + "'"
+}
+
+// UpsertClause returns an error: Redshift has no ON CONFLICT, and upserts
+// with MERGE or a staging table instead.
+func (r *RedshiftDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	return "", errors.New("redshift has no upsert clause, use MERGE or a staging table")
+}
@@ -0,0 +1,76 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+// openRecording opens a connection to a recordingDriver answering every
+// query with row.
+func openRecording(t *testing.T, name string, row []driver.Value) *sql.DB {
+	sql.Register(name, &recordingDriver{row: row})
+
+	conn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestRedshiftDistributionKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Row      []driver.Value
+		DistKey  string
+		SortKeys []string
+	}{
+		{[]driver.Value{"user_id", true, int64(0)}, "user_id", nil},
+		{[]driver.Value{"created_at", false, int64(1)}, "", []string{"created_at"}},
+		{[]driver.Value{"id", true, int64(-1)}, "id", []string{"id"}},
+	}
+
+	for i, test := range tests {
+		conn := openRecording(t, "sqlgen-redshift-dist-test-"+string(rune('a'+i)), test.Row)
+		r := &RedshiftDriver{PostgresDriver: &PostgresDriver{dbConn: conn}}
+
+		distKey, sortKeys, err := r.DistributionKeys(context.Background(), "public", "events")
+		conn.Close()
+		if err != nil {
+			t.Errorf("%d) %s", i, err)
+			continue
+		}
+		if distKey != test.DistKey || !reflect.DeepEqual(sortKeys, test.SortKeys) {
+			t.Errorf("%d) want: %q %v, got: %q %v", i, test.DistKey, test.SortKeys, distKey, sortKeys)
+		}
+	}
+}
+
+func TestRedshiftForeignKeyInfo(t *testing.T) {
+	t.Parallel()
+
+	// Redshift doesn't enforce foreign keys, but declares them in the catalog.
+	conn := openRecording(t, "sqlgen-redshift-fkey-test", []driver.Value{"events_user_id_fkey", "events", "user_id", "users", "id", false, false, "CASCADE", "NO ACTION"})
+	defer conn.Close()
+	r := &RedshiftDriver{PostgresDriver: &PostgresDriver{dbConn: conn}}
+
+	fkeys, err := r.ForeignKeyInfo(context.Background(), "public", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fkeys) != 1 || fkeys[0].Name != "events_user_id_fkey" || fkeys[0].Column != "user_id" || fkeys[0].ForeignTable != "users" || fkeys[0].ForeignColumn != "id" || fkeys[0].OnDelete != "CASCADE" {
+		t.Errorf("want the informational foreign key read, got: %#v", fkeys)
+	}
+}
+
+func TestRedshiftUpsertClause(t *testing.T) {
+	t.Parallel()
+
+	r := &RedshiftDriver{PostgresDriver: &PostgresDriver{}}
+	if clause, err := r.UpsertClause([]string{"id"}, []string{"name"}); err == nil {
+		t.Errorf("want an error, got: %q", clause)
+	}
+}
@@ -0,0 +1,59 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// queryer is what a driver runs its introspection queries on: the *sql.DB,
+// or the snapshot transaction.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Snapshot runs a driver's introspection in one read-only, repeatable read
+// transaction when UseSnapshot is set, so the many information_schema
+// queries all see the same view of a database that is changing underneath
+// them.
+type Snapshot struct {
+	UseSnapshot bool
+
+	tx *sql.Tx
+}
+
+// begin starts the snapshot transaction on conn, if UseSnapshot is set. It
+// closes conn if the transaction can't be begun, as Open then fails and the
+// driver is never closed.
+func (s *Snapshot) begin(conn *sql.DB) error {
+	if !s.UseSnapshot {
+		return nil
+	}
+
+	var err error
+	s.tx, err = conn.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "unable to begin snapshot transaction")
+	}
+	return nil
+}
+
+// queryer returns the snapshot transaction if there is one, conn otherwise.
+func (s *Snapshot) queryer(conn *sql.DB) queryer {
+	if s.tx != nil {
+		return s.tx
+	}
+	return conn
+}
+
+// commit ends the snapshot transaction, if there is one. Nothing was
+// written, so there is nothing to do if it fails.
+func (s *Snapshot) commit() {
+	if s.tx != nil {
+		s.tx.Commit()
+		s.tx = nil
+	}
+}
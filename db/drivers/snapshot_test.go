@@ -0,0 +1,267 @@
+package drivers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingDriver is a database/sql driver that answers every query with
+// rows, or just row, or no rows if both are nil, recording what happened on
+// its connections and the queries run on them. Beginning a transaction fails with failBegin if it is
+// set.
+type recordingDriver struct {
+	row       []driver.Value
+	rows      [][]driver.Value
+	failBegin error
+
+	mu       sync.Mutex
+	events   []string
+	queries  []string
+	openRows int
+}
+
+func (d *recordingDriver) record(event string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{d: d}, nil
+}
+
+type recordingConn struct {
+	d    *recordingDriver
+	inTx bool
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, query)
+	c.d.mu.Unlock()
+	return recordingStmt{c: c}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.d.failBegin != nil {
+		return nil, c.d.failBegin
+	}
+	if sql.IsolationLevel(opts.Isolation) != sql.LevelRepeatableRead || !opts.ReadOnly {
+		c.d.record("begin")
+	} else {
+		c.d.record("begin snapshot")
+	}
+	c.inTx = true
+	return recordingTx{c: c}, nil
+}
+
+type recordingTx struct{ c *recordingConn }
+
+func (t recordingTx) Commit() error {
+	t.c.inTx = false
+	t.c.d.record("commit")
+	return nil
+}
+
+func (t recordingTx) Rollback() error {
+	t.c.inTx = false
+	t.c.d.record("rollback")
+	return nil
+}
+
+type recordingStmt struct{ c *recordingConn }
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.c.inTx {
+		s.c.d.record("query in tx")
+	} else {
+		s.c.d.record("query")
+	}
+	s.c.d.mu.Lock()
+	s.c.d.openRows++
+	s.c.d.mu.Unlock()
+	rows := s.c.d.rows
+	if rows == nil && s.c.d.row != nil {
+		rows = [][]driver.Value{s.c.d.row}
+	}
+	return &recordingRows{d: s.c.d, rows: rows}, nil
+}
+
+type recordingRows struct {
+	d    *recordingDriver
+	rows [][]driver.Value
+}
+
+func (r *recordingRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+
+	cols := make([]string, len(r.rows[0]))
+	for i := range cols {
+		cols[i] = "c" + strconv.Itoa(i)
+	}
+	return cols
+}
+
+func (r *recordingRows) Close() error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	r.d.openRows--
+	return nil
+}
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	rec := &recordingDriver{}
+	sql.Register("sqlgen-snapshot-test", rec)
+
+	conn, err := sql.Open("sqlgen-snapshot-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &MySQLDriver{dbConn: conn}
+	m.UseSnapshot = true
+	if err := m.begin(m.dbConn); err != nil {
+		t.Fatal(err)
+	}
+
+	// The recorded connection has no tables, so introspect one by hand.
+	if _, err := m.TableNames(context.Background(), "schema", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Columns(context.Background(), "schema", "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.PrimaryKeyInfo(context.Background(), "schema", "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ForeignKeyInfo(context.Background(), "schema", "users"); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	if len(rec.events) < 4 {
+		t.Fatalf("want several queries, got: %v", rec.events)
+	}
+	if first, last := rec.events[0], rec.events[len(rec.events)-1]; first != "begin snapshot" || last != "commit" {
+		t.Errorf("want a snapshot begun first and committed last, got: %v", rec.events)
+	}
+	for _, e := range rec.events[1 : len(rec.events)-1] {
+		if e != "query in tx" {
+			t.Errorf("want every query in the one transaction, got: %s", strings.Join(rec.events, ", "))
+			break
+		}
+	}
+}
+
+func TestSnapshotBeginFails(t *testing.T) {
+	t.Parallel()
+
+	sql.Register("sqlgen-snapshot-begin-test", &recordingDriver{failBegin: errors.New("boom")})
+
+	conn, err := sql.Open("sqlgen-snapshot-begin-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &MySQLDriver{dbConn: conn}
+	m.UseSnapshot = true
+	if err := m.begin(m.dbConn); err == nil {
+		t.Fatal("want an error beginning the snapshot")
+	}
+	if err := conn.Ping(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Errorf("want the connection closed, got: %v", err)
+	}
+}
+
+func TestDriversCloseRows(t *testing.T) {
+	t.Parallel()
+
+	// A two column row fails every scan, returning before the rows are read
+	// to the end, which would otherwise have closed them.
+	rec := &recordingDriver{row: []driver.Value{"x", "y"}}
+	sql.Register("sqlgen-close-rows-test", rec)
+
+	conn, err := sql.Open("sqlgen-close-rows-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	m := &MySQLDriver{dbConn: conn}
+	p := &PostgresDriver{dbConn: conn}
+	c := &CockroachDriver{PostgresDriver: &PostgresDriver{dbConn: conn}}
+	r := &RedshiftDriver{PostgresDriver: &PostgresDriver{dbConn: conn}}
+	o := &OracleDriver{dbConn: conn}
+
+	ctx := context.Background()
+	calls := []func() error{
+		func() error { _, err := m.Schemas(ctx); return err },
+		func() error { _, err := m.TableNames(ctx, "schema", nil, nil); return err },
+		func() error { _, err := m.Columns(ctx, "schema", "users"); return err },
+		func() error { _, err := m.PrimaryKeyInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := m.ForeignKeyInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := m.IndexInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := p.Schemas(ctx); return err },
+		func() error { _, err := p.TableNames(ctx, "schema", nil, nil); return err },
+		func() error { _, err := p.Columns(ctx, "schema", "users"); return err },
+		func() error { _, err := p.PrimaryKeyInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := p.ForeignKeyInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := p.CheckConstraints(ctx, "schema", "users"); return err },
+		func() error { _, err := c.Schemas(ctx); return err },
+		func() error { _, err := c.Columns(ctx, "schema", "users"); return err },
+		func() error { _, err := c.ForeignKeyInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := c.IndexInfo(ctx, "schema", "users"); return err },
+		func() error { _, err := r.Columns(ctx, "schema", "users"); return err },
+		func() error { _, _, err := r.DistributionKeys(ctx, "schema", "users"); return err },
+		func() error { _, err := o.Schemas(ctx); return err },
+		func() error { _, err := o.TableNames(ctx, "SCHEMA", nil, nil); return err },
+		func() error { _, err := o.Columns(ctx, "SCHEMA", "USERS"); return err },
+		func() error { _, err := o.ForeignKeyInfo(ctx, "SCHEMA", "USERS"); return err },
+	}
+
+	for i, call := range calls {
+		if err := call(); err == nil {
+			t.Errorf("%d) want a scan error", i)
+		}
+
+		rec.mu.Lock()
+		open := rec.openRows
+		rec.mu.Unlock()
+		if open != 0 {
+			t.Errorf("%d) want every rows closed, got %d open", i, open)
+		}
+	}
+}
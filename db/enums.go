@@ -0,0 +1,87 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Enum is a string-backed Go type generated for enum columns, to be declared
+// once with a constant per value.
+type Enum struct {
+	// TypeName is the Go type, e.g. PostStatus or Mood.
+	TypeName string
+	// Values are the enum's values in their declared order.
+	Values []string
+}
+
+// Enums returns the enum types of the columns of tables, sorted by TypeName.
+// A Postgres enum type used by several columns, in one table or many, is
+// returned once. Columns that share a TypeName but not their values are an
+// error, one type can't declare both, as is a TypeName that is also a
+// table's struct name, or that two enums end up with, e.g. a named Postgres
+// enum post_status and the per-column type of posts.status.
+func Enums(tables []Table) ([]Enum, error) {
+	models := make(map[string]string)
+	for _, t := range tables {
+		if !t.IsJoinTable {
+			models[t.GoName] = t.Name
+		}
+	}
+
+	// firsts are the index in enums, the column and the source of each
+	// TypeName's first column: the Postgres enum type it was named for, or
+	// the column itself.
+	type first struct {
+		i      int
+		column string
+		source string
+	}
+	firsts := make(map[string]first)
+	var enums []Enum
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if !c.IsEnumType() {
+				continue
+			}
+
+			column := t.Name + "." + c.Name
+			if model, ok := models[c.TypeName]; ok {
+				return nil, errors.Errorf("enum type %s of %s is also the struct name of %s", c.TypeName, column, model)
+			}
+
+			source := column
+			if name := enumTypeName(c.DBType); name != "" {
+				source = "enum " + name
+			}
+			f, ok := firsts[c.TypeName]
+			if !ok {
+				firsts[c.TypeName] = first{i: len(enums), column: column, source: source}
+				enums = append(enums, Enum{TypeName: c.TypeName, Values: c.EnumValues})
+				continue
+			}
+			if f.source != source {
+				return nil, errors.Errorf("enum type %s is the type of both %s and %s", c.TypeName, f.source, source)
+			}
+			if values := enums[f.i].Values; !sameValues(values, c.EnumValues) {
+				return nil, errors.Errorf("enum type %s has the values %q in %s but %q in %s", c.TypeName, values, f.column, c.EnumValues, column)
+			}
+		}
+	}
+
+	sort.Slice(enums, func(i, j int) bool { return enums[i].TypeName < enums[j].TypeName })
+	return enums, nil
+}
+
+// sameValues reports whether a and b are the same values in the same order.
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
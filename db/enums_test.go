@@ -0,0 +1,78 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnums(t *testing.T) {
+	t.Parallel()
+
+	moods := []string{"happy", "sad"}
+	tables := []Table{
+		{
+			Name:   "users",
+			GoName: "User",
+			Columns: []Column{
+				{Name: "mood", TypeName: "Mood", DBType: "enum.mood('happy','sad')", EnumValues: moods},
+				{Name: "status", TypeName: "UserStatus", DBType: "enum('active')", EnumValues: []string{"active"}},
+				{Name: "backup_mood", TypeName: "null.String", EnumValues: moods, Nullable: true},
+			},
+		},
+		{
+			Name:   "posts",
+			GoName: "Post",
+			Columns: []Column{
+				{Name: "author_mood", TypeName: "Mood", DBType: "enum.mood('happy','sad')", EnumValues: moods},
+				{Name: "flags", TypeName: "Set", PkgName: "github.com/mickeyreiss/sqlgen/types", EnumValues: []string{"pinned"}},
+				{Name: "title", TypeName: "string"},
+			},
+		},
+	}
+
+	want := []Enum{
+		{TypeName: "Mood", Values: moods},
+		{TypeName: "UserStatus", Values: []string{"active"}},
+	}
+	got, err := Enums(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %#v\ngot:  %#v", want, got)
+	}
+
+	tables[1].Columns[0].EnumValues = []string{"happy", "sad", "meh"}
+	if _, err := Enums(tables); err == nil || !strings.Contains(err.Error(), "users.mood") || !strings.Contains(err.Error(), "posts.author_mood") {
+		t.Errorf("want an error naming both columns of Mood, got: %v", err)
+	}
+	tables[1].Columns[0].EnumValues = moods
+
+	collisions := []struct {
+		Tables []Table
+		Want   string
+	}{
+		{
+			append(tables, Table{Name: "user_statuses", GoName: "UserStatus"}),
+			"enum type UserStatus of users.status is also the struct name of user_statuses",
+		},
+		{
+			append(tables, Table{Name: "comments", GoName: "Comment", Columns: []Column{
+				{Name: "status", TypeName: "UserStatus", DBType: "enum.user_status('active')", EnumValues: []string{"active"}},
+			}}),
+			"enum type UserStatus is the type of both users.status and enum user_status",
+		},
+		{
+			append(tables, Table{Name: "user", GoName: "User", Columns: []Column{
+				{Name: "status", TypeName: "UserStatus", DBType: "enum('active')", EnumValues: []string{"active"}},
+			}}),
+			"enum type UserStatus is the type of both users.status and user.status",
+		},
+	}
+	for i, test := range collisions {
+		if _, err := Enums(test.Tables); err == nil || err.Error() != test.Want {
+			t.Errorf("%d) want: %s, got: %v", i, test.Want, err)
+		}
+	}
+}
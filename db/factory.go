@@ -0,0 +1,30 @@
+package db
+
+// FactorySpec hints at how to produce a valid random value for a column, for
+// generating test data.
+type FactorySpec struct {
+	// TypeName and PkgName are the column's Go type, as on Column.
+	TypeName string
+	PkgName  string
+	// MaxLength, when not 0, is the longest value the column holds.
+	MaxLength int
+	// EnumValues, when set, are the only values the column holds.
+	EnumValues []string
+	Nullable   bool
+	Unique     bool
+	// Check is the column's range or IN check constraint, if any.
+	Check *ColumnCheck
+}
+
+// FactorySpec returns the hints for producing a valid value for the column.
+func (c Column) FactorySpec() FactorySpec {
+	return FactorySpec{
+		TypeName:   c.TypeName,
+		PkgName:    c.PkgName,
+		MaxLength:  c.MaxLength,
+		EnumValues: c.EnumValues,
+		Nullable:   c.Nullable,
+		Unique:     c.Unique,
+		Check:      c.Check,
+	}
+}
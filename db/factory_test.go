@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestColumnFactorySpec(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockTables["pilots"] = []Column{
+		{Name: "id", TypeName: "int", DBType: "int", FullDBType: "int(11)", Unique: true},
+		{Name: "code", TypeName: "string", DBType: "varchar", FullDBType: "varchar(10)"},
+		{Name: "status", TypeName: "string", DBType: "enum('draft','published')", FullDBType: "enum('draft','published')"},
+		{Name: "note", TypeName: "null.String", DBType: "varchar", FullDBType: "varchar(255)", Nullable: true},
+	}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols := tables[0].Columns
+	tests := []struct {
+		Column Column
+		Want   FactorySpec
+	}{
+		{cols[0], FactorySpec{TypeName: "int", Unique: true}},
+		{cols[1], FactorySpec{TypeName: "string", MaxLength: 10}},
+		{cols[2], FactorySpec{TypeName: "PilotStatus", EnumValues: []string{"draft", "published"}}},
+		{cols[3], FactorySpec{TypeName: "null.String", MaxLength: 255, Nullable: true}},
+	}
+
+	for i, test := range tests {
+		if got := test.Column.FactorySpec(); !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("%d) want: %#v\ngot:  %#v", i, test.Want, got)
+		}
+	}
+}
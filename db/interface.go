@@ -1,15 +1,27 @@
 // Package db supplies database abstractions.
 package db
 
-import "github.com/pkg/errors"
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vattle/sqlboiler/strmangle"
+)
 
 // Interface for a database driver. Functionality required to support a specific
 // database type (eg, MySQL, Postgres etc.)
 type Interface interface {
-	TableNames(schema string, whitelist, blacklist []string) ([]string, error)
-	Columns(schema, tableName string) ([]Column, error)
-	PrimaryKeyInfo(schema, tableName string) (*PrimaryKey, error)
-	ForeignKeyInfo(schema, tableName string) ([]ForeignKey, error)
+	// Schemas lists the schemas tables can be generated from, leaving out
+	// the database's own system schemas.
+	Schemas(ctx context.Context) ([]string, error)
+	TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error)
+	Columns(ctx context.Context, schema, tableName string) ([]Column, error)
+	PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*PrimaryKey, error)
+	ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]ForeignKey, error)
 
 	// TranslateColumnType takes a Database column type and returns a go column type.
 	TranslateColumnType(Column) Column
@@ -33,56 +45,395 @@ type Interface interface {
 	LeftQuote() byte
 	RightQuote() byte
 	IndexPlaceholders() bool
+	// MaxPlaceholders is the most placeholders a single statement may bind,
+	// so renderers can chunk batch inserts.
+	MaxPlaceholders() int
+	// UpsertClause is the suffix turning an INSERT into an upsert on the
+	// dialect: updating updateColumns to the inserted values when a row
+	// conflicts on conflictColumns, or leaving the row as is when
+	// updateColumns is empty. It is an error if the dialect can't upsert
+	// with the columns given.
+	UpsertClause(conflictColumns, updateColumns []string) (string, error)
+	// QuoteLiteral quotes s as a string literal of the dialect, escaping
+	// it so it can be written into generated SQL, e.g. seed data.
+	QuoteLiteral(s string) string
+}
+
+// IndexInterface is implemented by drivers that can introspect secondary
+// indexes. It is optional: tables built from a driver that doesn't implement
+// it have no Indexes.
+type IndexInterface interface {
+	IndexInfo(ctx context.Context, schema, tableName string) ([]Index, error)
+}
+
+// CheckInterface is implemented by drivers that can introspect CHECK
+// constraints. It is optional: tables built from a driver that doesn't
+// implement it have no Checks.
+type CheckInterface interface {
+	CheckConstraints(ctx context.Context, schema, tableName string) ([]CheckConstraint, error)
+}
+
+// DDLInterface is implemented by drivers that can produce the CREATE
+// statement of a table. It is optional: tables built from a driver that
+// doesn't implement it have no CreateSQL.
+type DDLInterface interface {
+	CreateStatement(ctx context.Context, schema, tableName string) (string, error)
+}
+
+// TableCommentInterface is implemented by drivers that can introspect table
+// comments. It is optional: tables built from a driver that doesn't
+// implement it have no Comment.
+type TableCommentInterface interface {
+	TableComment(ctx context.Context, schema, tableName string) (string, error)
+}
+
+// AsOfInterface is implemented by drivers that can introspect the schema as
+// it was at a point in time. None of the built-in drivers do: MariaDB
+// system versioning covers the rows of versioned tables, not the catalog.
+type AsOfInterface interface {
+	SetAsOf(asOf time.Time) error
 }
 
+// RowCountInterface is implemented by drivers that can estimate how many
+// rows a table has without counting them. It is optional: tables built
+// from a driver that doesn't implement it have an EstimatedRows of -1.
+type RowCountInterface interface {
+	RowCountEstimate(ctx context.Context, schema, tableName string) (int64, error)
+}
+
+// DistributionInterface is implemented by drivers of databases that
+// distribute and sort the rows of tables by key columns, like Redshift. It
+// is optional: tables built from a driver that doesn't implement it have no
+// DistKey or SortKeys.
+type DistributionInterface interface {
+	DistributionKeys(ctx context.Context, schema, tableName string) (distKey string, sortKeys []string, err error)
+}
+
+// Options tune how Tables builds the table metadata.
+type Options struct {
+	// ForceInt64 widens every integer column to its 64-bit Go type after
+	// the driver has translated it.
+	ForceInt64 bool
+	// DateAsCivil maps date columns to types.Date and types.NullDate
+	// rather than a time.Time.
+	DateAsCivil bool
+	// IncludeDDL fills in each table's CreateSQL.
+	IncludeDDL bool
+	// StringifyLargeInts sets JSONString on int64 and uint64 columns.
+	StringifyLargeInts bool
+	// DirectivePrefix marks the words of column comments parsed into
+	// Column.Directives, DefaultDirectivePrefix if empty. Columns with the
+	// skip directive are left out of the table.
+	DirectivePrefix string
+	// BlacklistColumnPattern is a regular expression; the columns whose
+	// names match it are left out of the table, like skipped ones.
+	BlacklistColumnPattern string
+	// WhitelistColumns and BlacklistColumns map table names to the only
+	// columns of the table to keep, and to columns to leave out, like
+	// skipped ones. A table's whitelist wins: its blacklist is ignored.
+	// Shards are matched by their own names and by their ShardMerge
+	// logical table's, so listing orders covers orders_0 to orders_15.
+	WhitelistColumns map[string][]string
+	BlacklistColumns map[string][]string
+	// ShardMerge maps regular expressions matching the whole names of
+	// sharded tables to the logical table each set is merged into, e.g.
+	// `orders_\d+` to orders. Only Tables merges shards.
+	ShardMerge map[string]string
+	// KeepDuplicateForeignKeys keeps every foreign key of a column to the
+	// same foreign column. Tables otherwise keeps only the first, so the
+	// duplicates don't become duplicate relationships.
+	KeepDuplicateForeignKeys bool
+	// SingularTableNames is set when tables are named in the singular, e.g.
+	// user, so their GoNames are the names as they are rather than
+	// singularized.
+	SingularTableNames bool
+	// Warnf logs what Tables changes about the schema, such as dropped
+	// duplicate foreign keys, with log.Printf if nil.
+	Warnf func(format string, args ...interface{})
+}
+
+// listedOut reports whether WhitelistColumns or BlacklistColumns leave the
+// column of table out.
+func (o Options) listedOut(table, column string) bool {
+	if o.listedOutOf(table, column) {
+		return true
+	}
+
+	// Invalid patterns are reported by mergeShards.
+	patterns, _ := shardPatterns(o.ShardMerge)
+	if logical := logicalTable(patterns, table); len(logical) != 0 {
+		return o.listedOutOf(logical, column)
+	}
+	return false
+}
+
+// listedOutOf reports whether the lists under the name table leave the
+// column out.
+func (o Options) listedOutOf(table, column string) bool {
+	if whitelist, ok := o.WhitelistColumns[table]; ok {
+		return !strmangle.SetInclude(column, whitelist)
+	}
+
+	return strmangle.SetInclude(column, o.BlacklistColumns[table])
+}
+
+func (o Options) warnf(format string, args ...interface{}) {
+	if o.Warnf == nil {
+		log.Printf(format, args...)
+		return
+	}
+	o.Warnf(format, args...)
+}
+
+// DefaultDirectivePrefix is the directive prefix used when
+// Options.DirectivePrefix is empty.
+const DefaultDirectivePrefix = "@"
+
 // Tables returns the metadata for all tables, minus the tables
-// specified in the blacklist.
-func Tables(db Interface, schema string, whitelist, blacklist []string) ([]Table, error) {
-	var err error
+// specified in the blacklist. The driver's queries are run with ctx, and
+// introspection stops with its error once it is done. Problems with the
+// schema, such as a primary key column left out, are returned together as
+// a *ValidationError along with the tables.
+func Tables(ctx context.Context, db Interface, schema string, whitelist, blacklist []string, opts Options) ([]Table, error) {
+	var tables []Table
+	err := StreamTables(ctx, db, schema, whitelist, blacklist, opts, func(t Table) error {
+		tables = append(tables, t)
+		return nil
+	})
+	problems, ok := err.(*ValidationError)
+	if err != nil && !ok {
+		return nil, err
+	}
+	if problems == nil {
+		problems = &ValidationError{}
+	}
+
+	if tables, err = mergeShards(tables, opts.ShardMerge, opts.SingularTableNames, problems, opts.warnf); err != nil {
+		return nil, err
+	}
+
+	Derive(tables, opts)
+
+	return tables, problems.Err()
+}
 
-	names, err := db.TableNames(schema, whitelist, blacklist)
+// Derive recomputes the metadata of tables that follows from the rest of
+// it: OptionalOnInsert, AutoColumns, the foreign keys, indexes and checks
+// over missing columns and tables, which go with them, foreign key
+// nullability and relationships. Tables calls it, and callers that rename,
+// add or drop tables or columns afterwards call it again. The Go types of
+// columns are left alone, added columns need theirs set.
+func Derive(tables []Table, opts Options) {
+	for i := range tables {
+		for j, c := range tables[i].Columns {
+			tables[i].Columns[j].OptionalOnInsert = !c.Nullable && len(c.Default) != 0
+		}
+		tables[i].AutoColumns = autoColumns(tables[i].Columns)
+		dropMissingColumnKeys(&tables[i])
+		dropSkippedForeignKeys(&tables[i], tables, opts.warnf)
+		if !opts.KeepDuplicateForeignKeys {
+			dropDuplicateForeignKeys(&tables[i], opts.warnf)
+		}
+	}
+
+	// Relationships have a dependency on foreign key nullability.
+	for i := range tables {
+		tbl := &tables[i]
+		setForeignKeyConstraints(tbl, tables)
+	}
+	for i := range tables {
+		tbl := &tables[i]
+		setRelationships(tbl, tables)
+	}
+}
+
+// StreamTables calls fn with the metadata for each table, minus the tables
+// specified in the blacklist, as soon as it has been introspected. Since the
+// whole schema is never held at once, metadata that spans tables (foreign key
+// constraints and relationships) is not filled in. Tables with problems are
+// still passed to fn, and the problems returned as a *ValidationError once
+// every table has been.
+func StreamTables(ctx context.Context, db Interface, schema string, whitelist, blacklist []string, opts Options, fn func(Table) error) error {
+	var columnBlacklist *regexp.Regexp
+	if len(opts.BlacklistColumnPattern) != 0 {
+		var err error
+		if columnBlacklist, err = regexp.Compile(opts.BlacklistColumnPattern); err != nil {
+			return errors.Wrap(err, "invalid column blacklist pattern")
+		}
+	}
+
+	names, err := db.TableNames(ctx, schema, whitelist, blacklist)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to get table names")
+		return errors.Wrap(err, "unable to get table names")
 	}
 
-	var tables []Table
+	problems := &ValidationError{}
 	for _, name := range names {
-		t := Table{
-			Name: name,
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		if t.Columns, err = db.Columns(schema, name); err != nil {
-			return nil, errors.Wrapf(err, "unable to fetch table column info (%s)", name)
+		t, err := table(ctx, db, schema, name, opts, columnBlacklist, problems)
+		if err != nil {
+			return err
 		}
 
-		for i, c := range t.Columns {
-			t.Columns[i] = db.TranslateColumnType(c)
+		if err := fn(t); err != nil {
+			return err
 		}
+	}
+
+	return problems.Err()
+}
+
+// table introspects the metadata for a single table, leaving out the
+// columns that are skipped or match columnBlacklist, along with the foreign
+// keys, indexes and checks over them. Problems with it are added to
+// problems.
+func table(ctx context.Context, db Interface, schema, name string, opts Options, columnBlacklist *regexp.Regexp, problems *ValidationError) (Table, error) {
+	var err error
+
+	t := Table{
+		Name:   name,
+		GoName: tableGoName(name, opts.SingularTableNames),
+	}
+
+	if t.Columns, err = db.Columns(ctx, schema, name); err != nil {
+		return t, errors.Wrapf(err, "unable to fetch table column info (%s)", name)
+	}
+
+	prefix := opts.DirectivePrefix
+	if len(prefix) == 0 {
+		prefix = DefaultDirectivePrefix
+	}
 
-		if t.PKey, err = db.PrimaryKeyInfo(schema, name); err != nil {
-			return nil, errors.Wrapf(err, "unable to fetch table pkey info (%s)", name)
+	var skipped []string
+	columns := t.Columns[:0]
+	for _, c := range t.Columns {
+		c.Directives = parseDirectives(c.Comment, prefix)
+		if c.HasDirective("skip") || (columnBlacklist != nil && columnBlacklist.MatchString(c.Name)) || opts.listedOut(name, c.Name) {
+			skipped = append(skipped, c.Name)
+			continue
 		}
+		columns = append(columns, c)
+	}
+	t.Columns = columns
 
-		if t.FKeys, err = db.ForeignKeyInfo(schema, name); err != nil {
-			return nil, errors.Wrapf(err, "unable to fetch table fkey info (%s)", name)
+	for i, c := range t.Columns {
+		c = enumType(t.GoName, db.TranslateColumnType(columnLimits(c)))
+		if opts.ForceInt64 {
+			c = widenInt(c)
+		}
+		if opts.DateAsCivil {
+			c = civilDate(c)
 		}
+		if opts.StringifyLargeInts {
+			c = stringifyLargeInt(c)
+		}
+		c.OptionalOnInsert = !c.Nullable && len(c.Default) != 0
+		t.Columns[i] = c
+	}
+	t.AutoColumns = autoColumns(t.Columns)
 
-		setIsJoinTable(&t)
+	if t.PKey, err = db.PrimaryKeyInfo(ctx, schema, name); err != nil {
+		return t, errors.Wrapf(err, "unable to fetch table pkey info (%s)", name)
+	}
+	if t.PKey != nil {
+		for _, c := range t.PKey.Columns {
+			if strmangle.SetInclude(c, skipped) {
+				problems.Add(name, "primary key column %s left out", c)
+			}
+		}
+	}
 
-		tables = append(tables, t)
+	if t.FKeys, err = db.ForeignKeyInfo(ctx, schema, name); err != nil {
+		return t, errors.Wrapf(err, "unable to fetch table fkey info (%s)", name)
+	}
+	// Drivers that return a key per column pair still get them grouped.
+	t.FKeys = GroupForeignKeys(t.FKeys)
+	if len(skipped) != 0 {
+		fkeys := t.FKeys[:0]
+		for _, f := range t.FKeys {
+			if !coversAny(f.Columns, skipped) {
+				fkeys = append(fkeys, f)
+			}
+		}
+		t.FKeys = fkeys
+	}
+	for i := range t.FKeys {
+		t.FKeys[i].OnDelete = fkeyAction(t.FKeys[i].OnDelete)
+		t.FKeys[i].OnUpdate = fkeyAction(t.FKeys[i].OnUpdate)
 	}
 
-	// Relationships have a dependency on foreign key nullability.
-	for i := range tables {
-		tbl := &tables[i]
-		setForeignKeyConstraints(tbl, tables)
+	if idb, ok := db.(IndexInterface); ok {
+		if t.Indexes, err = idb.IndexInfo(ctx, schema, name); err != nil {
+			return t, errors.Wrapf(err, "unable to fetch table index info (%s)", name)
+		}
+		// Like foreign keys, indexes over left out columns are dropped.
+		if len(skipped) != 0 {
+			indexes := t.Indexes[:0]
+			for _, idx := range t.Indexes {
+				if !coversAny(idx.Columns, skipped) {
+					indexes = append(indexes, idx)
+				}
+			}
+			t.Indexes = indexes
+		}
 	}
-	for i := range tables {
-		tbl := &tables[i]
-		setRelationships(tbl, tables)
+
+	if cdb, ok := db.(CheckInterface); ok {
+		if t.Checks, err = cdb.CheckConstraints(ctx, schema, name); err != nil {
+			return t, errors.Wrapf(err, "unable to fetch table check constraints (%s)", name)
+		}
+		if len(skipped) != 0 {
+			checks := t.Checks[:0]
+			for _, ck := range t.Checks {
+				if !coversAny(ck.Columns, skipped) {
+					checks = append(checks, ck)
+				}
+			}
+			t.Checks = checks
+		}
+		setColumnChecks(&t)
 	}
 
-	return tables, nil
+	if ddb, ok := db.(DDLInterface); ok && opts.IncludeDDL {
+		if t.CreateSQL, err = ddb.CreateStatement(ctx, schema, name); err != nil {
+			return t, errors.Wrapf(err, "unable to fetch table ddl (%s)", name)
+		}
+	}
+
+	if tdb, ok := db.(TableCommentInterface); ok {
+		if t.Comment, err = tdb.TableComment(ctx, schema, name); err != nil {
+			return t, errors.Wrapf(err, "unable to fetch table comment (%s)", name)
+		}
+	}
+
+	t.EstimatedRows = -1
+	if rdb, ok := db.(RowCountInterface); ok {
+		if t.EstimatedRows, err = rdb.RowCountEstimate(ctx, schema, name); err != nil {
+			return t, errors.Wrapf(err, "unable to fetch table row count estimate (%s)", name)
+		}
+	}
+
+	if ddb, ok := db.(DistributionInterface); ok {
+		if t.DistKey, t.SortKeys, err = ddb.DistributionKeys(ctx, schema, name); err != nil {
+			return t, errors.Wrapf(err, "unable to fetch table distribution keys (%s)", name)
+		}
+	}
+
+	setIsJoinTable(&t)
+
+	return t, nil
+}
+
+// tableGoName is the GoName of the table name, singularized unless the
+// tables are named in the singular already.
+func tableGoName(name string, singular bool) string {
+	if singular {
+		return GoName(name)
+	}
+	return GoName(strmangle.Singular(name))
 }
 
 // setIsJoinTable if there are:
@@ -96,7 +447,7 @@ func setIsJoinTable(t *Table) {
 	for _, c := range t.PKey.Columns {
 		found := false
 		for _, f := range t.FKeys {
-			if c == f.Column {
+			if columns, _ := f.columnPairs(); strmangle.SetInclude(c, columns) {
 				found = true
 				break
 			}
@@ -109,16 +460,145 @@ func setIsJoinTable(t *Table) {
 	t.IsJoinTable = true
 }
 
+// coversAny reports whether any of columns is one of skipped.
+func coversAny(columns, skipped []string) bool {
+	return len(strmangle.SetComplement(columns, skipped)) != len(columns)
+}
+
+// setColumnChecks copies the parsed checks onto their columns. A column with
+// several checks, e.g. separate lower and upper bounds, gets them combined.
+func setColumnChecks(t *Table) {
+	for _, ck := range t.Checks {
+		if ck.Check == nil {
+			continue
+		}
+
+		for i := range t.Columns {
+			c := &t.Columns[i]
+			if c.Name != ck.Column {
+				continue
+			}
+
+			if c.Check == nil {
+				c.Check = &ColumnCheck{}
+			}
+			if len(ck.Check.Min) != 0 {
+				c.Check.Min, c.Check.MinExclusive = ck.Check.Min, ck.Check.MinExclusive
+			}
+			if len(ck.Check.Max) != 0 {
+				c.Check.Max, c.Check.MaxExclusive = ck.Check.Max, ck.Check.MaxExclusive
+			}
+			if len(ck.Check.In) != 0 {
+				c.Check.In = ck.Check.In
+			}
+		}
+	}
+}
+
+// dropSkippedForeignKeys removes the foreign keys of t from or to a column
+// its table doesn't have, because the column was skipped, and those that
+// reference a table that isn't generated, logging the latter.
+func dropSkippedForeignKeys(t *Table, tables []Table, warnf func(string, ...interface{})) {
+	fkeys := t.FKeys[:0]
+	for _, f := range t.FKeys {
+		foreign := findTable(tables, f.ForeignTable)
+		if foreign == nil {
+			warnf("dropping foreign key %s on %s.%s, %s isn't being generated", f.Name, t.Name, f.Column, f.ForeignTable)
+			continue
+		}
+		if columns, foreignColumns := f.columnPairs(); !hasColumns(*t, columns) || !hasColumns(*foreign, foreignColumns) {
+			continue
+		}
+		fkeys = append(fkeys, f)
+	}
+	t.FKeys = fkeys
+}
+
+// dropMissingColumnKeys removes the indexes and checks of t over columns it
+// doesn't have.
+func dropMissingColumnKeys(t *Table) {
+	indexes := t.Indexes[:0]
+	for _, idx := range t.Indexes {
+		if hasColumns(*t, idx.Columns) {
+			indexes = append(indexes, idx)
+		}
+	}
+	t.Indexes = indexes
+
+	checks := t.Checks[:0]
+	for _, ck := range t.Checks {
+		if hasColumns(*t, ck.Columns) {
+			checks = append(checks, ck)
+		}
+	}
+	t.Checks = checks
+}
+
+// dropDuplicateForeignKeys removes the foreign keys of t from the same
+// columns to the same foreign columns as an earlier one, logging each.
+func dropDuplicateForeignKeys(t *Table, warnf func(string, ...interface{})) {
+	seen := make(map[string]string)
+	fkeys := t.FKeys[:0]
+	for _, f := range t.FKeys {
+		columns, foreignColumns := f.columnPairs()
+		key := strings.Join(columns, ",") + " " + f.ForeignTable + "." + strings.Join(foreignColumns, ",")
+		if first, ok := seen[key]; ok {
+			warnf("dropping foreign key %s on %s.%s, a duplicate of %s", f.Name, t.Name, f.Column, first)
+			continue
+		}
+		seen[key] = f.Name
+		fkeys = append(fkeys, f)
+	}
+	t.FKeys = fkeys
+}
+
+func findTable(tables []Table, name string) *Table {
+	for i := range tables {
+		if tables[i].Name == name {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
+func hasColumn(t Table, name string) bool {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasColumns(t Table, names []string) bool {
+	for _, name := range names {
+		if !hasColumn(t, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// setForeignKeyConstraints sets the nullability and uniqueness of the
+// columns of the foreign keys of t. A composite key is nullable if any of
+// its columns is, and never unique, the columns being unique only as a
+// whole if at all.
 func setForeignKeyConstraints(t *Table, tables []Table) {
 	for i, fkey := range t.FKeys {
-		localColumn := t.GetColumn(fkey.Column)
 		foreignTable := GetTable(tables, fkey.ForeignTable)
-		foreignColumn := foreignTable.GetColumn(fkey.ForeignColumn)
+		columns, foreignColumns := fkey.columnPairs()
+
+		nullable, foreignNullable := false, false
+		for j := range columns {
+			nullable = nullable || t.GetColumn(columns[j]).Nullable
+			foreignNullable = foreignNullable || foreignTable.GetColumn(foreignColumns[j]).Nullable
+		}
 
-		t.FKeys[i].Nullable = localColumn.Nullable
-		t.FKeys[i].Unique = localColumn.Unique
-		t.FKeys[i].ForeignColumnNullable = foreignColumn.Nullable
-		t.FKeys[i].ForeignColumnUnique = foreignColumn.Unique
+		single := len(columns) == 1
+		t.FKeys[i].Nullable = nullable
+		t.FKeys[i].Unique = single && t.GetColumn(fkey.Column).Unique
+		t.FKeys[i].ForeignColumnNullable = foreignNullable
+		t.FKeys[i].ForeignColumnUnique = single && foreignTable.GetColumn(fkey.ForeignColumn).Unique
 	}
 }
 
@@ -1,121 +1,211 @@
 package db
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/vattle/sqlboiler/strmangle"
 )
 
-type testMockDriver struct{}
+// testMockDriver serves the tables seeded into it, in name order, with the
+// pilots schema of newTestMockDriver as a starting point. It implements
+// every optional interface from its Mock fields; wrap it in a baseDriver to
+// leave them out.
+type testMockDriver struct {
+	MockTables map[string][]Column
+	MockPKeys  map[string]*PrimaryKey
+	MockFKeys  map[string][]ForeignKey
+
+	MockIndexes   map[string][]Index
+	MockChecks    map[string][]CheckConstraint
+	MockComments  map[string]string
+	MockRowCounts map[string]int64
+	MockDDL       map[string]string
+	MockDistKeys  map[string]string
+	MockSortKeys  map[string][]string
+}
+
+// baseDriver hides every optional interface of the driver it wraps.
+type baseDriver struct{ Interface }
+
+// newTestMockDriver returns a testMockDriver seeded with pilots, their jets,
+// licenses and languages, and hangars.
+func newTestMockDriver() *testMockDriver {
+	return &testMockDriver{
+		MockTables: map[string][]Column{
+			"pilots": {
+				{Name: "id", TypeName: "int", DBType: "integer"},
+				{Name: "name", TypeName: "string", DBType: "character"},
+			},
+			"airports": {
+				{Name: "id", TypeName: "int", DBType: "integer"},
+				{Name: "size", TypeName: "null.Int", DBType: "integer", Nullable: true},
+			},
+			"jets": {
+				{Name: "id", TypeName: "int", DBType: "integer"},
+				{Name: "pilot_id", TypeName: "int", DBType: "integer", Nullable: true, Unique: true},
+				{Name: "airport_id", TypeName: "int", DBType: "integer"},
+				{Name: "name", TypeName: "string", DBType: "character", Nullable: false},
+				{Name: "color", TypeName: "null.String", DBType: "character", Nullable: true},
+				{Name: "uuid", TypeName: "string", DBType: "uuid", Nullable: true},
+				{Name: "identifier", TypeName: "string", DBType: "uuid", Nullable: false},
+				{Name: "cargo", TypeName: "[]byte", DBType: "bytea", Nullable: false},
+				{Name: "manifest", TypeName: "[]byte", DBType: "bytea", Nullable: true, Unique: true},
+			},
+			"licenses": {
+				{Name: "id", TypeName: "int", DBType: "integer"},
+				{Name: "pilot_id", TypeName: "int", DBType: "integer"},
+			},
+			"hangars": {
+				{Name: "id", TypeName: "int", DBType: "integer"},
+				{Name: "name", TypeName: "string", DBType: "character", Nullable: true, Unique: true},
+				{Name: "hangar_id", TypeName: "int", DBType: "integer", Nullable: true},
+			},
+			"languages": {
+				{Name: "id", TypeName: "int", DBType: "integer"},
+				{Name: "language", TypeName: "string", DBType: "character", Nullable: false, Unique: true},
+			},
+			"pilot_languages": {
+				{Name: "pilot_id", TypeName: "int", DBType: "integer"},
+				{Name: "language_id", TypeName: "int", DBType: "integer"},
+			},
+		},
+		MockPKeys: map[string]*PrimaryKey{
+			"pilots":          {Name: "pilot_id_pkey", Columns: []string{"id"}},
+			"airports":        {Name: "airport_id_pkey", Columns: []string{"id"}},
+			"jets":            {Name: "jet_id_pkey", Columns: []string{"id"}},
+			"licenses":        {Name: "license_id_pkey", Columns: []string{"id"}},
+			"hangars":         {Name: "hangar_id_pkey", Columns: []string{"id"}},
+			"languages":       {Name: "language_id_pkey", Columns: []string{"id"}},
+			"pilot_languages": {Name: "pilot_languages_pkey", Columns: []string{"pilot_id", "language_id"}},
+		},
+		MockFKeys: map[string][]ForeignKey{
+			"jets": {
+				{Table: "jets", Name: "jets_pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id", ForeignColumnUnique: true},
+				{Table: "jets", Name: "jets_airport_id_fk", Column: "airport_id", ForeignTable: "airports", ForeignColumn: "id"},
+			},
+			"licenses": {
+				{Table: "licenses", Name: "licenses_pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id"},
+			},
+			"pilot_languages": {
+				{Table: "pilot_languages", Name: "pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id"},
+				{Table: "pilot_languages", Name: "jet_id_fk", Column: "language_id", ForeignTable: "languages", ForeignColumn: "id"},
+			},
+			"hangars": {
+				{Table: "hangars", Name: "hangar_fk_id", Column: "hangar_id", ForeignTable: "hangars", ForeignColumn: "id"},
+			},
+		},
+	}
+}
+
+func (m *testMockDriver) TranslateColumnType(c Column) Column { return c }
+func (m *testMockDriver) UseLastInsertID() bool               { return false }
+func (m *testMockDriver) UseTopClause() bool                  { return false }
+func (m *testMockDriver) Open() error                         { return nil }
+func (m *testMockDriver) Close()                              {}
 
-func (m testMockDriver) TranslateColumnType(c Column) Column { return c }
-func (m testMockDriver) UseLastInsertID() bool               { return false }
-func (m testMockDriver) UseTopClause() bool                  { return false }
-func (m testMockDriver) Open() error                         { return nil }
-func (m testMockDriver) Close()                              {}
+func (m *testMockDriver) Schemas(ctx context.Context) ([]string, error) {
+	return []string{"public"}, nil
+}
 
-func (m testMockDriver) TableNames(schema string, whitelist, blacklist []string) ([]string, error) {
+func (m *testMockDriver) TableNames(ctx context.Context, schema string, whitelist, blacklist []string) ([]string, error) {
 	if len(whitelist) > 0 {
 		return whitelist, nil
 	}
-	tables := []string{"pilots", "jets", "airports", "licenses", "hangars", "languages", "pilot_languages"}
+	tables := make([]string, 0, len(m.MockTables))
+	for name := range m.MockTables {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
 	return strmangle.SetComplement(tables, blacklist), nil
 }
 
-// Columns returns a list of mock columns
-func (m testMockDriver) Columns(schema, tableName string) ([]Column, error) {
-	return map[string][]Column{
-		"pilots": {
-			{Name: "id", Type: "int", DBType: "integer"},
-			{Name: "name", Type: "string", DBType: "character"},
-		},
-		"airports": {
-			{Name: "id", Type: "int", DBType: "integer"},
-			{Name: "size", Type: "null.Int", DBType: "integer", Nullable: true},
-		},
-		"jets": {
-			{Name: "id", Type: "int", DBType: "integer"},
-			{Name: "pilot_id", Type: "int", DBType: "integer", Nullable: true, Unique: true},
-			{Name: "airport_id", Type: "int", DBType: "integer"},
-			{Name: "name", Type: "string", DBType: "character", Nullable: false},
-			{Name: "color", Type: "null.String", DBType: "character", Nullable: true},
-			{Name: "uuid", Type: "string", DBType: "uuid", Nullable: true},
-			{Name: "identifier", Type: "string", DBType: "uuid", Nullable: false},
-			{Name: "cargo", Type: "[]byte", DBType: "bytea", Nullable: false},
-			{Name: "manifest", Type: "[]byte", DBType: "bytea", Nullable: true, Unique: true},
-		},
-		"licenses": {
-			{Name: "id", Type: "int", DBType: "integer"},
-			{Name: "pilot_id", Type: "int", DBType: "integer"},
-		},
-		"hangars": {
-			{Name: "id", Type: "int", DBType: "integer"},
-			{Name: "name", Type: "string", DBType: "character", Nullable: true, Unique: true},
-			{Name: "hangar_id", Type: "int", DBType: "integer", Nullable: true},
-		},
-		"languages": {
-			{Name: "id", Type: "int", DBType: "integer"},
-			{Name: "language", Type: "string", DBType: "character", Nullable: false, Unique: true},
-		},
-		"pilot_languages": {
-			{Name: "pilot_id", Type: "int", DBType: "integer"},
-			{Name: "language_id", Type: "int", DBType: "integer"},
-		},
-	}[tableName], nil
+// Columns returns a copy of the mock columns, so tables can't change them
+func (m *testMockDriver) Columns(ctx context.Context, schema, tableName string) ([]Column, error) {
+	return append([]Column(nil), m.MockTables[tableName]...), nil
 }
 
-// ForeignKeyInfo returns a list of mock foreignkeys
-func (m testMockDriver) ForeignKeyInfo(schema, tableName string) ([]ForeignKey, error) {
-	return map[string][]ForeignKey{
-		"jets": {
-			{Table: "jets", Name: "jets_pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id", ForeignColumnUnique: true},
-			{Table: "jets", Name: "jets_airport_id_fk", Column: "airport_id", ForeignTable: "airports", ForeignColumn: "id"},
-		},
-		"licenses": {
-			{Table: "licenses", Name: "licenses_pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id"},
-		},
-		"pilot_languages": {
-			{Table: "pilot_languages", Name: "pilot_id_fk", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id"},
-			{Table: "pilot_languages", Name: "jet_id_fk", Column: "language_id", ForeignTable: "languages", ForeignColumn: "id"},
-		},
-		"hangars": {
-			{Table: "hangars", Name: "hangar_fk_id", Column: "hangar_id", ForeignTable: "hangars", ForeignColumn: "id"},
-		},
-	}[tableName], nil
+// ForeignKeyInfo returns a copy of the mock foreign keys
+func (m *testMockDriver) ForeignKeyInfo(ctx context.Context, schema, tableName string) ([]ForeignKey, error) {
+	return append([]ForeignKey(nil), m.MockFKeys[tableName]...), nil
 }
 
 // PrimaryKeyInfo returns mock primary key info for the passed in table name
-func (m testMockDriver) PrimaryKeyInfo(schema, tableName string) (*PrimaryKey, error) {
-	return map[string]*PrimaryKey{
-		"pilots":          {Name: "pilot_id_pkey", Columns: []string{"id"}},
-		"airports":        {Name: "airport_id_pkey", Columns: []string{"id"}},
-		"jets":            {Name: "jet_id_pkey", Columns: []string{"id"}},
-		"licenses":        {Name: "license_id_pkey", Columns: []string{"id"}},
-		"hangars":         {Name: "hangar_id_pkey", Columns: []string{"id"}},
-		"languages":       {Name: "language_id_pkey", Columns: []string{"id"}},
-		"pilot_languages": {Name: "pilot_languages_pkey", Columns: []string{"pilot_id", "language_id"}},
-	}[tableName], nil
+func (m *testMockDriver) PrimaryKeyInfo(ctx context.Context, schema, tableName string) (*PrimaryKey, error) {
+	return m.MockPKeys[tableName], nil
+}
+
+// IndexInfo returns the mock indexes
+func (m *testMockDriver) IndexInfo(ctx context.Context, schema, tableName string) ([]Index, error) {
+	return m.MockIndexes[tableName], nil
+}
+
+// CheckConstraints returns the mock check constraints
+func (m *testMockDriver) CheckConstraints(ctx context.Context, schema, tableName string) ([]CheckConstraint, error) {
+	return m.MockChecks[tableName], nil
+}
+
+// TableComment returns the mock table comment
+func (m *testMockDriver) TableComment(ctx context.Context, schema, tableName string) (string, error) {
+	return m.MockComments[tableName], nil
+}
+
+// RowCountEstimate returns the mock row count, -1 if there is none
+func (m *testMockDriver) RowCountEstimate(ctx context.Context, schema, tableName string) (int64, error) {
+	if n, ok := m.MockRowCounts[tableName]; ok {
+		return n, nil
+	}
+	return -1, nil
+}
+
+// CreateStatement returns the mock CREATE TABLE statement
+func (m *testMockDriver) CreateStatement(ctx context.Context, schema, tableName string) (string, error) {
+	return m.MockDDL[tableName], nil
+}
+
+// DistributionKeys returns the mock distribution and sort keys
+func (m *testMockDriver) DistributionKeys(ctx context.Context, schema, tableName string) (string, []string, error) {
+	return m.MockDistKeys[tableName], m.MockSortKeys[tableName], nil
 }
 
 // RightQuote is the quoting character for the right side of the identifier
-func (m testMockDriver) RightQuote() byte {
+func (m *testMockDriver) RightQuote() byte {
 	return '"'
 }
 
 // LeftQuote is the quoting character for the left side of the identifier
-func (m testMockDriver) LeftQuote() byte {
+func (m *testMockDriver) LeftQuote() byte {
 	return '"'
 }
 
 // IndexPlaceholders returns true to indicate fake support of indexed placeholders
-func (m testMockDriver) IndexPlaceholders() bool {
+func (m *testMockDriver) IndexPlaceholders() bool {
 	return false
 }
 
+// MaxPlaceholders returns a fake placeholder limit
+func (m *testMockDriver) MaxPlaceholders() int {
+	return 65535
+}
+
+// QuoteLiteral quotes s with single quotes
+func (m *testMockDriver) QuoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// UpsertClause returns a fake upsert clause
+func (m *testMockDriver) UpsertClause(conflictColumns, updateColumns []string) (string, error) {
+	return "ON CONFLICT DO NOTHING", nil
+}
+
 func TestTables(t *testing.T) {
 	t.Parallel()
 
-	tables, err := Tables(testMockDriver{}, "public", nil, nil)
+	tables, err := Tables(context.Background(), newTestMockDriver(), "public", nil, nil, Options{})
 	if err != nil {
 		t.Error(err)
 	}
@@ -155,6 +245,470 @@ func TestTables(t *testing.T) {
 	if len(hangars.FKeys) != 1 || hangars.FKeys[0].ForeignTable != "hangars" {
 		t.Error("want one hangar foreign key to itself")
 	}
+	if fkey := hangars.FKeys[0]; fkey.OnDelete != "NO ACTION" || fkey.OnUpdate != "NO ACTION" {
+		t.Errorf("want the actions to default to no action, got: %q %q", fkey.OnDelete, fkey.OnUpdate)
+	}
+}
+
+func TestTablesSingularTableNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Singular bool
+		Name     string
+		Want     string
+	}{
+		{false, "pilots", "Pilot"},
+		{false, "pilot_languages", "PilotLanguage"},
+		{true, "pilot", "Pilot"},
+		{true, "pilot_language", "PilotLanguage"},
+	}
+
+	for i, test := range tests {
+		tables, err := Tables(context.Background(), newTestMockDriver(), "public", []string{test.Name}, nil, Options{SingularTableNames: test.Singular})
+		if err != nil {
+			t.Errorf("%d) %s", i, err)
+			continue
+		}
+		if got := tables[0].GoName; got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
+
+func TestTablesIndexes(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockIndexes = map[string][]Index{"pilots": {{Name: "pilots_name_idx", Columns: []string{"name"}}}}
+
+	tables, err := Tables(context.Background(), baseDriver{d}, "public", nil, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if len(table.Indexes) != 0 {
+			t.Errorf("want no indexes for %s, got: %#v", table.Name, table.Indexes)
+		}
+	}
+
+	tables, err = Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tables[0].Indexes; len(got) != 1 || got[0].Name != "pilots_name_idx" {
+		t.Errorf("want the driver's indexes, got: %#v", got)
+	}
+}
+
+func TestTablesComment(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockComments = map[string]string{"pilots": "The pilots who fly"}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tables[0].Comment; got != "The pilots who fly" {
+		t.Errorf("want the driver's table comment, got: %q", got)
+	}
+}
+
+func TestTablesEstimatedRows(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockRowCounts = map[string]int64{"pilots": 1200}
+
+	tables, err := Tables(context.Background(), baseDriver{d}, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tables[0].EstimatedRows; got != -1 {
+		t.Errorf("want no estimate without the driver support, got: %d", got)
+	}
+
+	tables, err = Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tables[0].EstimatedRows; got != 1200 {
+		t.Errorf("want the driver's estimate, got: %d", got)
+	}
+}
+
+func TestTablesDistributionKeys(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockDistKeys = map[string]string{"jets": "pilot_id"}
+	d.MockSortKeys = map[string][]string{"jets": {"airport_id", "id"}}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"jets"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tables[0]; got.DistKey != "pilot_id" || !reflect.DeepEqual(got.SortKeys, []string{"airport_id", "id"}) {
+		t.Errorf("want the driver's distribution keys, got: %q %v", got.DistKey, got.SortKeys)
+	}
+}
+
+func TestTablesIncludeDDL(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockDDL = map[string]string{"pilots": "CREATE TABLE `pilots` (`id` int NOT NULL)"}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{IncludeDDL: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "CREATE TABLE `pilots` (`id` int NOT NULL)"; tables[0].CreateSQL != want {
+		t.Errorf("want: %s\ngot:  %s", want, tables[0].CreateSQL)
+	}
+
+	tables, err = Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables[0].CreateSQL) != 0 {
+		t.Errorf("want no ddl without the option, got: %s", tables[0].CreateSQL)
+	}
+}
+
+func TestTablesCheckConstraints(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockChecks = map[string][]CheckConstraint{"pilots": {
+		{Name: "pilots_chk_1", Expression: "(`id` > 0)", Column: "id", Check: &ColumnCheck{Min: "0", MinExclusive: true}},
+		{Name: "pilots_chk_2", Expression: "(`id` < 100)", Column: "id", Check: &ColumnCheck{Max: "100", MaxExclusive: true}},
+		{Name: "pilots_chk_3", Expression: "(char_length(`name`) > 2)"},
+	}}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pilots := tables[0]
+	if len(pilots.Checks) != 3 {
+		t.Errorf("want 3 checks, got: %d", len(pilots.Checks))
+	}
+
+	want := &ColumnCheck{Min: "0", MinExclusive: true, Max: "100", MaxExclusive: true}
+	if got := pilots.GetColumn("id").Check; !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %#v, got: %#v", want, got)
+	}
+	if got := pilots.GetColumn("name").Check; got != nil {
+		t.Errorf("want no parsed check on name, got: %#v", got)
+	}
+}
+
+func TestTablesForceInt64(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockTables["pilots"] = []Column{
+		{Name: "id", TypeName: "int16", DBType: "smallint"},
+		{Name: "rank", TypeName: "null.Int16", DBType: "smallint", Nullable: true},
+	}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{ForceInt64: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols := tables[0].Columns
+	if cols[0].TypeName != "int64" {
+		t.Errorf("want smallint widened to int64, got: %s", cols[0].TypeName)
+	}
+	if cols[1].TypeName != "null.Int64" {
+		t.Errorf("want nullable smallint widened to null.Int64, got: %s", cols[1].TypeName)
+	}
+
+	tables, err = Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tables[0].Columns[0].TypeName; got != "int16" {
+		t.Errorf("want smallint left alone without the flag, got: %s", got)
+	}
+}
+
+func TestTablesDateAsCivil(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockTables["pilots"] = []Column{
+		{Name: "id", TypeName: "int", DBType: "integer"},
+		{Name: "born_on", TypeName: "time.Time", DBType: "date"},
+		{Name: "died_on", TypeName: "null.Time", DBType: "date", Nullable: true},
+	}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{DateAsCivil: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols := tables[0].Columns
+	if cols[1].TypeName != "types.Date" {
+		t.Errorf("want date mapped to types.Date, got: %s", cols[1].TypeName)
+	}
+	if cols[2].TypeName != "types.NullDate" {
+		t.Errorf("want nullable date mapped to types.NullDate, got: %s", cols[2].TypeName)
+	}
+
+	tables, err = Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tables[0].Columns[1].TypeName; got != "time.Time" {
+		t.Errorf("want date left as time.Time without the flag, got: %s", got)
+	}
+}
+
+func TestTablesStringifyLargeInts(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockTables["pilots"] = []Column{
+		{Name: "id", TypeName: "int64", DBType: "bigint"},
+		{Name: "hits", TypeName: "uint64", DBType: "bigint unsigned"},
+		{Name: "rank", TypeName: "null.Int64", DBType: "bigint", Nullable: true},
+		{Name: "age", TypeName: "int", DBType: "integer"},
+	}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{StringifyLargeInts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bool{true, true, false, false}
+	for i, c := range tables[0].Columns {
+		if c.JSONString != want[i] {
+			t.Errorf("%d) want JSONString %t on %s, got: %t", i, want[i], c.Name, c.JSONString)
+		}
+	}
+
+	tables, err = Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tables[0].Columns[0].JSONString {
+		t.Error("want bigint left alone without the flag")
+	}
+}
+
+func TestTablesOptionalOnInsert(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockTables["pilots"] = []Column{
+		{Name: "id", TypeName: "int", DBType: "integer", Default: "nextval('pilots_id_seq'::regclass)"},
+		{Name: "name", TypeName: "string", DBType: "text"},
+		{Name: "rank", TypeName: "int", DBType: "integer", Default: "1"},
+		{Name: "nickname", TypeName: "null.String", DBType: "text", Default: "'ace'", Nullable: true},
+	}
+
+	tables, err := Tables(context.Background(), d, "public", []string{"pilots"}, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []bool{true, false, true, false}
+	for i, c := range tables[0].Columns {
+		if c.OptionalOnInsert != want[i] {
+			t.Errorf("%d) want OptionalOnInsert %t on %s, got: %t", i, want[i], c.Name, c.OptionalOnInsert)
+		}
+	}
+}
+
+func TestTablesSkipDirective(t *testing.T) {
+	t.Parallel()
+
+	d := newTestMockDriver()
+	d.MockTables["pilots"][1].Comment = "shown as @json"
+	d.MockTables["jets"][1].Comment = "legacy, @skip"
+	d.MockIndexes = map[string][]Index{"jets": {
+		{Name: "jets_pilot_id_name_idx", Columns: []string{"pilot_id", "name"}},
+		{Name: "jets_name_idx", Columns: []string{"name"}},
+	}}
+	d.MockChecks = map[string][]CheckConstraint{"jets": {
+		{Name: "jets_pilot_id_check", Columns: []string{"pilot_id"}, Expression: "pilot_id > 0"},
+		{Name: "jets_name_check", Columns: []string{"name"}, Expression: "name <> ''"},
+	}}
+
+	tables, err := Tables(context.Background(), d, "public", nil, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := GetTable(tables, "pilots").GetColumn("name").Directives; !reflect.DeepEqual(got, []string{"json"}) {
+		t.Errorf("want the json directive, got: %v", got)
+	}
+
+	jets := GetTable(tables, "jets")
+	for _, c := range jets.Columns {
+		if c.Name == "pilot_id" {
+			t.Error("want the skipped pilot_id column excluded")
+		}
+	}
+	for _, f := range jets.FKeys {
+		if f.Column == "pilot_id" {
+			t.Errorf("want the foreign key on the skipped column excluded, got: %#v", f)
+		}
+	}
+	if len(jets.Indexes) != 1 || jets.Indexes[0].Name != "jets_name_idx" {
+		t.Errorf("want the index over the skipped column excluded, got: %#v", jets.Indexes)
+	}
+	if len(jets.Checks) != 1 || jets.Checks[0].Name != "jets_name_check" {
+		t.Errorf("want the check on the skipped column excluded, got: %#v", jets.Checks)
+	}
+
+	d.MockTables["jets"][1].Comment = "legacy, sqlgen:skip"
+	tables, err = Tables(context.Background(), d, "public", nil, nil, Options{DirectivePrefix: "sqlgen:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(GetTable(tables, "jets").Columns) != len(jets.Columns) {
+		t.Error("want pilot_id skipped with a custom prefix")
+	}
+	if got := GetTable(tables, "pilots").GetColumn("name").Directives; len(got) != 0 {
+		t.Errorf("want @json ignored with a custom prefix, got: %v", got)
+	}
+}
+
+// newInternalMockDriver is the pilots schema with an internal column.
+func newInternalMockDriver() *testMockDriver {
+	d := newTestMockDriver()
+	d.MockTables["pilots"] = append(d.MockTables["pilots"], Column{Name: "score_internal", TypeName: "int", DBType: "integer"})
+	return d
+}
+
+func TestTablesBlacklistColumnPattern(t *testing.T) {
+	t.Parallel()
+
+	tables, err := Tables(context.Background(), newInternalMockDriver(), "public", nil, nil, Options{BlacklistColumnPattern: "_internal$"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pilots := GetTable(tables, "pilots")
+	if got := ColumnNames(pilots.Columns); !reflect.DeepEqual(got, []string{"id", "name"}) {
+		t.Errorf("want score_internal dropped, got: %v", got)
+	}
+	if pilots.PKey == nil || !reflect.DeepEqual(pilots.PKey.Columns, []string{"id"}) {
+		t.Errorf("want the primary key kept, got: %#v", pilots.PKey)
+	}
+
+	if _, err := Tables(context.Background(), newInternalMockDriver(), "public", nil, nil, Options{BlacklistColumnPattern: "^id$"}); err == nil {
+		t.Error("want an error dropping primary key columns")
+	}
+	if _, err := Tables(context.Background(), newInternalMockDriver(), "public", nil, nil, Options{BlacklistColumnPattern: "("}); err == nil {
+		t.Error("want an error for an invalid pattern")
+	}
+}
+
+func TestTablesColumnLists(t *testing.T) {
+	t.Parallel()
+
+	tables, err := Tables(context.Background(), newInternalMockDriver(), "public", nil, nil, Options{
+		WhitelistColumns: map[string][]string{"pilots": {"id", "name"}},
+		BlacklistColumns: map[string][]string{"pilots": {"name"}, "airports": {"size"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ColumnNames(GetTable(tables, "pilots").Columns); !reflect.DeepEqual(got, []string{"id", "name"}) {
+		t.Errorf("want the whitelist to win, got: %v", got)
+	}
+	if got := ColumnNames(GetTable(tables, "airports").Columns); !reflect.DeepEqual(got, []string{"id"}) {
+		t.Errorf("want size blacklisted, got: %v", got)
+	}
+	if got := ColumnNames(GetTable(tables, "jets").Columns); len(got) != 9 {
+		t.Errorf("want the jets columns left alone, got: %v", got)
+	}
+
+	_, err = Tables(context.Background(), newInternalMockDriver(), "public", nil, nil, Options{BlacklistColumns: map[string][]string{"pilots": {"id"}}})
+	if err == nil || !strings.Contains(err.Error(), "pilots: primary key column id left out") {
+		t.Errorf("want an error blacklisting a primary key column, got: %v", err)
+	}
+	if _, err := Tables(context.Background(), newInternalMockDriver(), "public", nil, nil, Options{WhitelistColumns: map[string][]string{"pilots": {"name"}}}); err == nil {
+		t.Error("want an error leaving a primary key column off the whitelist")
+	}
+}
+
+func TestTablesDuplicateForeignKeys(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	warnf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	d := newTestMockDriver()
+	d.MockFKeys["licenses"] = append(d.MockFKeys["licenses"], ForeignKey{Table: "licenses", Name: "licenses_pilot_id_fk2", Column: "pilot_id", ForeignTable: "pilots", ForeignColumn: "id"})
+
+	tables, err := Tables(context.Background(), d, "public", nil, nil, Options{Warnf: warnf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	licenses := GetTable(tables, "licenses")
+	if len(licenses.FKeys) != 1 || licenses.FKeys[0].Name != "licenses_pilot_id_fk" {
+		t.Errorf("want only the first foreign key kept, got: %#v", licenses.FKeys)
+	}
+	var rels int
+	for _, rel := range GetTable(tables, "pilots").ToManyRelationships {
+		if rel.ForeignTable == "licenses" {
+			rels++
+		}
+	}
+	if rels != 1 {
+		t.Errorf("want a single relationship from pilots to licenses, got %d", rels)
+	}
+	want := []string{"dropping foreign key licenses_pilot_id_fk2 on licenses.pilot_id, a duplicate of licenses_pilot_id_fk"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("want: %v\ngot:  %v", want, warnings)
+	}
+
+	tables, err = Tables(context.Background(), d, "public", nil, nil, Options{Warnf: warnf, KeepDuplicateForeignKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fkeys := GetTable(tables, "licenses").FKeys; len(fkeys) != 2 {
+		t.Errorf("want both foreign keys kept, got: %#v", fkeys)
+	}
+}
+
+func TestTablesDanglingForeignKeys(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	warnf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	tables, err := Tables(context.Background(), newTestMockDriver(), "public", nil, []string{"airports"}, Options{Warnf: warnf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jets := GetTable(tables, "jets")
+	if len(jets.FKeys) != 1 || jets.FKeys[0].ForeignTable != "pilots" {
+		t.Errorf("want only the foreign key to pilots kept, got: %#v", jets.FKeys)
+	}
+	want := []string{"dropping foreign key jets_airport_id_fk on jets.airport_id, airports isn't being generated"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("want: %v\ngot:  %v", want, warnings)
+	}
 }
 
 func TestSetIsJoinTable(t *testing.T) {
@@ -197,15 +751,15 @@ func TestSetForeignKeyConstraints(t *testing.T) {
 		{
 			Name: "one",
 			Columns: []Column{
-				{Name: "id1", Type: "string", Nullable: false, Unique: false},
-				{Name: "id2", Type: "string", Nullable: true, Unique: true},
+				{Name: "id1", TypeName: "string", Nullable: false, Unique: false},
+				{Name: "id2", TypeName: "string", Nullable: true, Unique: true},
 			},
 		},
 		{
 			Name: "other",
 			Columns: []Column{
-				{Name: "one_id_1", Type: "string", Nullable: false, Unique: false},
-				{Name: "one_id_2", Type: "string", Nullable: true, Unique: true},
+				{Name: "one_id_1", TypeName: "string", Nullable: false, Unique: false},
+				{Name: "one_id_2", TypeName: "string", Nullable: true, Unique: true},
 			},
 			FKeys: []ForeignKey{
 				{Column: "one_id_1", ForeignTable: "one", ForeignColumn: "id1"},
@@ -252,13 +806,13 @@ func TestSetRelationships(t *testing.T) {
 		{
 			Name: "one",
 			Columns: []Column{
-				{Name: "id", Type: "string"},
+				{Name: "id", TypeName: "string"},
 			},
 		},
 		{
 			Name: "other",
 			Columns: []Column{
-				{Name: "other_id", Type: "string"},
+				{Name: "other_id", TypeName: "string"},
 			},
 			FKeys: []ForeignKey{{Column: "other_id", ForeignTable: "one", ForeignColumn: "id", Nullable: true}},
 		},
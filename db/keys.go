@@ -1,6 +1,9 @@
 package db
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // PrimaryKey represents a primary key constraint in a database
 type PrimaryKey struct {
@@ -20,6 +23,116 @@ type ForeignKey struct {
 	ForeignColumn         string
 	ForeignColumnNullable bool
 	ForeignColumnUnique   bool
+
+	// Columns and ForeignColumns are every column of the constraint and the
+	// one each references, in order. Column and ForeignColumn are their
+	// first, and a single-column key has just those. Nullable is set if any
+	// of the columns is, and Unique only on single-column keys.
+	Columns        []string
+	ForeignColumns []string
+
+	// Deferrable and InitiallyDeferred report whether the constraint check
+	// can be, or by default is, deferred to the end of the transaction.
+	// Only Postgres supports this; they are false for other databases.
+	Deferrable        bool
+	InitiallyDeferred bool
+
+	// OnDelete and OnUpdate are the referential actions taken when the
+	// referenced row is deleted or its key updated: CASCADE, SET NULL,
+	// SET DEFAULT, RESTRICT or NO ACTION, the default for drivers that
+	// don't report them.
+	OnDelete string
+	OnUpdate string
+}
+
+// GroupForeignKeys merges the foreign keys sharing a table and constraint
+// name, as drivers reading a row per column return them, into one with the
+// Columns and ForeignColumns in the order found. Keys that have no Columns
+// yet get their Column and ForeignColumn as the only ones.
+func GroupForeignKeys(fkeys []ForeignKey) []ForeignKey {
+	var grouped []ForeignKey
+	index := map[string]int{}
+	for _, f := range fkeys {
+		columns, foreignColumns := f.columnPairs()
+		key := f.Table + "." + f.Name
+		if i, ok := index[key]; ok && len(f.Name) != 0 {
+			grouped[i].Columns = append(grouped[i].Columns, columns...)
+			grouped[i].ForeignColumns = append(grouped[i].ForeignColumns, foreignColumns...)
+			continue
+		}
+
+		f.Columns = append([]string(nil), columns...)
+		f.ForeignColumns = append([]string(nil), foreignColumns...)
+		index[key] = len(grouped)
+		grouped = append(grouped, f)
+	}
+
+	return grouped
+}
+
+// columnPairs returns the Columns and ForeignColumns of f, or its Column and
+// ForeignColumn for keys built without them.
+func (f ForeignKey) columnPairs() ([]string, []string) {
+	if len(f.Columns) == 0 {
+		return []string{f.Column}, []string{f.ForeignColumn}
+	}
+	return f.Columns, f.ForeignColumns
+}
+
+// fkeyAction normalizes a referential action reported by a driver, e.g.
+// "set  null" to SET NULL, defaulting to NO ACTION.
+func fkeyAction(action string) string {
+	action = strings.ToUpper(strings.Join(strings.Fields(action), " "))
+	if len(action) == 0 {
+		return "NO ACTION"
+	}
+
+	return action
+}
+
+// Index represents a secondary (non primary key) index in a database
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+
+	// Expression holds the key of a functional index, whose parts are
+	// expressions rather than plain columns.
+	Expression string
+	// MultiValued is set for MySQL multi-valued indexes over JSON arrays
+	// (CAST(... AS ... ARRAY)), which can serve MEMBER OF queries.
+	MultiValued bool
+
+	// Cardinality is the database's estimate of the number of distinct
+	// keys in the index, for ranking indexes by selectivity. It is -1 when
+	// unknown.
+	Cardinality int64
+}
+
+// CheckConstraint represents a CHECK constraint in a database
+type CheckConstraint struct {
+	Name string
+	// Columns are the columns the constraint covers, in order.
+	Columns []string
+	// Expression is the constraint's condition as the database shows it,
+	// e.g. ((price > (0)::numeric)) on Postgres.
+	Expression string
+
+	// Column and Check are set when the expression is a simple range or IN
+	// list over a single column, and are copied onto that column.
+	Column string
+	Check  *ColumnCheck
+}
+
+// ColumnCheck is the parsed form of a simple CHECK constraint on a column.
+// Values are SQL literals, e.g. 0 or 'active'.
+type ColumnCheck struct {
+	Min          string
+	MinExclusive bool
+	Max          string
+	MaxExclusive bool
+
+	In []string
 }
 
 // SQLColumnDef formats a column name and type like an SQL column definition.
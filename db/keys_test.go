@@ -1,14 +1,17 @@
 package db
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestSQLColDefinitions(t *testing.T) {
 	t.Parallel()
 
 	cols := []Column{
-		{Name: "one", Type: "int64"},
-		{Name: "two", Type: "string"},
-		{Name: "three", Type: "string"},
+		{Name: "one", TypeName: "int64"},
+		{Name: "two", TypeName: "string"},
+		{Name: "three", TypeName: "string"},
 	}
 
 	defs := SQLColDefinitions(cols, []string{"one"})
@@ -64,3 +67,51 @@ func TestNames(t *testing.T) {
 		t.Error("wrong type:", ret[1])
 	}
 }
+
+func TestGroupForeignKeys(t *testing.T) {
+	t.Parallel()
+
+	fkeys := GroupForeignKeys([]ForeignKey{
+		{Table: "shipments", Name: "shipments_order_fkey", Column: "order_region", ForeignTable: "orders", ForeignColumn: "region"},
+		{Table: "shipments", Name: "shipments_carrier_fkey", Column: "carrier_id", ForeignTable: "carriers", ForeignColumn: "id"},
+		{Table: "shipments", Name: "shipments_order_fkey", Column: "order_number", ForeignTable: "orders", ForeignColumn: "number"},
+	})
+
+	want := []ForeignKey{
+		{
+			Table: "shipments", Name: "shipments_order_fkey", Column: "order_region", ForeignTable: "orders", ForeignColumn: "region",
+			Columns: []string{"order_region", "order_number"}, ForeignColumns: []string{"region", "number"},
+		},
+		{
+			Table: "shipments", Name: "shipments_carrier_fkey", Column: "carrier_id", ForeignTable: "carriers", ForeignColumn: "id",
+			Columns: []string{"carrier_id"}, ForeignColumns: []string{"id"},
+		},
+	}
+	if !reflect.DeepEqual(fkeys, want) {
+		t.Errorf("want:\n%#v\ngot:\n%#v", want, fkeys)
+	}
+
+	if again := GroupForeignKeys(fkeys); !reflect.DeepEqual(again, want) {
+		t.Errorf("want grouping grouped keys to change nothing, got:\n%#v", again)
+	}
+}
+
+func TestFKeyAction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Action string
+		Want   string
+	}{
+		{"CASCADE", "CASCADE"},
+		{"set  null", "SET NULL"},
+		{" restrict ", "RESTRICT"},
+		{"", "NO ACTION"},
+	}
+
+	for i, test := range tests {
+		if got := fkeyAction(test.Action); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/vattle/sqlboiler/strmangle"
+)
+
+// ReservedWordSuffix is appended to unexported identifiers that would
+// otherwise be a Go keyword or shadow a predeclared identifier, so the local
+// variable for a column named type becomes type_.
+var ReservedWordSuffix = "_"
+
+// predeclared are Go's predeclared identifiers. Generated code relies on
+// them, so a local variable must not shadow one.
+var predeclared = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true,
+	"int16": true, "int32": true, "int64": true, "rune": true, "string": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true, "true": true, "false": true, "iota": true, "nil": true,
+	"append": true, "cap": true, "close": true, "complex": true, "copy": true,
+	"delete": true, "imag": true, "len": true, "make": true, "new": true,
+	"panic": true, "print": true, "println": true, "real": true, "recover": true,
+}
+
+// GoName returns the exported Go identifier for the sql name, e.g. UserID for
+// user_id. Characters Go doesn't allow separate words like underscores, so
+// first-name is FirstName, and a name that doesn't start with an upper case
+// letter, such as 2fa_secret, is prefixed with X.
+func GoName(name string) string {
+	ident := identRunes(strmangle.TitleCase(separateWords(name)))
+	if len(ident) == 0 || !unicode.IsUpper([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+
+	return ident
+}
+
+// GoVarName returns the unexported Go identifier for the sql name, e.g.
+// userID for user_id, and firstName for first-name. Keywords and
+// predeclared identifiers get the ReservedWordSuffix, and a name that
+// doesn't start with a letter is prefixed with x.
+func GoVarName(name string) string {
+	ident := identRunes(strmangle.CamelCase(separateWords(name)))
+	if len(ident) == 0 || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "x" + ident
+	}
+
+	if token.IsKeyword(ident) || predeclared[ident] {
+		ident += ReservedWordSuffix
+	}
+
+	return ident
+}
+
+// separateWords replaces the characters of s that can't be part of an
+// identifier with underscores, for casing to treat them as word breaks.
+func separateWords(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// identRunes drops the characters of s that can't be part of an identifier.
+func identRunes(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
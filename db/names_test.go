@@ -0,0 +1,60 @@
+package db
+
+import (
+	"go/token"
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name string
+		Want string
+	}{
+		{"type", "Type"},
+		{"func", "Func"},
+		{"user_id", "UserID"},
+		{"2fa_secret", "X2faSecret"},
+		{"first-name", "FirstName"},
+		{"unit price ($)", "UnitPrice"},
+	}
+
+	for i, test := range tests {
+		got := GoName(test.Name)
+		if got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+		if !token.IsIdentifier(got) || !token.IsExported(got) {
+			t.Errorf("%d) %s is not a valid exported identifier", i, got)
+		}
+	}
+}
+
+func TestGoVarName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Name string
+		Want string
+	}{
+		{"type", "type_"},
+		{"func", "func_"},
+		{"select", "select_"},
+		{"name", "name"},
+		{"string", "string_"},
+		{"user_id", "userID"},
+		{"2fa_secret", "x2faSecret"},
+		{"first-name", "firstName"},
+	}
+
+	for i, test := range tests {
+		got := GoVarName(test.Name)
+		if got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+		if !token.IsIdentifier(got) || token.IsExported(got) {
+			t.Errorf("%d) %s is not a valid unexported identifier", i, got)
+		}
+	}
+}
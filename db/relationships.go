@@ -1,5 +1,13 @@
 package db
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vattle/sqlboiler/strmangle"
+)
+
 // ToOneRelationship describes a relationship between two tables where the local
 // table has no id, and the foregin table has an id that matches a column in the
 // local table, that column is also unique which changes the dynamic into a
@@ -14,6 +22,11 @@ type ToOneRelationship struct {
 	ForeignColumn         string
 	ForeignColumnNullable bool
 	ForeignColumnUnique   bool
+
+	// Columns and ForeignColumns are all the columns joined on, in order,
+	// for composite foreign keys. Column and ForeignColumn are their first.
+	Columns        []string
+	ForeignColumns []string
 }
 
 // ToManyRelationship describes a relationship between two tables where the
@@ -30,6 +43,11 @@ type ToManyRelationship struct {
 	ForeignColumnNullable bool
 	ForeignColumnUnique   bool
 
+	// Columns and ForeignColumns are all the columns joined on, in order,
+	// for composite foreign keys. Column and ForeignColumn are their first.
+	Columns        []string
+	ForeignColumns []string
+
 	ToJoinTable bool
 	JoinTable   string
 
@@ -42,6 +60,208 @@ type ToManyRelationship struct {
 	JoinForeignColumnUnique   bool
 }
 
+// RelationshipKind is the cardinality of a Relationship as seen from its
+// local table.
+type RelationshipKind int
+
+// Relationship kinds.
+const (
+	// BelongsTo is an outgoing foreign key: the local column references a
+	// single row in the foreign table.
+	BelongsTo RelationshipKind = iota
+	// HasOne is a unique foreign key in the foreign table that references
+	// the local table.
+	HasOne
+	// HasMany is a non-unique foreign key in the foreign table that
+	// references the local table.
+	HasMany
+	// ManyToMany links the local and foreign tables through a join table.
+	ManyToMany
+)
+
+func (k RelationshipKind) String() string {
+	switch k {
+	case BelongsTo:
+		return "belongs_to"
+	case HasOne:
+		return "has_one"
+	case HasMany:
+		return "has_many"
+	case ManyToMany:
+		return "many_to_many"
+	}
+
+	return fmt.Sprintf("RelationshipKind(%d)", int(k))
+}
+
+// Relationship is the uniform description of a relationship from a table to
+// another, regardless of whether it comes from an outgoing foreign key, an
+// inverse foreign key or a join table. It carries what is needed to eager
+// load the foreign rows: the columns to match on and their cardinality.
+type Relationship struct {
+	// Name identifies the relationship within its table. It is the
+	// table.column of the foreign key that implements it, which is the
+	// join table's column for ManyToMany.
+	Name string
+	Kind RelationshipKind
+	// Accessor is the Go name for the relationship, unique within its
+	// table: the singular or plural foreign table, e.g. User or Messages,
+	// or one derived from the foreign key column when several relationships
+	// would share it, e.g. Sender and Recipient.
+	Accessor string
+
+	Table    string
+	Column   string
+	Nullable bool
+
+	ForeignTable          string
+	ForeignColumn         string
+	ForeignColumnNullable bool
+
+	// Columns and ForeignColumns are all the columns to match on, in order:
+	// Column and ForeignColumn alone, unless the foreign key is composite.
+	Columns        []string
+	ForeignColumns []string
+
+	// The join table and its columns referencing the local and foreign
+	// tables, for ManyToMany only.
+	JoinTable         string
+	JoinLocalColumn   string
+	JoinForeignColumn string
+}
+
+// Relationships returns every relationship of the table in one list: its
+// outgoing foreign keys followed by the to-one and to-many relationships
+// that reference it. The inverse relationships are only known once Tables
+// has run.
+func (t Table) Relationships() []Relationship {
+	var relationships []Relationship
+
+	for _, f := range t.FKeys {
+		columns, foreignColumns := f.columnPairs()
+		relationships = append(relationships, Relationship{
+			Name: t.Name + "." + f.Column,
+			Kind: BelongsTo,
+
+			Table:    t.Name,
+			Column:   f.Column,
+			Nullable: f.Nullable,
+
+			ForeignTable:          f.ForeignTable,
+			ForeignColumn:         f.ForeignColumn,
+			ForeignColumnNullable: f.ForeignColumnNullable,
+
+			Columns:        columns,
+			ForeignColumns: foreignColumns,
+		})
+	}
+
+	for _, r := range t.ToOneRelationships {
+		relationships = append(relationships, Relationship{
+			Name: r.ForeignTable + "." + r.ForeignColumn,
+			Kind: HasOne,
+
+			Table:    r.Table,
+			Column:   r.Column,
+			Nullable: r.Nullable,
+
+			ForeignTable:          r.ForeignTable,
+			ForeignColumn:         r.ForeignColumn,
+			ForeignColumnNullable: r.ForeignColumnNullable,
+
+			Columns:        r.Columns,
+			ForeignColumns: r.ForeignColumns,
+		})
+	}
+
+	for _, r := range t.ToManyRelationships {
+		rel := Relationship{
+			Name: r.ForeignTable + "." + r.ForeignColumn,
+			Kind: HasMany,
+
+			Table:    r.Table,
+			Column:   r.Column,
+			Nullable: r.Nullable,
+
+			ForeignTable:          r.ForeignTable,
+			ForeignColumn:         r.ForeignColumn,
+			ForeignColumnNullable: r.ForeignColumnNullable,
+
+			Columns:        r.Columns,
+			ForeignColumns: r.ForeignColumns,
+		}
+
+		if r.ToJoinTable {
+			rel.Name = r.JoinTable + "." + r.JoinLocalColumn
+			rel.Kind = ManyToMany
+			rel.JoinTable = r.JoinTable
+			rel.JoinLocalColumn = r.JoinLocalColumn
+			rel.JoinForeignColumn = r.JoinForeignColumn
+		}
+
+		relationships = append(relationships, rel)
+	}
+
+	setAccessors(relationships)
+
+	return relationships
+}
+
+// setAccessors names each relationship after its foreign table, falling back
+// to a name derived from the foreign key column for those that collide.
+func setAccessors(relationships []Relationship) {
+	counts := map[string]int{}
+	for i := range relationships {
+		relationships[i].Accessor = relationships[i].tableAccessor()
+		counts[relationships[i].Accessor]++
+	}
+
+	for i := range relationships {
+		if counts[relationships[i].Accessor] > 1 {
+			relationships[i].Accessor = relationships[i].columnAccessor()
+		}
+	}
+
+	// Anything still colliding, such as a self-referencing column that is
+	// both a belongs to and a has many, is numbered in order.
+	seen := map[string]int{}
+	for i := range relationships {
+		accessor := relationships[i].Accessor
+		seen[accessor]++
+		if n := seen[accessor]; n > 1 {
+			relationships[i].Accessor = accessor + strconv.Itoa(n)
+		}
+	}
+}
+
+// tableAccessor is the accessor named after the foreign table alone.
+func (r Relationship) tableAccessor() string {
+	switch r.Kind {
+	case BelongsTo, HasOne:
+		return GoName(strmangle.Singular(r.ForeignTable))
+	}
+
+	return GoName(strmangle.Plural(r.ForeignTable))
+}
+
+// columnAccessor is the accessor named after the foreign key column, e.g.
+// Sender for messages.sender_id, and SenderMessages for its inverse.
+func (r Relationship) columnAccessor() string {
+	var stem string
+	switch r.Kind {
+	case BelongsTo:
+		return GoName(strings.TrimSuffix(r.Column, "_id"))
+	case ManyToMany:
+		// Both columns of parallel join tables tend to be named alike, so
+		// tell them apart by the join table instead.
+		stem = strmangle.Singular(r.JoinTable)
+	default:
+		stem = strings.TrimSuffix(r.ForeignColumn, "_id")
+	}
+
+	return GoName(stem) + r.tableAccessor()
+}
+
 // ToOneRelationships relationship lookups
 // Input should be the sql name of a table like: videos
 func ToOneRelationships(table string, tables []Table) []ToOneRelationship {
@@ -86,6 +306,7 @@ func toManyRelationships(table Table, tables []Table) []ToManyRelationship {
 }
 
 func buildToOneRelationship(localTable Table, foreignKey ForeignKey, foreignTable Table, tables []Table) ToOneRelationship {
+	columns, foreignColumns := foreignKey.columnPairs()
 	return ToOneRelationship{
 		Table:    localTable.Name,
 		Column:   foreignKey.ForeignColumn,
@@ -96,10 +317,14 @@ func buildToOneRelationship(localTable Table, foreignKey ForeignKey, foreignTabl
 		ForeignColumn:         foreignKey.Column,
 		ForeignColumnNullable: foreignKey.Nullable,
 		ForeignColumnUnique:   foreignKey.Unique,
+
+		Columns:        foreignColumns,
+		ForeignColumns: columns,
 	}
 }
 
 func buildToManyRelationship(localTable Table, foreignKey ForeignKey, foreignTable Table, tables []Table) ToManyRelationship {
+	columns, foreignColumns := foreignKey.columnPairs()
 	if !foreignTable.IsJoinTable {
 		return ToManyRelationship{
 			Table:                 localTable.Name,
@@ -110,6 +335,8 @@ func buildToManyRelationship(localTable Table, foreignKey ForeignKey, foreignTab
 			ForeignColumn:         foreignKey.Column,
 			ForeignColumnNullable: foreignKey.Nullable,
 			ForeignColumnUnique:   foreignKey.Unique,
+			Columns:               foreignColumns,
+			ForeignColumns:        columns,
 			ToJoinTable:           false,
 		}
 	}
@@ -120,6 +347,8 @@ func buildToManyRelationship(localTable Table, foreignKey ForeignKey, foreignTab
 		Nullable: foreignKey.ForeignColumnNullable,
 		Unique:   foreignKey.ForeignColumnUnique,
 
+		Columns: foreignColumns,
+
 		ToJoinTable: true,
 		JoinTable:   foreignTable.Name,
 
@@ -141,6 +370,7 @@ func buildToManyRelationship(localTable Table, foreignKey ForeignKey, foreignTab
 		relationship.ForeignColumn = fk.ForeignColumn
 		relationship.ForeignColumnNullable = fk.ForeignColumnNullable
 		relationship.ForeignColumnUnique = fk.ForeignColumnUnique
+		_, relationship.ForeignColumns = fk.columnPairs()
 	}
 
 	return relationship
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -63,6 +64,9 @@ func TestToOneRelationships(t *testing.T) {
 			ForeignColumn:         "pilot_id",
 			ForeignColumnNullable: false,
 			ForeignColumnUnique:   true,
+
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"pilot_id"},
 		},
 		{
 			Table:    "pilots",
@@ -74,6 +78,9 @@ func TestToOneRelationships(t *testing.T) {
 			ForeignColumn:         "pilot_id",
 			ForeignColumnNullable: false,
 			ForeignColumnUnique:   true,
+
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"pilot_id"},
 		},
 	}
 
@@ -148,6 +155,9 @@ func TestToManyRelationships(t *testing.T) {
 			ForeignColumnNullable: false,
 			ForeignColumnUnique:   false,
 
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"pilot_id"},
+
 			ToJoinTable: false,
 		},
 		{
@@ -161,6 +171,9 @@ func TestToManyRelationships(t *testing.T) {
 			ForeignColumnNullable: false,
 			ForeignColumnUnique:   false,
 
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"pilot_id"},
+
 			ToJoinTable: false,
 		},
 		{
@@ -174,6 +187,9 @@ func TestToManyRelationships(t *testing.T) {
 			ForeignColumnNullable: false,
 			ForeignColumnUnique:   false,
 
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"id"},
+
 			ToJoinTable: true,
 			JoinTable:   "pilot_languages",
 
@@ -260,6 +276,9 @@ func TestToManyRelationshipsNull(t *testing.T) {
 			ForeignColumnNullable: true,
 			ForeignColumnUnique:   false,
 
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"pilot_id"},
+
 			ToJoinTable: false,
 		},
 		{
@@ -273,6 +292,9 @@ func TestToManyRelationshipsNull(t *testing.T) {
 			ForeignColumnNullable: true,
 			ForeignColumnUnique:   false,
 
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"pilot_id"},
+
 			ToJoinTable: false,
 		},
 		{
@@ -286,6 +308,9 @@ func TestToManyRelationshipsNull(t *testing.T) {
 			ForeignColumnNullable: true,
 			ForeignColumnUnique:   false,
 
+			Columns:        []string{"id"},
+			ForeignColumns: []string{"id"},
+
 			ToJoinTable: true,
 			JoinTable:   "pilot_languages",
 
@@ -305,3 +330,102 @@ func TestToManyRelationshipsNull(t *testing.T) {
 		}
 	}
 }
+
+func TestTableRelationships(t *testing.T) {
+	t.Parallel()
+
+	tables, err := Tables(context.Background(), newTestMockDriver(), "public", nil, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pilots := GetTable(tables, "pilots").Relationships()
+	expected := []Relationship{
+		{
+			Name: "jets.pilot_id", Kind: HasOne, Accessor: "Jet",
+			Table: "pilots", Column: "id",
+			ForeignTable: "jets", ForeignColumn: "pilot_id", ForeignColumnNullable: true,
+			Columns: []string{"id"}, ForeignColumns: []string{"pilot_id"},
+		},
+		{
+			Name: "licenses.pilot_id", Kind: HasMany, Accessor: "Licenses",
+			Table: "pilots", Column: "id",
+			ForeignTable: "licenses", ForeignColumn: "pilot_id",
+			Columns: []string{"id"}, ForeignColumns: []string{"pilot_id"},
+		},
+		{
+			Name: "pilot_languages.pilot_id", Kind: ManyToMany, Accessor: "Languages",
+			Table: "pilots", Column: "id",
+			ForeignTable: "languages", ForeignColumn: "id",
+			Columns: []string{"id"}, ForeignColumns: []string{"id"},
+			JoinTable: "pilot_languages", JoinLocalColumn: "pilot_id", JoinForeignColumn: "language_id",
+		},
+	}
+
+	if !reflect.DeepEqual(pilots, expected) {
+		t.Errorf("Mismatch between relationships:\n\nwant:%#v\n\ngot:%#v\n\n", expected, pilots)
+	}
+
+	jets := GetTable(tables, "jets").Relationships()
+	if len(jets) != 2 {
+		t.Fatalf("want 2 relationships for jets, got: %d", len(jets))
+	}
+	if r := jets[0]; r.Kind != BelongsTo || r.Name != "jets.pilot_id" || r.ForeignTable != "pilots" || !r.Nullable {
+		t.Errorf("want jets to belong to a pilot, got: %#v", r)
+	}
+	if r := jets[1]; r.Kind != BelongsTo || r.ForeignTable != "airports" || r.Nullable {
+		t.Errorf("want jets to belong to an airport, got: %#v", r)
+	}
+}
+
+func TestRelationshipAccessors(t *testing.T) {
+	t.Parallel()
+
+	tables := []Table{
+		{
+			Name:    "users",
+			Columns: []Column{{Name: "id", Unique: true}},
+			PKey:    &PrimaryKey{Columns: []string{"id"}},
+		},
+		{
+			Name:    "messages",
+			Columns: []Column{{Name: "id", Unique: true}, {Name: "sender_id"}, {Name: "recipient_id"}, {Name: "reply_to_id", Nullable: true}},
+			PKey:    &PrimaryKey{Columns: []string{"id"}},
+			FKeys: []ForeignKey{
+				{Name: "messages_sender_id_fk", Column: "sender_id", ForeignTable: "users", ForeignColumn: "id"},
+				{Name: "messages_recipient_id_fk", Column: "recipient_id", ForeignTable: "users", ForeignColumn: "id"},
+				{Name: "messages_reply_to_id_fk", Column: "reply_to_id", ForeignTable: "messages", ForeignColumn: "id"},
+			},
+		},
+	}
+
+	for i := range tables {
+		setForeignKeyConstraints(&tables[i], tables)
+	}
+	for i := range tables {
+		setRelationships(&tables[i], tables)
+	}
+
+	accessors := func(table string) []string {
+		var names []string
+		for _, r := range GetTable(tables, table).Relationships() {
+			names = append(names, r.Accessor)
+		}
+		return names
+	}
+
+	if got, want := accessors("messages"), []string{"Sender", "Recipient", "Message", "Messages"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+	if got, want := accessors("users"), []string{"SenderMessages", "RecipientMessages"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestRelationshipKindString(t *testing.T) {
+	t.Parallel()
+
+	if got := ManyToMany.String(); got != "many_to_many" {
+		t.Errorf("want many_to_many, got: %s", got)
+	}
+}
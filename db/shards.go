@@ -0,0 +1,191 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// shardPattern is a compiled ShardMerge pattern and its logical table.
+type shardPattern struct {
+	rgx     *regexp.Regexp
+	logical string
+}
+
+// shardPatterns compiles the ShardMerge patterns, sorted for shards matching
+// two of them to always go to the same logical table.
+func shardPatterns(shards map[string]string) ([]shardPattern, error) {
+	patterns := make([]string, 0, len(shards))
+	for pattern := range shards {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	compiled := make([]shardPattern, len(patterns))
+	for i, pattern := range patterns {
+		rgx, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid shard pattern %q", pattern)
+		}
+		compiled[i] = shardPattern{rgx: rgx, logical: shards[pattern]}
+	}
+
+	return compiled, nil
+}
+
+// logicalTable is the table patterns merge the table name into, or "" if
+// it isn't a shard.
+func logicalTable(patterns []shardPattern, name string) string {
+	for _, p := range patterns {
+		if p.rgx.MatchString(name) {
+			return p.logical
+		}
+	}
+
+	return ""
+}
+
+// mergeShards replaces the tables whose names match a pattern of shards with
+// a single table named after the pattern's logical name, e.g. orders_0 to
+// orders_15 with orders. The shards must have the same columns and primary
+// key, or the difference is added to problems. The foreign keys of the
+// first one are kept, with a warning for other shards' that differ, and
+// foreign keys to any of them point to the merged table. A logical name
+// that is also the name of a table that isn't a shard is an error.
+// singular is Options.SingularTableNames.
+func mergeShards(tables []Table, shards map[string]string, singular bool, problems *ValidationError, warnf func(string, ...interface{})) ([]Table, error) {
+	if len(shards) == 0 {
+		return tables, nil
+	}
+
+	patterns, err := shardPatterns(shards)
+	if err != nil {
+		return nil, err
+	}
+
+	logical := map[string]string{}
+	for _, t := range tables {
+		if name := logicalTable(patterns, t.Name); len(name) != 0 {
+			logical[t.Name] = name
+		}
+	}
+	for _, t := range tables {
+		if _, ok := logical[t.Name]; ok {
+			continue
+		}
+		for physical, name := range logical {
+			if name == t.Name {
+				return nil, errors.Errorf("shard %s would merge into %s, a table of its own", physical, name)
+			}
+		}
+	}
+
+	merged := map[string]int{}
+	out := tables[:0]
+	for _, t := range tables {
+		name, ok := logical[t.Name]
+		if !ok {
+			out = append(out, t)
+			continue
+		}
+		physical := t.Name
+		renameTable(&t, name, singular)
+
+		i, ok := merged[name]
+		if !ok {
+			merged[name] = len(out)
+			t.Shards = []string{physical}
+			out = append(out, t)
+			continue
+		}
+
+		m := &out[i]
+		sameShard(*m, physical, t, problems)
+		if !reflect.DeepEqual(shardForeignKeys(m.FKeys, logical), shardForeignKeys(t.FKeys, logical)) {
+			warnf("shard %s of %s has different foreign keys than %s, only those of %s are kept", physical, name, m.Shards[0], m.Shards[0])
+		}
+		m.Shards = append(m.Shards, physical)
+		if m.EstimatedRows >= 0 && t.EstimatedRows >= 0 {
+			m.EstimatedRows += t.EstimatedRows
+		} else {
+			m.EstimatedRows = -1
+		}
+	}
+
+	for i := range out {
+		for j := range out[i].FKeys {
+			f := &out[i].FKeys[j]
+			if name, ok := logical[f.Table]; ok {
+				f.Table = name
+			}
+			if name, ok := logical[f.ForeignTable]; ok {
+				f.ForeignTable = name
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// shardForeignKeys describes fkeys by their columns and what they
+// reference, the foreign tables by their logical names, for comparing the
+// keys of shards whose constraint names differ.
+func shardForeignKeys(fkeys []ForeignKey, logical map[string]string) []string {
+	var keys []string
+	for _, f := range fkeys {
+		foreign := f.ForeignTable
+		if name, ok := logical[foreign]; ok {
+			foreign = name
+		}
+		keys = append(keys, fmt.Sprintf("%v %s%v", f.Columns, foreign, f.ForeignColumns))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// renameTable gives t the logical name, along with the Go names of the
+// table and of its enum types derived from it.
+func renameTable(t *Table, name string, singular bool) {
+	goName := t.GoName
+	t.Name, t.GoName = name, tableGoName(name, singular)
+	RenameEnumTypes(t, goName)
+}
+
+// shardColumns copies columns without the defaults of auto increment ones,
+// which name each shard's own sequence, e.g.
+// nextval('orders_0_id_seq'::regclass), for comparing shards' schemas.
+func shardColumns(columns []Column) []Column {
+	out := make([]Column, len(columns))
+	for i, c := range columns {
+		if c.AutoIncrement {
+			c.Default = ""
+		}
+		out[i] = c
+	}
+
+	return out
+}
+
+// sameShard adds a problem to problems if the schema of the shard named
+// physical differs from the merged table's first shard.
+func sameShard(merged Table, physical string, shard Table, problems *ValidationError) {
+	first := merged.Shards[0]
+	if !reflect.DeepEqual(shardColumns(merged.Columns), shardColumns(shard.Columns)) {
+		problems.Add(merged.Name, "shard %s has different columns than %s", physical, first)
+	}
+
+	var mergedPKey, shardPKey []string
+	if merged.PKey != nil {
+		mergedPKey = merged.PKey.Columns
+	}
+	if shard.PKey != nil {
+		shardPKey = shard.PKey.Columns
+	}
+	if !reflect.DeepEqual(mergedPKey, shardPKey) {
+		problems.Add(merged.Name, "shard %s has a different primary key than %s", physical, first)
+	}
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newShardMockDriver returns a driver with customers and two shards of
+// orders, each with its own serial id, the second with different columns if
+// mismatch is set.
+func newShardMockDriver(mismatch bool) *testMockDriver {
+	order := func(name string) []Column {
+		return []Column{
+			{Name: "id", TypeName: "int", DBType: "integer", Default: "nextval('" + name + "_id_seq'::regclass)", AutoIncrement: true},
+			{Name: "customer_id", TypeName: "int", DBType: "integer"},
+			{Name: "status", TypeName: "string", DBType: "enum('open','shipped')"},
+		}
+	}
+	d := &testMockDriver{
+		MockTables: map[string][]Column{
+			"customers": {{Name: "id", TypeName: "int", DBType: "integer", Unique: true}},
+			"orders_0":  order("orders_0"),
+			"orders_1":  order("orders_1"),
+		},
+		MockPKeys: map[string]*PrimaryKey{},
+		MockFKeys: map[string][]ForeignKey{},
+	}
+	if mismatch {
+		d.MockTables["orders_1"] = []Column{{Name: "id", TypeName: "int64", DBType: "bigint"}}
+	}
+	for name := range d.MockTables {
+		d.MockPKeys[name] = &PrimaryKey{Name: name + "_pkey", Columns: []string{"id"}}
+		if name != "customers" {
+			d.MockFKeys[name] = []ForeignKey{{Table: name, Name: name + "_customer_id_fkey", Column: "customer_id", ForeignTable: "customers", ForeignColumn: "id"}}
+		}
+	}
+	return d
+}
+
+func TestTablesShardMerge(t *testing.T) {
+	t.Parallel()
+
+	tables, err := Tables(context.Background(), newShardMockDriver(false), "public", nil, nil, Options{ShardMerge: map[string]string{`orders_\d+`: "orders"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tables) != 2 {
+		t.Fatalf("want customers and orders, got: %#v", tables)
+	}
+
+	orders := GetTable(tables, "orders")
+	if orders.GoName != "Order" {
+		t.Errorf("want the Order model, got: %s", orders.GoName)
+	}
+	if want := []string{"orders_0", "orders_1"}; !reflect.DeepEqual(orders.Shards, want) {
+		t.Errorf("want shards: %v, got: %v", want, orders.Shards)
+	}
+	if got := orders.GetColumn("status").TypeName; got != "OrderStatus" {
+		t.Errorf("want the status enum named after the Order model, got: %s", got)
+	}
+	if len(orders.FKeys) != 1 || orders.FKeys[0].Table != "orders" {
+		t.Errorf("want the first shard's foreign key on orders, got: %#v", orders.FKeys)
+	}
+
+	customers := GetTable(tables, "customers")
+	if len(customers.ToManyRelationships) != 1 || customers.ToManyRelationships[0].ForeignTable != "orders" {
+		t.Errorf("want customers to have many orders, got: %#v", customers.ToManyRelationships)
+	}
+	if customers.Shards != nil {
+		t.Errorf("want customers unsharded, got: %v", customers.Shards)
+	}
+}
+
+func TestTablesShardMergeMismatch(t *testing.T) {
+	t.Parallel()
+
+	_, err := Tables(context.Background(), newShardMockDriver(true), "public", nil, nil, Options{ShardMerge: map[string]string{`orders_\d+`: "orders"}})
+	if err == nil {
+		t.Fatal("want an error merging shards with different columns")
+	}
+	if !strings.Contains(err.Error(), "orders: shard orders_1 has different columns than orders_0") {
+		t.Errorf("want the mismatched shard named, got: %s", err)
+	}
+
+	_, err = Tables(context.Background(), newShardMockDriver(false), "public", nil, nil, Options{ShardMerge: map[string]string{`orders_(`: "orders"}})
+	if err == nil {
+		t.Error("want an error for an invalid pattern")
+	}
+}
+
+func TestTablesValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	tables, err := Tables(context.Background(), newShardMockDriver(true), "public", nil, nil, Options{
+		ShardMerge:       map[string]string{`orders_\d+`: "orders"},
+		BlacklistColumns: map[string][]string{"customers": {"id"}},
+	})
+	problems, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("want a *ValidationError, got: %v", err)
+	}
+
+	want := map[string][]string{
+		"customers": {"primary key column id left out"},
+		"orders":    {"shard orders_1 has different columns than orders_0"},
+	}
+	if !reflect.DeepEqual(problems.Problems, want) {
+		t.Errorf("want every problem, got: %#v", problems.Problems)
+	}
+	if len(tables) != 2 {
+		t.Errorf("want the tables along with the problems, got: %#v", tables)
+	}
+}
+
+func TestTablesShardMergeCollision(t *testing.T) {
+	t.Parallel()
+
+	d := newShardMockDriver(false)
+	d.MockTables["orders"] = d.MockTables["orders_0"]
+	_, err := Tables(context.Background(), d, "public", nil, nil, Options{ShardMerge: map[string]string{`orders_\d+`: "orders"}})
+	if err == nil || !strings.Contains(err.Error(), "a table of its own") {
+		t.Errorf("want an error merging shards into an existing table, got: %v", err)
+	}
+}
+
+func TestTablesShardMergeForeignKeys(t *testing.T) {
+	t.Parallel()
+
+	var warnings []string
+	opts := Options{
+		ShardMerge: map[string]string{`orders_\d+`: "orders"},
+		Warnf: func(format string, args ...interface{}) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	}
+
+	if _, err := Tables(context.Background(), newShardMockDriver(false), "public", nil, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("want no warnings for foreign keys differing only in name, got: %v", warnings)
+	}
+
+	d := newShardMockDriver(false)
+	d.MockFKeys["orders_1"] = nil
+	if _, err := Tables(context.Background(), d, "public", nil, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "shard orders_1 of orders has different foreign keys than orders_0") {
+		t.Errorf("want a warning about the dropped foreign keys, got: %v", warnings)
+	}
+}
+
+func TestTablesShardMergeColumnLists(t *testing.T) {
+	t.Parallel()
+
+	tables, err := Tables(context.Background(), newShardMockDriver(false), "public", nil, nil, Options{
+		ShardMerge:       map[string]string{`orders_\d+`: "orders"},
+		BlacklistColumns: map[string][]string{"orders": {"status"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range GetTable(tables, "orders").Columns {
+		if c.Name == "status" {
+			t.Errorf("want status left out of every shard by the logical table's blacklist, got: %#v", c)
+		}
+	}
+}
@@ -5,13 +5,50 @@ import "fmt"
 // Table metadata from the database schema.
 type Table struct {
 	Name string
+	// GoName is the Go type name of the table's model: the singular of
+	// Name, e.g. User for users, or Name itself with SingularTableNames,
+	// unless Config.StructNames overrides it.
+	GoName string
 	// For dbs with real schemas, like Postgres.
 	// Example value: "schema_name"."table_name"
 	SchemaName string
 	Columns    []Column
 
-	PKey  *PrimaryKey
-	FKeys []ForeignKey
+	// Comment is the table's comment in the database, if the driver
+	// supports it, for renderers to turn into the model's doc comment.
+	Comment string
+
+	PKey    *PrimaryKey
+	FKeys   []ForeignKey
+	Indexes []Index
+	Checks  []CheckConstraint
+
+	// AutoColumns are the columns the database fills in on insert.
+	AutoColumns AutoColumns
+
+	// CreateSQL is the statement that creates the table, if the driver
+	// supports it and Options.IncludeDDL is set.
+	CreateSQL string
+
+	// EstimatedRows is the database's approximate row count of the table,
+	// for generation heuristics only: InnoDB samples it and can be off by
+	// 40% or more. It is -1 when the driver can't estimate it.
+	EstimatedRows int64
+
+	// DistKey and SortKeys are the columns the rows of a Redshift table are
+	// distributed across nodes by and stored sorted by, in order. They are
+	// empty for other databases.
+	DistKey  string
+	SortKeys []string
+
+	// DisplayColumn is the human-readable column, e.g. name or title, a
+	// renderer can build a String() method from: the one in
+	// Config.DisplayColumns, or the first primary key column.
+	DisplayColumn string
+
+	// Shards are the names of the tables merged into this one by
+	// Options.ShardMerge, in order, nil if it isn't sharded.
+	Shards []string
 
 	IsJoinTable bool
 
@@ -19,6 +56,33 @@ type Table struct {
 	ToManyRelationships []ToManyRelationship
 }
 
+// AutoColumns classifies the columns of a table the database can fill in on
+// insert, by name, in column order.
+type AutoColumns struct {
+	// Assigned are always filled in by the database and can't be inserted:
+	// auto incremented, otherwise auto generated and generated columns.
+	Assigned []string
+	// Defaulted have a default, e.g. CURRENT_TIMESTAMP, so an INSERT can
+	// leave them out or set them.
+	Defaulted []string
+}
+
+// autoColumns classifies the columns the database fills in on insert.
+func autoColumns(cols []Column) AutoColumns {
+	var auto AutoColumns
+
+	for _, c := range cols {
+		switch {
+		case c.AutoIncrement || c.AutoGenerated || c.Generated:
+			auto.Assigned = append(auto.Assigned, c.Name)
+		case len(c.Default) != 0:
+			auto.Defaulted = append(auto.Defaulted, c.Name)
+		}
+	}
+
+	return auto
+}
+
 // GetTable by name. Panics if not found (for use in templates mostly).
 func GetTable(tables []Table, name string) (tbl Table) {
 	for _, t := range tables {
@@ -64,3 +128,85 @@ func (t Table) CanLastInsertID() bool {
 
 	return true
 }
+
+// NaturalKey returns the columns that uniquely identify a row, for use as a
+// stable cache key. This is the primary key when there is one, otherwise the
+// smallest unique key of non-nullable columns: a unique column, or the
+// unique index with the fewest columns. ok is false if the table has none.
+func (t Table) NaturalKey() (columns []Column, ok bool) {
+	if t.PKey != nil && len(t.PKey.Columns) != 0 {
+		for _, name := range t.PKey.Columns {
+			columns = append(columns, t.GetColumn(name))
+		}
+		return columns, true
+	}
+
+	for _, c := range t.Columns {
+		if c.Unique && !c.Nullable {
+			return []Column{c}, true
+		}
+	}
+
+	for _, idx := range t.Indexes {
+		if !idx.Unique || len(idx.Expression) != 0 || idx.MultiValued || len(idx.Columns) == 0 {
+			continue
+		}
+		if columns != nil && len(idx.Columns) >= len(columns) {
+			continue
+		}
+		if key, ok := t.notNullColumns(idx.Columns); ok {
+			columns = key
+		}
+	}
+
+	return columns, columns != nil
+}
+
+// notNullColumns returns the named columns, ok being false if any of them
+// is nullable or missing.
+func (t Table) notNullColumns(names []string) ([]Column, bool) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range t.Columns {
+			if c.Name == name && !c.Nullable {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	return columns, true
+}
+
+// InsertColumns returns the columns an INSERT should list: every column that
+// isn't auto incremented or otherwise generated by the database.
+func (t Table) InsertColumns() []Column {
+	var cols []Column
+
+	for _, c := range t.Columns {
+		if !c.AutoIncrement && !c.AutoGenerated && !c.Generated {
+			cols = append(cols, c)
+		}
+	}
+
+	return cols
+}
+
+// UpdateColumns returns the columns an UPDATE should set: every column that
+// the database doesn't generate or bump on update by itself.
+func (t Table) UpdateColumns() []Column {
+	var cols []Column
+
+	for _, c := range t.Columns {
+		if !c.AutoUpdateTime && !c.AutoGenerated && !c.Generated {
+			cols = append(cols, c)
+		}
+	}
+
+	return cols
+}
@@ -1,6 +1,9 @@
 package db
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestGetTable(t *testing.T) {
 	t.Parallel()
@@ -74,29 +77,29 @@ func TestCanLastInsertID(t *testing.T) {
 		PKeys []Column
 	}{
 		{true, []Column{
-			{Name: "id", Type: "int64", Default: "a"},
+			{Name: "id", TypeName: "int64", Default: "a"},
 		}},
 		{true, []Column{
-			{Name: "id", Type: "uint64", Default: "a"},
+			{Name: "id", TypeName: "uint64", Default: "a"},
 		}},
 		{true, []Column{
-			{Name: "id", Type: "int", Default: "a"},
+			{Name: "id", TypeName: "int", Default: "a"},
 		}},
 		{true, []Column{
-			{Name: "id", Type: "uint", Default: "a"},
+			{Name: "id", TypeName: "uint", Default: "a"},
 		}},
 		{true, []Column{
-			{Name: "id", Type: "uint", Default: "a"},
+			{Name: "id", TypeName: "uint", Default: "a"},
 		}},
 		{false, []Column{
-			{Name: "id", Type: "uint", Default: "a"},
-			{Name: "id2", Type: "uint", Default: "a"},
+			{Name: "id", TypeName: "uint", Default: "a"},
+			{Name: "id2", TypeName: "uint", Default: "a"},
 		}},
 		{false, []Column{
-			{Name: "id", Type: "string", Default: "a"},
+			{Name: "id", TypeName: "string", Default: "a"},
 		}},
 		{false, []Column{
-			{Name: "id", Type: "int", Default: ""},
+			{Name: "id", TypeName: "int", Default: ""},
 		}},
 		{false, nil},
 	}
@@ -118,3 +121,121 @@ func TestCanLastInsertID(t *testing.T) {
 		}
 	}
 }
+
+func TestNaturalKey(t *testing.T) {
+	t.Parallel()
+
+	surrogate := Table{
+		Columns: []Column{
+			{Name: "id", TypeName: "int"},
+			{Name: "email", TypeName: "string", Unique: true},
+		},
+		PKey: &PrimaryKey{Columns: []string{"id"}},
+	}
+
+	cols, ok := surrogate.NaturalKey()
+	if !ok || len(cols) != 1 || cols[0].Name != "id" {
+		t.Errorf("want the primary key, got: %#v", cols)
+	}
+
+	natural := Table{
+		Columns: []Column{
+			{Name: "nickname", TypeName: "null.String", Nullable: true, Unique: true},
+			{Name: "email", TypeName: "string", Unique: true},
+			{Name: "name", TypeName: "string"},
+		},
+	}
+
+	cols, ok = natural.NaturalKey()
+	if !ok || len(cols) != 1 || cols[0].Name != "email" {
+		t.Errorf("want the unique email column, got: %#v", cols)
+	}
+
+	composite := Table{
+		Columns: []Column{
+			{Name: "tenant_id", TypeName: "int"},
+			{Name: "slug", TypeName: "string"},
+			{Name: "region", TypeName: "string"},
+			{Name: "handle", TypeName: "null.String", Nullable: true},
+		},
+		Indexes: []Index{
+			{Name: "pages_tenant_id_slug_region_key", Columns: []string{"tenant_id", "slug", "region"}, Unique: true},
+			{Name: "pages_tenant_id_handle_key", Columns: []string{"tenant_id", "handle"}, Unique: true},
+			{Name: "pages_tenant_id_slug_key", Columns: []string{"tenant_id", "slug"}, Unique: true},
+			{Name: "pages_slug_idx", Columns: []string{"slug"}},
+		},
+	}
+
+	cols, ok = composite.NaturalKey()
+	if !ok || len(cols) != 2 || cols[0].Name != "tenant_id" || cols[1].Name != "slug" {
+		t.Errorf("want the smallest not null unique index, got: %#v", cols)
+	}
+
+	keyless := Table{
+		Columns: []Column{{Name: "name", TypeName: "string"}},
+	}
+
+	if cols, ok = keyless.NaturalKey(); ok || cols != nil {
+		t.Errorf("want no natural key, got: %#v", cols)
+	}
+}
+
+func TestInsertColumns(t *testing.T) {
+	t.Parallel()
+
+	table := Table{
+		Columns: []Column{
+			{Name: "id", TypeName: "int", Default: "auto_increment", AutoIncrement: true},
+			{Name: "name", TypeName: "string"},
+			{Name: "version", TypeName: "[]byte", AutoGenerated: true},
+			{Name: "created_at", TypeName: "time.Time", Default: "now()"},
+			{Name: "name_length", TypeName: "int", Generated: true},
+		},
+	}
+
+	if got := ColumnNames(table.InsertColumns()); !reflect.DeepEqual(got, []string{"name", "created_at"}) {
+		t.Errorf("want name and created_at, got: %v", got)
+	}
+}
+
+func TestUpdateColumns(t *testing.T) {
+	t.Parallel()
+
+	table := Table{
+		Columns: []Column{
+			{Name: "id", TypeName: "int", Default: "auto_increment", AutoIncrement: true},
+			{Name: "name", TypeName: "string"},
+			{Name: "created_at", TypeName: "time.Time", Default: "CURRENT_TIMESTAMP"},
+			{Name: "updated_at", TypeName: "time.Time", Default: "CURRENT_TIMESTAMP", AutoUpdateTime: true},
+			{Name: "name_length", TypeName: "int", Generated: true},
+		},
+	}
+
+	if got := ColumnNames(table.UpdateColumns()); !reflect.DeepEqual(got, []string{"id", "name", "created_at"}) {
+		t.Errorf("want updated_at left out, got: %v", got)
+	}
+	if got := ColumnNames(table.InsertColumns()); !reflect.DeepEqual(got, []string{"name", "created_at", "updated_at"}) {
+		t.Errorf("want updated_at inserted, got: %v", got)
+	}
+}
+
+func TestAutoColumns(t *testing.T) {
+	t.Parallel()
+
+	cols := []Column{
+		{Name: "id", TypeName: "int", Default: "auto_increment", AutoIncrement: true},
+		{Name: "name", TypeName: "string"},
+		{Name: "version", TypeName: "[]byte", AutoGenerated: true},
+		{Name: "created_at", TypeName: "time.Time", Default: "CURRENT_TIMESTAMP"},
+		{Name: "status", TypeName: "null.String", Default: "'new'", Nullable: true},
+		{Name: "name_length", TypeName: "int", Generated: true},
+	}
+
+	want := AutoColumns{
+		Assigned:  []string{"id", "version", "name_length"},
+		Defaulted: []string{"created_at", "status"},
+	}
+	if got := autoColumns(cols); !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %#v, got: %#v", want, got)
+	}
+}
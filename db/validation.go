@@ -0,0 +1,63 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError is every problem found validating the tables of a schema
+// for generation, so they can all be fixed in one pass rather than one rerun
+// at a time.
+type ValidationError struct {
+	// Problems maps table names to what is wrong with them, in the order
+	// they were found.
+	Problems map[string][]string
+}
+
+// Add records a problem with table, or with the schema as a whole if table
+// is empty. A problem already recorded for the table isn't repeated.
+func (v *ValidationError) Add(table, format string, args ...interface{}) {
+	if v.Problems == nil {
+		v.Problems = map[string][]string{}
+	}
+	problem := fmt.Sprintf(format, args...)
+	for _, p := range v.Problems[table] {
+		if p == problem {
+			return
+		}
+	}
+	v.Problems[table] = append(v.Problems[table], problem)
+}
+
+// Err returns v if it has any problems, and nil otherwise.
+func (v *ValidationError) Err() error {
+	if len(v.Problems) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// Error lists the problems a line each, by table name, those with the
+// schema as a whole first.
+func (v *ValidationError) Error() string {
+	tables := make([]string, 0, len(v.Problems))
+	for table := range v.Problems {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	lines := []string{"invalid schema:"}
+	for _, table := range tables {
+		for _, problem := range v.Problems[table] {
+			if len(table) == 0 {
+				lines = append(lines, "  "+problem)
+				continue
+			}
+			lines = append(lines, "  "+table+": "+problem)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,23 @@
+package db
+
+import "testing"
+
+func TestValidationError(t *testing.T) {
+	t.Parallel()
+
+	v := &ValidationError{}
+	if err := v.Err(); err != nil {
+		t.Errorf("want no error without problems, got: %v", err)
+	}
+
+	v.Add("users", "primary key missing")
+	v.Add("posts", "display column %s not found", "subject")
+	v.Add("users", "display column %s not found", "name")
+	v.Add("users", "primary key missing")
+	v.Add("", "unknown struct tag casing %q", "kebab")
+
+	want := "invalid schema:\n  unknown struct tag casing \"kebab\"\n  posts: display column subject not found\n  users: primary key missing\n  users: display column name not found"
+	if err := v.Err(); err == nil || err.Error() != want {
+		t.Errorf("want:\n%s\ngot:\n%v", want, err)
+	}
+}
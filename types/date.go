@@ -0,0 +1,163 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date with no time of day or location, for DATE
+// columns. Unlike time.Time it can't shift a day when crossing timezones.
+// Date implements Marshal and Unmarshal.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date t falls on in t's location.
+func DateOf(t time.Time) Date {
+	var d Date
+	d.Year, d.Month, d.Day = t.Date()
+	return d
+}
+
+// ParseDate parses a date in YYYY-MM-DD form.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// String output your date in YYYY-MM-DD form.
+func (d Date) String() string {
+	return d.Time(time.UTC).Format(dateLayout)
+}
+
+// Time returns midnight at the start of d in loc.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// UnmarshalJSON sets *d to the date in data.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if d == nil {
+		return errors.New("json: unmarshal json on nil pointer to date")
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of d.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Value returns d as a driver.Value.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan stores the src in *d.
+func (d *Date) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case time.Time:
+		*d = DateOf(src)
+	case string:
+		return d.scanString(src)
+	case []byte:
+		return d.scanString(string(src))
+	default:
+		return errors.New("incompatible type for date")
+	}
+
+	return nil
+}
+
+func (d *Date) scanString(s string) error {
+	// Drivers that don't parse times hand back the column as text, which
+	// may carry a time of day.
+	if len(s) > len(dateLayout) {
+		s = s[:len(dateLayout)]
+	}
+
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+// NullDate is a nullable Date.
+type NullDate struct {
+	Date  Date
+	Valid bool
+}
+
+// UnmarshalJSON sets *n to the date in data, or to null.
+func (n *NullDate) UnmarshalJSON(data []byte) error {
+	if n == nil {
+		return errors.New("json: unmarshal json on nil pointer to null date")
+	}
+
+	if string(data) == "null" {
+		n.Date, n.Valid = Date{}, false
+		return nil
+	}
+
+	if err := n.Date.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullDate) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.Date.MarshalJSON()
+}
+
+// Value returns n as a driver.Value.
+func (n NullDate) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Date.Value()
+}
+
+// Scan stores the src in *n.
+func (n *NullDate) Scan(src interface{}) error {
+	if src == nil {
+		n.Date, n.Valid = Date{}, false
+		return nil
+	}
+
+	if err := n.Date.Scan(src); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateScan(t *testing.T) {
+	t.Parallel()
+
+	want := Date{Year: 2017, Month: time.March, Day: 4}
+
+	tests := []interface{}{
+		time.Date(2017, 3, 4, 23, 30, 0, 0, time.FixedZone("", -7*3600)),
+		"2017-03-04",
+		[]byte("2017-03-04 00:00:00"),
+	}
+
+	for i, src := range tests {
+		var d Date
+		if err := d.Scan(src); err != nil {
+			t.Errorf("%d) %s", i, err)
+			continue
+		}
+		if d != want {
+			t.Errorf("%d) want: %s, got: %s", i, want, d)
+		}
+	}
+
+	var d Date
+	if err := d.Scan(5); err == nil {
+		t.Error("want an error scanning an int")
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := Date{Year: 2017, Month: time.March, Day: 4}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "2017-03-04" {
+		t.Errorf("Expected %q, got %v", "2017-03-04", v)
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	t.Parallel()
+
+	d := Date{Year: 2017, Month: time.March, Day: 4}
+	res, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != `"2017-03-04"` {
+		t.Errorf("Expected %q, got %s", `"2017-03-04"`, res)
+	}
+
+	var got Date
+	if err := json.Unmarshal(res, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != d {
+		t.Errorf("Expected %s, got %s", d, got)
+	}
+}
+
+func TestNullDate(t *testing.T) {
+	t.Parallel()
+
+	var n NullDate
+	if err := n.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := n.Value(); n.Valid || v != nil {
+		t.Errorf("want an invalid date scanning nil, got: %#v", n)
+	}
+	if res, _ := json.Marshal(n); string(res) != "null" {
+		t.Errorf("Expected null, got %s", res)
+	}
+
+	if err := n.Scan("2017-03-04"); err != nil {
+		t.Fatal(err)
+	}
+	if !n.Valid || n.Date.String() != "2017-03-04" {
+		t.Errorf("want a valid date, got: %#v", n)
+	}
+}
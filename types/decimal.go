@@ -0,0 +1,170 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Decimal is an exact decimal number, for DECIMAL and NUMERIC columns. It
+// keeps the digits the database sends, which a float64 would round. The
+// zero value is 0.
+// Decimal implements Marshal and Unmarshal.
+type Decimal struct {
+	s string
+}
+
+// ParseDecimal parses a decimal number such as -12.50.
+func ParseDecimal(s string) (Decimal, error) {
+	digits, dot := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.' && !dot:
+			dot = true
+		case (c == '-' || c == '+') && i == 0:
+		default:
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+	if digits == 0 {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+
+	return Decimal{s: s}, nil
+}
+
+// String outputs d in the form it was parsed from.
+func (d Decimal) String() string {
+	if len(d.s) == 0 {
+		return "0"
+	}
+	return d.s
+}
+
+// Rat returns d as a big.Rat, for arithmetic without rounding.
+func (d Decimal) Rat() *big.Rat {
+	r, _ := new(big.Rat).SetString(d.String())
+	return r
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// UnmarshalJSON sets *d to the number, or the string holding one, in data.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if d == nil {
+		return errors.New("json: unmarshal json on nil pointer to decimal")
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+
+	dec, err := ParseDecimal(n.String())
+	if err != nil {
+		return err
+	}
+
+	*d = dec
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of d, a number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Value returns d as a driver.Value.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan stores the src in *d.
+func (d *Decimal) Scan(src interface{}) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case int64:
+		s = strconv.FormatInt(src, 10)
+	case float64:
+		s = strconv.FormatFloat(src, 'f', -1, 64)
+	default:
+		return errors.New("incompatible type for decimal")
+	}
+
+	dec, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+
+	*d = dec
+	return nil
+}
+
+// NullDecimal is a nullable Decimal.
+type NullDecimal struct {
+	Decimal Decimal
+	Valid   bool
+}
+
+// UnmarshalJSON sets *n to the decimal in data, or to null.
+func (n *NullDecimal) UnmarshalJSON(data []byte) error {
+	if n == nil {
+		return errors.New("json: unmarshal json on nil pointer to null decimal")
+	}
+
+	if string(data) == "null" {
+		n.Decimal, n.Valid = Decimal{}, false
+		return nil
+	}
+
+	if err := n.Decimal.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullDecimal) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.Decimal.MarshalJSON()
+}
+
+// Value returns n as a driver.Value.
+func (n NullDecimal) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Decimal.Value()
+}
+
+// Scan stores the src in *n.
+func (n *NullDecimal) Scan(src interface{}) error {
+	if src == nil {
+		n.Decimal, n.Valid = Decimal{}, false
+		return nil
+	}
+
+	if err := n.Decimal.Scan(src); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecimalScan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Src  interface{}
+		Want string
+	}{
+		{"12345678901234567890.123456789", "12345678901234567890.123456789"},
+		{[]byte("-0.50"), "-0.50"},
+		{int64(42), "42"},
+		{2.5, "2.5"},
+	}
+
+	for i, test := range tests {
+		var d Decimal
+		if err := d.Scan(test.Src); err != nil {
+			t.Errorf("%d) %s", i, err)
+			continue
+		}
+		if got := d.String(); got != test.Want {
+			t.Errorf("%d) want: %s, got: %s", i, test.Want, got)
+		}
+	}
+
+	var d Decimal
+	for _, src := range []interface{}{"1e5", "1.2.3", "-", true} {
+		if err := d.Scan(src); err == nil {
+			t.Errorf("want an error scanning %#v", src)
+		}
+	}
+	if got := (Decimal{}).String(); got != "0" {
+		t.Errorf("want the zero value to be 0, got: %s", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	t.Parallel()
+
+	var n NullDecimal
+	if err := json.Unmarshal([]byte(`"10.10"`), &n); err != nil {
+		t.Fatal(err)
+	}
+	if !n.Valid || n.Decimal.String() != "10.10" {
+		t.Errorf("want 10.10, got: %#v", n)
+	}
+
+	res, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != "10.10" {
+		t.Errorf("Expected %s, got %s", "10.10", res)
+	}
+
+	if err := json.Unmarshal([]byte("null"), &n); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := n.Value(); err != nil || v != nil || n.Valid {
+		t.Errorf("want null, got: %v %v", v, err)
+	}
+}
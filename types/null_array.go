@@ -0,0 +1,194 @@
+package types
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+)
+
+// The Null*Arrays wrap an array type with Valid, for array columns where
+// NULL and the empty array differ. They share the code below, each passing
+// the address of its array.
+
+// scanNullArray stores src in *arr, or the zero array when src is NULL,
+// setting valid to whether it wasn't.
+func scanNullArray(arr sql.Scanner, valid *bool, src interface{}) error {
+	if src == nil {
+		v := reflect.ValueOf(arr).Elem()
+		v.Set(reflect.Zero(v.Type()))
+		*valid = false
+		return nil
+	}
+
+	if err := arr.Scan(src); err != nil {
+		return err
+	}
+
+	*valid = true
+	return nil
+}
+
+// nullArrayValue returns arr as a driver.Value, NULL if it isn't valid and
+// the empty array if it's valid but nil.
+func nullArrayValue(arr driver.Valuer, valid bool) (driver.Value, error) {
+	if !valid {
+		return nil, nil
+	}
+
+	v, err := arr.Value()
+	if v == nil && err == nil {
+		return "{}", nil
+	}
+	return v, err
+}
+
+// marshalNullArray returns the JSON encoding of arr, or null if it isn't
+// valid.
+func marshalNullArray(arr interface{}, valid bool) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(arr)
+}
+
+// unmarshalNullArray sets *arr to the array in data, setting valid to
+// whether data isn't null. Unmarshaling null leaves arr nil.
+func unmarshalNullArray(data []byte, arr interface{}, valid *bool) error {
+	if err := json.Unmarshal(data, arr); err != nil {
+		return err
+	}
+
+	*valid = string(data) != "null"
+	return nil
+}
+
+// NullBoolArray is a nullable BoolArray.
+type NullBoolArray struct {
+	BoolArray BoolArray
+	Valid     bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullBoolArray) Scan(src interface{}) error {
+	return scanNullArray(&n.BoolArray, &n.Valid, src)
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullBoolArray) Value() (driver.Value, error) {
+	return nullArrayValue(n.BoolArray, n.Valid)
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullBoolArray) MarshalJSON() ([]byte, error) {
+	return marshalNullArray(n.BoolArray, n.Valid)
+}
+
+// UnmarshalJSON sets *n to the array in data, or to null.
+func (n *NullBoolArray) UnmarshalJSON(data []byte) error {
+	return unmarshalNullArray(data, &n.BoolArray, &n.Valid)
+}
+
+// NullBytesArray is a nullable BytesArray.
+type NullBytesArray struct {
+	BytesArray BytesArray
+	Valid      bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullBytesArray) Scan(src interface{}) error {
+	return scanNullArray(&n.BytesArray, &n.Valid, src)
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullBytesArray) Value() (driver.Value, error) {
+	return nullArrayValue(n.BytesArray, n.Valid)
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullBytesArray) MarshalJSON() ([]byte, error) {
+	return marshalNullArray(n.BytesArray, n.Valid)
+}
+
+// UnmarshalJSON sets *n to the array in data, or to null.
+func (n *NullBytesArray) UnmarshalJSON(data []byte) error {
+	return unmarshalNullArray(data, &n.BytesArray, &n.Valid)
+}
+
+// NullFloat64Array is a nullable Float64Array.
+type NullFloat64Array struct {
+	Float64Array Float64Array
+	Valid        bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullFloat64Array) Scan(src interface{}) error {
+	return scanNullArray(&n.Float64Array, &n.Valid, src)
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullFloat64Array) Value() (driver.Value, error) {
+	return nullArrayValue(n.Float64Array, n.Valid)
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullFloat64Array) MarshalJSON() ([]byte, error) {
+	return marshalNullArray(n.Float64Array, n.Valid)
+}
+
+// UnmarshalJSON sets *n to the array in data, or to null.
+func (n *NullFloat64Array) UnmarshalJSON(data []byte) error {
+	return unmarshalNullArray(data, &n.Float64Array, &n.Valid)
+}
+
+// NullInt64Array is a nullable Int64Array.
+type NullInt64Array struct {
+	Int64Array Int64Array
+	Valid      bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullInt64Array) Scan(src interface{}) error {
+	return scanNullArray(&n.Int64Array, &n.Valid, src)
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullInt64Array) Value() (driver.Value, error) {
+	return nullArrayValue(n.Int64Array, n.Valid)
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullInt64Array) MarshalJSON() ([]byte, error) {
+	return marshalNullArray(n.Int64Array, n.Valid)
+}
+
+// UnmarshalJSON sets *n to the array in data, or to null.
+func (n *NullInt64Array) UnmarshalJSON(data []byte) error {
+	return unmarshalNullArray(data, &n.Int64Array, &n.Valid)
+}
+
+// NullStringArray is a nullable StringArray.
+type NullStringArray struct {
+	StringArray StringArray
+	Valid       bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (n *NullStringArray) Scan(src interface{}) error {
+	return scanNullArray(&n.StringArray, &n.Valid, src)
+}
+
+// Value implements the driver.Valuer interface.
+func (n NullStringArray) Value() (driver.Value, error) {
+	return nullArrayValue(n.StringArray, n.Valid)
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullStringArray) MarshalJSON() ([]byte, error) {
+	return marshalNullArray(n.StringArray, n.Valid)
+}
+
+// UnmarshalJSON sets *n to the array in data, or to null.
+func (n *NullStringArray) UnmarshalJSON(data []byte) error {
+	return unmarshalNullArray(data, &n.StringArray, &n.Valid)
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNullStringArray(t *testing.T) {
+	t.Parallel()
+
+	var n NullStringArray
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("want null, got: %#v, %v", n, err)
+	}
+	if v, err := n.Value(); err != nil || v != nil {
+		t.Errorf("want a nil value, got: %v, %v", v, err)
+	}
+
+	if err := n.Scan([]byte("{}")); err != nil || !n.Valid || len(n.StringArray) != 0 {
+		t.Errorf("want a valid empty array, got: %#v, %v", n, err)
+	}
+
+	if err := n.Scan([]byte(`{a,"b c"}`)); err != nil || !n.Valid || !reflect.DeepEqual(n.StringArray, StringArray{"a", "b c"}) {
+		t.Errorf("want {a,b c}, got: %#v, %v", n, err)
+	}
+
+	b, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `["a","b c"]` {
+		t.Errorf("want a json array, got: %s", b)
+	}
+
+	var out NullInt64Array
+	if err := json.Unmarshal([]byte("null"), &out); err != nil || out.Valid {
+		t.Errorf("want null, got: %#v, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte("[1,2]"), &out); err != nil || !out.Valid || !reflect.DeepEqual(out.Int64Array, Int64Array{1, 2}) {
+		t.Errorf("want [1,2], got: %#v, %v", out, err)
+	}
+}
+
+func TestNullArrayValid(t *testing.T) {
+	t.Parallel()
+
+	n := NullBoolArray{BoolArray: BoolArray{true}, Valid: true}
+	if err := n.Scan(nil); err != nil || n.Valid || n.BoolArray != nil {
+		t.Errorf("want scanning null to clear the array, got: %#v, %v", n, err)
+	}
+
+	n.Valid = true
+	if v, err := n.Value(); err != nil || v != "{}" {
+		t.Errorf("want a valid nil array to be empty, not null, got: %v, %v", v, err)
+	}
+
+	if err := json.Unmarshal([]byte("[true]"), &n); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte("null"), &n); err != nil || n.Valid || n.BoolArray != nil {
+		t.Errorf("want unmarshaling null to clear the array, got: %#v, %v", n, err)
+	}
+}
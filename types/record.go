@@ -0,0 +1,199 @@
+package types
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordTimeLayouts are the text forms Postgres sends date and time
+// attributes of a composite value in.
+var recordTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00:00",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// ScanRecord stores src, a Postgres composite value in its text form such as
+// (1,"Main St",), in dests, a pointer per attribute in order. Empty unquoted
+// attributes are NULL. Each attribute is scanned from its text, or from the
+// time it holds for scanners that only take a time.Time, like null.Time.
+func ScanRecord(src interface{}, dests ...interface{}) error {
+	var text []byte
+	switch src := src.(type) {
+	case string:
+		text = []byte(src)
+	case []byte:
+		text = src
+	default:
+		return errors.New("incompatible type for record")
+	}
+
+	attrs, err := parseRecord(text)
+	if err != nil {
+		return err
+	}
+	if len(attrs) != len(dests) {
+		return fmt.Errorf("record has %d attributes, want %d", len(attrs), len(dests))
+	}
+
+	for i, attr := range attrs {
+		if err := scanAttribute(dests[i], attr); err != nil {
+			return fmt.Errorf("record attribute %d: %s", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func scanAttribute(dest interface{}, attr []byte) error {
+	switch dest := dest.(type) {
+	case *string:
+		*dest = string(attr)
+		return nil
+	case *[]byte:
+		*dest = attr
+		return nil
+	case *int64:
+		i, err := strconv.ParseInt(string(attr), 10, 64)
+		*dest = i
+		return err
+	case *float64:
+		f, err := strconv.ParseFloat(string(attr), 64)
+		*dest = f
+		return err
+	case *bool:
+		*dest = len(attr) != 0 && (attr[0] == 't' || attr[0] == 'T')
+		return nil
+	case sql.Scanner:
+		if attr == nil {
+			return dest.Scan(nil)
+		}
+		err := dest.Scan(attr)
+		if err == nil {
+			return nil
+		}
+		for _, layout := range recordTimeLayouts {
+			if t, perr := time.Parse(layout, string(attr)); perr == nil {
+				return dest.Scan(t)
+			}
+		}
+		return err
+	}
+
+	return fmt.Errorf("unsupported destination %T", dest)
+}
+
+// parseRecord splits a composite value into its attributes, nil for NULL
+// ones, unquoting and unescaping them.
+func parseRecord(src []byte) ([][]byte, error) {
+	if len(src) < 2 || src[0] != '(' || src[len(src)-1] != ')' {
+		return nil, fmt.Errorf("unable to parse record %q", src)
+	}
+	src = src[1 : len(src)-1]
+
+	var attrs [][]byte
+	for {
+		var attr []byte
+		quoted, inQuotes := false, false
+		i := 0
+	scan:
+		for ; i < len(src); i++ {
+			switch c := src[i]; {
+			case inQuotes && c == '"' && i+1 < len(src) && src[i+1] == '"':
+				attr = append(attr, '"')
+				i++
+			case c == '"':
+				quoted, inQuotes = true, !inQuotes
+			case c == '\\' && i+1 < len(src):
+				i++
+				attr = append(attr, src[i])
+			case c == ',' && !inQuotes:
+				break scan
+			default:
+				attr = append(attr, c)
+			}
+		}
+		if inQuotes {
+			return nil, errors.New("unterminated quote in record")
+		}
+		if quoted && attr == nil {
+			attr = []byte{}
+		}
+		attrs = append(attrs, attr)
+
+		if i == len(src) {
+			return attrs, nil
+		}
+		src = src[i+1:]
+	}
+}
+
+// RecordValue formats values, one per attribute in order, as a Postgres
+// composite value in its text form. Nil values, including driver.Valuers
+// returning nil, are NULL.
+func RecordValue(values ...interface{}) (driver.Value, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('(')
+	for i, v := range values {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		v, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var attr string
+		switch v := v.(type) {
+		case nil:
+			continue
+		case []byte:
+			attr = string(v)
+		case string:
+			attr = v
+		case int64:
+			attr = strconv.FormatInt(v, 10)
+		case float64:
+			attr = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			attr = "f"
+			if v {
+				attr = "t"
+			}
+		case time.Time:
+			attr = v.Format("2006-01-02 15:04:05.999999999Z07:00")
+		}
+		writeRecordAttr(buf, attr)
+	}
+	buf.WriteByte(')')
+
+	return buf.String(), nil
+}
+
+// writeRecordAttr writes attr, quoted if it is empty, so it isn't NULL, or
+// has characters that are special in a record.
+func writeRecordAttr(buf *bytes.Buffer, attr string) {
+	if len(attr) != 0 && !strings.ContainsAny(attr, "(),\"\\ \t\n") {
+		buf.WriteString(attr)
+		return
+	}
+
+	buf.WriteByte('"')
+	for i := 0; i < len(attr); i++ {
+		if attr[i] == '"' || attr[i] == '\\' {
+			buf.WriteByte(attr[i])
+		}
+		buf.WriteByte(attr[i])
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanRecord(t *testing.T) {
+	t.Parallel()
+
+	var street, city string
+	var zip sql.NullInt64
+	var note sql.NullString
+	var tags NullStringArray
+	var movedIn NullDate
+	src := `("1 Main St, Apt \"2\"",Springfield,12345,,"{a,b}",2017-03-04)`
+	if err := ScanRecord(src, &street, &city, &zip, &note, &tags, &movedIn); err != nil {
+		t.Fatal(err)
+	}
+
+	if street != `1 Main St, Apt "2"` || city != "Springfield" {
+		t.Errorf("want the text attributes unquoted, got: %q %q", street, city)
+	}
+	if !zip.Valid || zip.Int64 != 12345 {
+		t.Errorf("want the zip scanned, got: %#v", zip)
+	}
+	if note.Valid {
+		t.Errorf("want the empty attribute null, got: %#v", note)
+	}
+	if !tags.Valid || !reflect.DeepEqual(tags.StringArray, StringArray{"a", "b"}) {
+		t.Errorf("want the array scanned, got: %#v", tags)
+	}
+	if want := (Date{Year: 2017, Month: time.March, Day: 4}); !movedIn.Valid || movedIn.Date != want {
+		t.Errorf("want the date scanned, got: %#v", movedIn)
+	}
+
+	if err := ScanRecord("(1,2)", &street); err == nil {
+		t.Error("want an error for too many attributes")
+	}
+	if err := ScanRecord(`("1)`, &street); err == nil {
+		t.Error("want an error for an unterminated quote")
+	}
+}
+
+func TestRecordValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := RecordValue(`1 Main St, Apt "2"`, "", sql.NullInt64{Int64: 12345, Valid: true}, sql.NullString{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `("1 Main St, Apt ""2""","",12345,,t)`; v != want {
+		t.Errorf("want: %s, got: %s", want, v)
+	}
+
+	var street, empty string
+	var zip sql.NullInt64
+	var note sql.NullString
+	var ok bool
+	if err := ScanRecord(v, &street, &empty, &zip, &note, &ok); err != nil {
+		t.Fatal(err)
+	}
+	if street != `1 Main St, Apt "2"` || empty != "" || zip.Int64 != 12345 || note.Valid {
+		t.Errorf("want the value to scan back, got: %q %q %#v %#v", street, empty, zip, note)
+	}
+}
@@ -0,0 +1,96 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Set holds the members of a MySQL SET column, which the database sends as
+// a comma separated string. An empty set has no members. MySQL rejects
+// members with commas in them, so splitting on commas is exact.
+// Set implements Marshal and Unmarshal as a JSON array.
+type Set []string
+
+// Value returns s as a driver.Value.
+func (s Set) Value() (driver.Value, error) {
+	return strings.Join(s, ","), nil
+}
+
+// Scan stores the src in *s.
+func (s *Set) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		*s = parseSet(src)
+	case []byte:
+		*s = parseSet(string(src))
+	default:
+		return errors.New("incompatible type for set")
+	}
+
+	return nil
+}
+
+func parseSet(src string) Set {
+	if len(src) == 0 {
+		return Set{}
+	}
+	return Set(strings.Split(src, ","))
+}
+
+// NullSet is a nullable Set.
+type NullSet struct {
+	Set   Set
+	Valid bool
+}
+
+// UnmarshalJSON sets *n to the set in data, or to null.
+func (n *NullSet) UnmarshalJSON(data []byte) error {
+	if n == nil {
+		return errors.New("json: unmarshal json on nil pointer to null set")
+	}
+
+	if string(data) == "null" {
+		n.Set, n.Valid = nil, false
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &n.Set); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of n.
+func (n NullSet) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Set)
+}
+
+// Value returns n as a driver.Value.
+func (n NullSet) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Set.Value()
+}
+
+// Scan stores the src in *n.
+func (n *NullSet) Scan(src interface{}) error {
+	if src == nil {
+		n.Set, n.Valid = nil, false
+		return nil
+	}
+
+	if err := n.Set.Scan(src); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
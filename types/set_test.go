@@ -0,0 +1,76 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSetScan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		Src  interface{}
+		Want Set
+	}{
+		{"", Set{}},
+		{"a", Set{"a"}},
+		{[]byte("a,b,c"), Set{"a", "b", "c"}},
+	}
+
+	for i, test := range tests {
+		var s Set
+		if err := s.Scan(test.Src); err != nil {
+			t.Errorf("%d) %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(s, test.Want) {
+			t.Errorf("%d) want: %#v, got: %#v", i, test.Want, s)
+		}
+	}
+
+	var s Set
+	if err := s.Scan(1); err == nil {
+		t.Error("want an error scanning an int")
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	t.Parallel()
+
+	if v, err := (Set{"a", "b"}).Value(); err != nil || v != "a,b" {
+		t.Errorf("want a,b, got: %v, %v", v, err)
+	}
+	if v, err := (Set{}).Value(); err != nil || v != "" {
+		t.Errorf("want an empty string for no members, got: %v, %v", v, err)
+	}
+}
+
+func TestNullSet(t *testing.T) {
+	t.Parallel()
+
+	var n NullSet
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("want null, got: %#v, %v", n, err)
+	}
+	if v, err := n.Value(); err != nil || v != nil {
+		t.Errorf("want a nil value, got: %v, %v", v, err)
+	}
+
+	if err := n.Scan([]byte("a,b")); err != nil || !n.Valid || !reflect.DeepEqual(n.Set, Set{"a", "b"}) {
+		t.Errorf("want a,b, got: %#v, %v", n, err)
+	}
+
+	b, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `["a","b"]` {
+		t.Errorf("want a json array, got: %s", b)
+	}
+
+	var out NullSet
+	if err := json.Unmarshal([]byte("null"), &out); err != nil || out.Valid {
+		t.Errorf("want null, got: %#v, %v", out, err)
+	}
+}